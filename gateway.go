@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/internal/rtugateway"
+	"modbustohttp/pkg/config"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runGateway runs the gateway subcommand, which forwards the requests of Modbus TCP masters to the slaves on the RTU
+// bus in the gateway config until the process is interrupted.
+func runGateway(args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("gateway", flag.ContinueOnError)
+	configLocation := flags.String("config", os.Getenv("CONFIG_FILE"), "location of config file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	appConfig, err := config.LoadAppConfig(configLocation)
+	if err != nil {
+		return err
+	}
+	gatewayConfig := &appConfig.Gateway
+	if gatewayConfig.Port == 0 {
+		gatewayConfig.Port = 502
+	}
+	logger.Info("setting up gateway mode",
+		slog.String("config_file", *configLocation),
+		slog.String("device", gatewayConfig.Device),
+		slog.Int("baud_rate", gatewayConfig.BaudRate),
+		slog.String("parity", gatewayConfig.Parity),
+		slog.Duration("response_timeout", gatewayConfig.ResponseTimeout),
+	)
+	port, err := rtugateway.Open(gatewayConfig)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = port.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := modbusserver.NewServer(rtugateway.New(gatewayConfig, port, logger))
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	addr := fmt.Sprintf("%s:%d", gatewayConfig.Host, gatewayConfig.Port)
+	logger.Info("starting modbus gateway", slog.String("addr", addr))
+	err = server.ListenAndServe(addr)
+	if errors.Is(err, modbusserver.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package arm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"modbustohttp/pkg/config"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when a token was never issued, has already been used or has expired.
+	ErrInvalidToken = errors.New("arm token is invalid, expired or has already been used")
+	// ErrPrincipalMismatch is returned when a token is used by a different principal to the one which prepared it.
+	ErrPrincipalMismatch = errors.New("arm token was prepared by a different principal")
+)
+
+// PendingWrite is a write to a single coil or holding register which has been prepared but not yet executed.
+type PendingWrite struct {
+	// Principal is the name of the principal which prepared the write
+	Principal string
+	// Table is the table which will be written to, either coils or holdingRegisters
+	Table config.Table
+	// Address is the address which will be written to
+	Address uint32
+	// Value is the value which will be written. Coils are 0 or 1.
+	Value uint32
+	// Current is the value of the address when the write was prepared. Coils are 0 or 1.
+	Current uint32
+	// ExpiresAt is when the token for the write expires
+	ExpiresAt time.Time
+}
+
+// Store holds prepared writes until they are executed or expire.
+type Store struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	pending map[string]PendingWrite
+	now     func() time.Time
+}
+
+// NewStore returns a Store whose tokens expire after the given timeout.
+func NewStore(timeout time.Duration) *Store {
+	return &Store{
+		timeout: timeout,
+		pending: make(map[string]PendingWrite),
+		now:     time.Now,
+	}
+}
+
+// Prepare stores the write and returns the token which must be used to execute it. The ExpiresAt of the returned
+// write is set.
+func (s *Store) Prepare(write PendingWrite) (string, PendingWrite, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", PendingWrite{}, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	// Drop expired writes so that tokens which are never executed do not accumulate.
+	for pendingToken, pending := range s.pending {
+		if !now.Before(pending.ExpiresAt) {
+			delete(s.pending, pendingToken)
+		}
+	}
+	write.ExpiresAt = now.Add(s.timeout)
+	s.pending[token] = write
+	return token, write, nil
+}
+
+// Peek returns the write for the token without removing it, so that a request can be checked before the token is
+// used. It returns the same errors as Take.
+func (s *Store) Peek(token string, principal string) (PendingWrite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookup(token, principal)
+}
+
+// Take removes the write for the token and returns it, so that each token can only be used once. It returns
+// ErrInvalidToken if the token is unknown or has expired, and ErrPrincipalMismatch if the token was prepared by a
+// different principal. The token is kept after a mismatch, so that another principal cannot revoke it by presenting
+// it.
+func (s *Store) Take(token string, principal string) (PendingWrite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	write, err := s.lookup(token, principal)
+	if err != nil {
+		return PendingWrite{}, err
+	}
+	delete(s.pending, token)
+	return write, nil
+}
+
+// lookup returns the write for the token, dropping it if it has expired. It must be called with the mutex held.
+func (s *Store) lookup(token string, principal string) (PendingWrite, error) {
+	write, ok := s.pending[token]
+	if !ok {
+		return PendingWrite{}, ErrInvalidToken
+	}
+	if !s.now().Before(write.ExpiresAt) {
+		delete(s.pending, token)
+		return PendingWrite{}, ErrInvalidToken
+	}
+	if write.Principal != principal {
+		return PendingWrite{}, ErrPrincipalMismatch
+	}
+	return write, nil
+}
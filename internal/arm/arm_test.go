@@ -0,0 +1,72 @@
+package arm
+
+import (
+	"errors"
+	"modbustohttp/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(10 * time.Second)
+	store.now = func() time.Time { return now }
+
+	write := PendingWrite{Principal: "alice", Table: config.Coils, Address: 3, Value: 1}
+	token, prepared, err := store.Prepare(write)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if want := now.Add(10 * time.Second); !prepared.ExpiresAt.Equal(want) {
+		t.Errorf("Prepare() ExpiresAt = %v, want %v", prepared.ExpiresAt, want)
+	}
+
+	if _, err := store.Take(token, "alice"); err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if _, err := store.Take(token, "alice"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Take() of a used token error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	token, _, err = store.Prepare(write)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if _, err := store.Take(token, "bob"); !errors.Is(err, ErrPrincipalMismatch) {
+		t.Errorf("Take() by another principal error = %v, want %v", err, ErrPrincipalMismatch)
+	}
+	if _, err := store.Take(token, "alice"); err != nil {
+		t.Errorf("Take() by the owner after a mismatch error = %v", err)
+	}
+
+	token, _, err = store.Prepare(write)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	now = now.Add(10 * time.Second)
+	if _, err := store.Take(token, "alice"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Take() of an expired token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestStore_Peek(t *testing.T) {
+	store := NewStore(10 * time.Second)
+	token, _, err := store.Prepare(PendingWrite{Principal: "alice", Table: config.HoldingRegisters, Address: 3, Value: 7})
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if _, err := store.Peek(token, "bob"); !errors.Is(err, ErrPrincipalMismatch) {
+		t.Errorf("Peek() by another principal error = %v, want %v", err, ErrPrincipalMismatch)
+	}
+	for range 2 {
+		if write, err := store.Peek(token, "alice"); err != nil || write.Address != 3 {
+			t.Fatalf("Peek() = %+v, %v, want the write to address 3", write, err)
+		}
+	}
+	if _, err := store.Take(token, "alice"); err != nil {
+		t.Fatalf("Take() after Peek() error = %v", err)
+	}
+	if _, err := store.Peek(token, "alice"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Peek() of a used token error = %v, want %v", err, ErrInvalidToken)
+	}
+}
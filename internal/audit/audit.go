@@ -0,0 +1,257 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"modbustohttp/pkg/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Mask describes a masked write to a holding register. The resulting value of the register is
+// (current AND AndMask) OR (OrMask AND (NOT AndMask)).
+type Mask struct {
+	AndMask uint16 `json:"andMask"`
+	OrMask  uint16 `json:"orMask"`
+}
+
+// Apply returns the result of applying the mask to the given register value.
+func (m Mask) Apply(value uint16) uint16 {
+	return (value & m.AndMask) | (m.OrMask &^ m.AndMask)
+}
+
+// Event is a single write made to the modbus server.
+type Event struct {
+	// Time is when the write was made
+	Time time.Time `json:"time"`
+	// Principal is the name of the principal which made the write, or "anonymous" if the request was not authenticated
+	Principal string `json:"principal"`
+	// Peer is the network address the request was received from
+	Peer string `json:"peer"`
+	// Procedure is the RPC procedure used to make the write
+	Procedure string `json:"procedure"`
+	// Table is the modbus table written to
+	Table config.Table `json:"table"`
+	// FileNumber is the file written to, for writes to file records. Address is then the first record number written to.
+	FileNumber uint32 `json:"fileNumber,omitempty"`
+	// Address is the first address written to
+	Address uint32 `json:"address"`
+	// OldValues are the values which were replaced, starting at Address. Coils are recorded as 0 or 1. It is empty if
+	// the values were not read before the write.
+	OldValues []uint32 `json:"oldValues,omitempty"`
+	// NewValues are the values written, starting at Address. Coils are recorded as 0 or 1. For masked writes it is
+	// only set if the resulting value is known.
+	NewValues []uint32 `json:"newValues,omitempty"`
+	// Mask is set for masked writes to a single holding register
+	Mask *Mask `json:"mask,omitempty"`
+	// FunctionCode is set for raw PDUs, whose writes are not known. Table is then empty.
+	FunctionCode byte `json:"functionCode,omitempty"`
+	// Data is the data of a raw PDU
+	Data []byte `json:"data,omitempty"`
+	// UnitID is the unit ID a raw PDU was sent to
+	UnitID byte `json:"unitId,omitempty"`
+	// Success is whether the write succeeded
+	Success bool `json:"success"`
+	// Error is the error returned if the write did not succeed
+	Error string `json:"error,omitempty"`
+}
+
+// end returns the last address written to by the event.
+func (e Event) end() uint32 {
+	if len(e.NewValues) > 1 {
+		return e.Address + uint32(len(e.NewValues)) - 1
+	}
+	return e.Address
+}
+
+// Filter selects the audit events returned by Logger.List. Zero values match all events.
+type Filter struct {
+	// Principal only matches events made by the principal with this name
+	Principal string
+	// Table only matches events which wrote to this table
+	Table config.Table
+	// StartAddress only matches events which wrote to an address at or after this address
+	StartAddress uint32
+	// EndAddress only matches events which wrote to an address at or before this address, if set
+	EndAddress *uint32
+	// Since only matches events at or after this time
+	Since time.Time
+	// Until only matches events before this time
+	Until time.Time
+	// Success only matches events with this outcome, if set
+	Success *bool
+	// Limit is the maximum number of events returned. If zero, all matching events are returned.
+	Limit int
+}
+
+func (f Filter) matches(event Event) bool {
+	switch {
+	case f.Principal != "" && event.Principal != f.Principal:
+		return false
+	case f.Table != "" && event.Table != f.Table:
+		return false
+	case event.end() < f.StartAddress:
+		return false
+	case f.EndAddress != nil && event.Address > *f.EndAddress:
+		return false
+	case !f.Since.IsZero() && event.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !event.Time.Before(f.Until):
+		return false
+	case f.Success != nil && event.Success != *f.Success:
+		return false
+	}
+	return true
+}
+
+// Logger appends audit events to a rotating JSON lines file.
+type Logger struct {
+	mu              sync.Mutex
+	writer          *lumberjack.Logger
+	readBeforeWrite bool
+}
+
+// NewLogger returns a Logger which writes to the file described by the given config.
+func NewLogger(auditConfig *config.Audit) *Logger {
+	return &Logger{
+		writer: &lumberjack.Logger{
+			Filename:   auditConfig.Path,
+			MaxSize:    auditConfig.MaxSizeMB,
+			MaxBackups: auditConfig.MaxBackups,
+			MaxAge:     auditConfig.MaxAgeDays,
+		},
+		readBeforeWrite: auditConfig.ReadBeforeWrite,
+	}
+}
+
+// ReadBeforeWrite is whether the values being replaced should be read before each write, so that they can be recorded.
+func (l *Logger) ReadBeforeWrite() bool {
+	return l.readBeforeWrite
+}
+
+// Record appends the given event to the audit log.
+func (l *Logger) Record(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.writer.Write(append(line, '\n'))
+	return err
+}
+
+// List returns the events in the audit log, including rotated files which have been kept, which match the filter.
+// The events are returned newest first.
+func (l *Logger) List(filter Filter) ([]Event, error) {
+	files, err := l.open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	// The files are read newest first. Every event in an older file precedes those in the newer files, so the older
+	// files do not need to be read once the limit has been reached.
+	var events []Event
+	for _, file := range slices.Backward(files) {
+		fileEvents, err := readEvents(file, filter)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+		if filter.Limit > 0 && len(events) >= filter.Limit {
+			break
+		}
+	}
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return b.Time.Compare(a.Time)
+	})
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
+
+// Close closes the audit log file.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writer.Close()
+}
+
+// files returns any rotated backups of the audit log followed by the current file. Backups are named by lumberjack as
+// <name>-<timestamp><ext> in the same directory as the current file.
+func (l *Logger) files() ([]string, error) {
+	dir := filepath.Dir(l.writer.Filename)
+	ext := filepath.Ext(l.writer.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(l.writer.Filename), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ext) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return append(files, l.writer.Filename), nil
+}
+
+// open opens the files of the audit log, oldest first. The files are opened while holding the lock so that a rotation
+// cannot rename the current file in between finding and opening it, but are read without it so that listing the
+// events does not hold up writes. A file which no longer exists is skipped.
+func (l *Logger) open() ([]*os.File, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	paths, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+	var files []*os.File
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			for _, file := range files {
+				_ = file.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// readEvents reads the events in the given file which match the filter.
+func readEvents(file *os.File, filter Filter) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// Skip lines which cannot be decoded, such as a partially written final line.
+			continue
+		}
+		if filter.matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"encoding/json"
+	"modbustohttp/pkg/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMaskApply(t *testing.T) {
+	// Example from the Modbus application protocol specification for function code 22.
+	mask := Mask{AndMask: 0x00F2, OrMask: 0x0025}
+	if got := mask.Apply(0x0012); got != 0x0017 {
+		t.Errorf("Apply() = %#04x, want %#04x", got, 0x0017)
+	}
+}
+
+func TestLogger_List(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A rotated backup written before the current file.
+	backup := Event{
+		Time: start, Principal: "alice", Table: config.HoldingRegisters, Address: 10,
+		NewValues: []uint32{1}, Success: true,
+	}
+	line, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupPath := filepath.Join(dir, "audit-2025-01-01T00-00-00.000.jsonl")
+	if err := os.WriteFile(backupPath, append(line, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := NewLogger(&config.Audit{Path: path, MaxSizeMB: 1})
+	defer func() {
+		_ = logger.Close()
+	}()
+	events := []Event{
+		{
+			Time: start.Add(time.Minute), Principal: "bob", Table: config.Coils, Address: 0,
+			NewValues: []uint32{1, 0, 1}, Success: true,
+		},
+		{
+			Time: start.Add(2 * time.Minute), Principal: "alice", Table: config.HoldingRegisters, Address: 20,
+			NewValues: []uint32{5}, Success: false, Error: "modbus: exception '2' (illegal data address)",
+		},
+	}
+	for _, event := range events {
+		if err := logger.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	failed := false
+	end := uint32(15)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []uint32
+	}{
+		{name: "All events newest first", filter: Filter{}, want: []uint32{20, 0, 10}},
+		{name: "By principal", filter: Filter{Principal: "alice"}, want: []uint32{20, 10}},
+		{name: "By table", filter: Filter{Table: config.Coils}, want: []uint32{0}},
+		{name: "By address range", filter: Filter{StartAddress: 2, EndAddress: &end}, want: []uint32{0, 10}},
+		{name: "By outcome", filter: Filter{Success: &failed}, want: []uint32{20}},
+		{name: "By time", filter: Filter{Since: start.Add(time.Minute), Until: start.Add(2 * time.Minute)}, want: []uint32{0}},
+		{name: "With limit", filter: Filter{Limit: 1}, want: []uint32{20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logger.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() returned %d events, want %d", len(got), len(tt.want))
+			}
+			for i, event := range got {
+				if event.Address != tt.want[i] {
+					t.Errorf("List()[%d].Address = %d, want %d", i, event.Address, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_ListLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+
+	// An older backup which cannot be read, as its line is longer than the scanner allows.
+	backupPath := filepath.Join(dir, "audit-2025-01-01T00-00-00.000.jsonl")
+	if err := os.WriteFile(backupPath, make([]byte, 2*1024*1024), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := NewLogger(&config.Audit{Path: path, MaxSizeMB: 1})
+	defer func() {
+		_ = logger.Close()
+	}()
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		event := Event{Time: start.Add(time.Duration(i) * time.Minute), Address: uint32(i), Success: true}
+		if err := logger.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// The newest events are all in the current file, so the backup is not read.
+	got, err := logger.List(Filter{Limit: 2})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Address != 2 || got[1].Address != 1 {
+		t.Errorf("List() = %+v, want the events at addresses 2 and 1", got)
+	}
+
+	// Without a limit which the current file can satisfy, the backup is read.
+	if _, err := logger.List(Filter{Limit: 4}); err == nil {
+		t.Error("List() error = nil, want an error reading the backup")
+	}
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	// APIKeyHeader is the header containing a static API key.
+	APIKeyHeader = "X-API-Key"
+	// bearerPrefix is the prefix of an Authorization header containing a bearer token.
+	bearerPrefix = "Bearer "
+	// leeway is the clock skew allowed when validating the time claims of a token.
+	leeway = time.Minute
+)
+
+var (
+	// ErrNoCredentials is returned when a request does not contain an API key or bearer token.
+	ErrNoCredentials = errors.New("no credentials provided")
+	// ErrInvalidCredentials is returned when the credentials provided in a request are not valid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// signatureAlgorithms are the algorithms accepted for signed tokens. Symmetric algorithms are not accepted, as the keys
+// are read from a JWKS file which may be shared with other services.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// apiKey is a configured API key with its decoded hash.
+type apiKey struct {
+	name string
+	hash []byte
+}
+
+// Authenticator authenticates requests using static API keys or JWT bearer tokens.
+type Authenticator struct {
+	apiKeys        []apiKey
+	keys           *jose.JSONWebKeySet
+	issuer         string
+	audience       string
+	principalClaim string
+}
+
+// NewAuthenticator returns an Authenticator for the given config. The JWKS file, if configured, is read once.
+func NewAuthenticator(authConfig *config.Auth) (*Authenticator, error) {
+	authenticator := &Authenticator{
+		issuer:         authConfig.JWT.Issuer,
+		audience:       authConfig.JWT.Audience,
+		principalClaim: authConfig.JWT.PrincipalClaim,
+	}
+	if authenticator.principalClaim == "" {
+		authenticator.principalClaim = "sub"
+	}
+	for i, key := range authConfig.APIKeys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("api key %d has no name", i)
+		}
+		hash, err := hex.DecodeString(key.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %q does not have a hex encoded SHA-256 hash", key.Name)
+		}
+		authenticator.apiKeys = append(authenticator.apiKeys, apiKey{name: key.Name, hash: hash})
+	}
+	if authConfig.JWT.JWKSFile != "" {
+		data, err := os.ReadFile(authConfig.JWT.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading JWKS file: %w", err)
+		}
+		var keys jose.JSONWebKeySet
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("error decoding JWKS file: %w", err)
+		}
+		authenticator.keys = &keys
+	}
+	if len(authenticator.apiKeys) == 0 && authenticator.keys == nil {
+		return nil, errors.New("authentication is enabled but no api keys or JWKS file are configured")
+	}
+	return authenticator, nil
+}
+
+// Authenticate returns the principal identified by the credentials in the given request headers. An API key in the
+// X-API-Key header takes precedence over a bearer token in the Authorization header.
+func (a *Authenticator) Authenticate(header http.Header) (Principal, error) {
+	if key := header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+	if authorization := header.Get("Authorization"); authorization != "" {
+		if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return a.authenticateToken(strings.TrimSpace(authorization[len(bearerPrefix):]))
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+// authenticateAPIKey compares the hash of the key against every configured key in constant time.
+func (a *Authenticator) authenticateAPIKey(key string) (Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	var principal Principal
+	found := false
+	for _, candidate := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], candidate.hash) == 1 {
+			principal = Principal{Name: candidate.name}
+			found = true
+		}
+	}
+	if !found {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+// authenticateToken verifies the signature of the token against the JWKS and validates its claims. Tokens must have
+// an expiry.
+func (a *Authenticator) authenticateToken(raw string) (Principal, error) {
+	if a.keys == nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+	keys := a.keys.Keys
+	if kid := token.Headers[0].KeyID; kid != "" {
+		keys = a.keys.Key(kid)
+	}
+	for _, key := range keys {
+		var claims jwt.Claims
+		custom := map[string]any{}
+		if err := token.Claims(key.Public(), &claims, &custom); err != nil {
+			continue
+		}
+		if claims.Expiry == nil {
+			return Principal{}, ErrInvalidCredentials
+		}
+		expected := jwt.Expected{Issuer: a.issuer, Time: time.Now()}
+		if a.audience != "" {
+			expected.AnyAudience = jwt.Audience{a.audience}
+		}
+		if err := claims.ValidateWithLeeway(expected, leeway); err != nil {
+			return Principal{}, ErrInvalidCredentials
+		}
+		name, ok := custom[a.principalClaim].(string)
+		if !ok || name == "" {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return Principal{Name: name}, nil
+	}
+	return Principal{}, ErrInvalidCredentials
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"modbustohttp/pkg/config"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: key.Public(), KeyID: "key-1", Algorithm: string(jose.ES256), Use: "sig"},
+	}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("secret-key"))
+	authenticator, err := NewAuthenticator(&config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{{Name: "scada", Hash: hex.EncodeToString(hash[:])}},
+		JWT:     config.JWT{JWKSFile: jwksFile, Issuer: "https://issuer.example", Audience: "modbustohttp"},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	sign := func(signingKey *ecdsa.PrivateKey, claims jwt.Claims) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: signingKey},
+			(&jose.SignerOptions{}).WithHeader("kid", "key-1"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.Signed(signer).Claims(claims).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	now := time.Now()
+	valid := jwt.Claims{
+		Subject:  "operator",
+		Issuer:   "https://issuer.example",
+		Audience: jwt.Audience{"modbustohttp"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+	expired := valid
+	expired.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.Audience{"other"}
+	noExpiry := valid
+	noExpiry.Expiry = nil
+
+	header := func(key, value string) http.Header {
+		h := http.Header{}
+		h.Set(key, value)
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    string
+		wantErr error
+	}{
+		{name: "No credentials", header: http.Header{}, wantErr: ErrNoCredentials},
+		{name: "Valid API key", header: header(APIKeyHeader, "secret-key"), want: "scada"},
+		{name: "Invalid API key", header: header(APIKeyHeader, "wrong-key"), wantErr: ErrInvalidCredentials},
+		{
+			name:   "Valid token",
+			header: header("Authorization", "Bearer "+sign(key, valid)),
+			want:   "operator",
+		},
+		{
+			name:    "Token signed by unknown key",
+			header:  header("Authorization", "Bearer "+sign(otherKey, valid)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "Expired token",
+			header:  header("Authorization", "Bearer "+sign(key, expired)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "Token for another audience",
+			header:  header("Authorization", "Bearer "+sign(key, wrongAudience)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "Token without expiry",
+			header:  header("Authorization", "Bearer "+sign(key, noExpiry)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{name: "Basic authorization", header: header("Authorization", "Basic Zm9vOmJhcg=="), wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authenticator.Authenticate(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Authenticate() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator_NoCredentialsConfigured(t *testing.T) {
+	if _, err := NewAuthenticator(&config.Auth{Enabled: true}); err == nil {
+		t.Error("NewAuthenticator() error = nil, want error")
+	}
+}
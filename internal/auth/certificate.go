@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/x509"
+	"net/http"
+)
+
+// PrincipalFromCertificate returns the principal identified by a verified client certificate. The common name of the
+// subject is used as the name of the principal, or the full subject if it has no common name.
+func PrincipalFromCertificate(certificate *x509.Certificate) Principal {
+	if certificate.Subject.CommonName != "" {
+		return Principal{Name: certificate.Subject.CommonName}
+	}
+	return Principal{Name: certificate.Subject.String()}
+}
+
+// NewCertificateMiddleware returns an HTTP middleware which attaches the principal identified by a verified client
+// certificate to the request context. Requests without a verified client certificate are passed through unchanged, so
+// that they can be authenticated by other means.
+func NewCertificateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			principal := PrincipalFromCertificate(r.TLS.VerifiedChains[0][0])
+			r = r.WithContext(NewContext(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewCertificateMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     *tls.ConnectionState
+		want      string
+		wantFound bool
+	}{
+		{name: "Plaintext", state: nil},
+		{name: "No client certificate", state: &tls.ConnectionState{}},
+		{
+			name: "Verified client certificate",
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+				{Subject: pkix.Name{CommonName: "plc-gateway", Organization: []string{"Plant"}}},
+			}}},
+			want:      "plc-gateway",
+			wantFound: true,
+		},
+		{
+			name: "Verified client certificate without common name",
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+				{Subject: pkix.Name{Organization: []string{"Plant"}}},
+			}}},
+			want:      "O=Plant",
+			wantFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Principal
+			var found bool
+			handler := NewCertificateMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, found = FromContext(r.Context())
+			}))
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			request.TLS = tt.state
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+			if found != tt.wantFound || got.Name != tt.want {
+				t.Errorf("principal = %q, %v, want %q, %v", got.Name, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package auth
+
+import "context"
+
+// Principal is the identity on whose behalf a request is made.
+type Principal struct {
+	// Name identifies the principal, for example the name of an API key or the subject of a token.
+	Name string
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the given principal.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal carried by ctx, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
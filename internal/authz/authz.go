@@ -0,0 +1,149 @@
+package authz
+
+import (
+	"fmt"
+	"modbustohttp/pkg/config"
+	"slices"
+)
+
+// AllProcedures is the procedure name which allows a role to call every RPC method.
+const AllProcedures = "*"
+
+// Range is an inclusive range of addresses in a modbus table.
+type Range struct {
+	// Table is the modbus table accessed
+	Table config.Table
+	// Start is the first address accessed
+	Start uint32
+	// End is the last address accessed
+	End uint32
+}
+
+// Access describes the part of a modbus server a request accesses.
+type Access struct {
+	// Procedure is the name of the RPC method called, for example "ReadCoils"
+	Procedure string
+	// Device is the slave ID of the modbus server accessed. It is ignored if Local is set.
+	Device byte
+	// Ranges are the address ranges accessed. It is empty if the request does not access a modbus table.
+	Ranges []Range
+	// Local is set if the request does not access the modbus server, such as listing audit events
+	Local bool
+	// AnyAddress is set if the request may access any address of the device, such as sending a raw PDU. It is only
+	// allowed by roles which name the procedure and are not limited to address ranges.
+	AnyAddress bool
+}
+
+// DeniedError is returned when a principal is not allowed to make a request.
+type DeniedError struct {
+	// Principal is the name of the principal which made the request
+	Principal string
+	// Procedure is the RPC method which was denied
+	Procedure string
+	// Table is the table containing Address. It is empty if the procedure itself was denied.
+	Table config.Table
+	// Address is the first address in the request which the principal is not allowed to access
+	Address uint32
+}
+
+func (e *DeniedError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("principal %q is not allowed to call %s", e.Principal, e.Procedure)
+	}
+	return fmt.Sprintf(
+		"principal %q is not allowed to call %s on address %d of %s",
+		e.Principal, e.Procedure, e.Address, e.Table,
+	)
+}
+
+// Policy decides which requests principals are allowed to make, based on the roles granted to them.
+type Policy struct {
+	roles  map[string]config.Role
+	grants map[string][]string
+}
+
+// NewPolicy returns a Policy for the given config. It returns an error if a grant refers to a role which does not exist
+// or an address range is invalid.
+func NewPolicy(authorizationConfig *config.Authorization) (*Policy, error) {
+	policy := &Policy{
+		roles:  make(map[string]config.Role, len(authorizationConfig.Roles)),
+		grants: make(map[string][]string, len(authorizationConfig.Grants)),
+	}
+	for _, role := range authorizationConfig.Roles {
+		if role.Name == "" {
+			return nil, fmt.Errorf("role has no name")
+		}
+		if _, ok := policy.roles[role.Name]; ok {
+			return nil, fmt.Errorf("role %q is defined more than once", role.Name)
+		}
+		for _, addressRange := range role.AddressRanges {
+			if addressRange.End < addressRange.Start {
+				return nil, fmt.Errorf("role %q has an address range which ends before it starts", role.Name)
+			}
+		}
+		policy.roles[role.Name] = role
+	}
+	for _, grant := range authorizationConfig.Grants {
+		for _, name := range grant.Roles {
+			if _, ok := policy.roles[name]; !ok {
+				return nil, fmt.Errorf("principal %q is granted role %q which does not exist", grant.Principal, name)
+			}
+		}
+		policy.grants[grant.Principal] = append(policy.grants[grant.Principal], grant.Roles...)
+	}
+	return policy, nil
+}
+
+// Authorize returns a *DeniedError if the principal with the given name is not allowed to make the access. Each
+// address accessed must be allowed by a role granted to the principal which also allows the procedure and device.
+func (p *Policy) Authorize(principal string, access Access) error {
+	var roles []config.Role
+	for _, name := range p.grants[principal] {
+		role := p.roles[name]
+		if !allowsProcedure(role, access.Procedure) {
+			continue
+		}
+		// The addresses of a request which may access any address cannot be checked against the address ranges, and the
+		// wildcard procedure does not allow it, so that it is never granted by accident.
+		if access.AnyAddress && (!slices.Contains(role.Procedures, access.Procedure) || len(role.AddressRanges) > 0) {
+			continue
+		}
+		if !access.Local && len(role.Devices) > 0 && !slices.Contains(role.Devices, access.Device) {
+			continue
+		}
+		roles = append(roles, role)
+	}
+	if len(roles) == 0 {
+		return &DeniedError{Principal: principal, Procedure: access.Procedure}
+	}
+	for _, accessRange := range access.Ranges {
+		for address := accessRange.Start; address <= accessRange.End; address++ {
+			if !slices.ContainsFunc(roles, func(role config.Role) bool {
+				return allowsAddress(role, accessRange.Table, address)
+			}) {
+				return &DeniedError{
+					Principal: principal,
+					Procedure: access.Procedure,
+					Table:     accessRange.Table,
+					Address:   address,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func allowsProcedure(role config.Role, procedure string) bool {
+	return slices.Contains(role.Procedures, AllProcedures) || slices.Contains(role.Procedures, procedure)
+}
+
+func allowsAddress(role config.Role, table config.Table, address uint32) bool {
+	if len(role.AddressRanges) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(role.AddressRanges, func(addressRange config.AddressRange) bool {
+		return addressRange.Table == table &&
+			address >= uint32(addressRange.Start) &&
+			address <= uint32(addressRange.End)
+	})
+}
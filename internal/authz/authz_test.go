@@ -0,0 +1,183 @@
+package authz
+
+import (
+	"errors"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy, err := NewPolicy(&config.Authorization{
+		Enabled: true,
+		Roles: []config.Role{
+			{Name: "dashboard", Procedures: []string{"ReadCoils", "ReadHoldingRegisters"}},
+			{
+				Name:       "engineer",
+				Procedures: []string{"WriteSingleCoil", "WriteMultipleCoils"},
+				Devices:    []byte{1},
+				AddressRanges: []config.AddressRange{
+					{Table: config.Coils, Start: 0, End: 9},
+					{Table: config.Coils, Start: 20, End: 29},
+				},
+			},
+			{Name: "admin", Procedures: []string{AllProcedures}},
+			{Name: "firmware", Procedures: []string{"SendRawPDU"}, Devices: []byte{1}},
+			{
+				Name:          "limited-firmware",
+				Procedures:    []string{"SendRawPDU"},
+				AddressRanges: []config.AddressRange{{Table: config.HoldingRegisters, Start: 0, End: 9}},
+			},
+		},
+		Grants: []config.Grant{
+			{Principal: "grafana", Roles: []string{"dashboard"}},
+			{Principal: "alice", Roles: []string{"dashboard", "engineer", "firmware"}},
+			{Principal: "root", Roles: []string{"admin"}},
+			{Principal: "bob", Roles: []string{"limited-firmware"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		principal   string
+		procedure   string
+		device      byte
+		msg         any
+		wantDenied  bool
+		wantTable   config.Table
+		wantAddress uint32
+	}{
+		{
+			name:      "Read allowed",
+			principal: "grafana",
+			procedure: "/modbustohttp.v1alpha1.ModbusService/ReadCoils",
+			device:    1,
+			msg:       &modbusv1alpha1.ReadCoilsRequest{Address: 100, Quantity: 10},
+		},
+		{
+			name:       "Procedure not granted",
+			principal:  "grafana",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/WriteSingleCoil",
+			device:     1,
+			msg:        &modbusv1alpha1.WriteSingleCoilRequest{Coil: &modbusv1alpha1.BooleanAddress{Address: 1}},
+			wantDenied: true,
+		},
+		{
+			name:       "Unknown principal",
+			principal:  "mallory",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/ReadCoils",
+			device:     1,
+			msg:        &modbusv1alpha1.ReadCoilsRequest{Address: 0, Quantity: 1},
+			wantDenied: true,
+		},
+		{
+			name:      "Write within range",
+			principal: "alice",
+			procedure: "/modbustohttp.v1alpha1.ModbusService/WriteMultipleCoils",
+			device:    1,
+			msg:       &modbusv1alpha1.WriteMultipleCoilsRequest{Address: 5, Values: []bool{true, false, true}},
+		},
+		{
+			name:        "Write partially outside range",
+			principal:   "alice",
+			procedure:   "/modbustohttp.v1alpha1.ModbusService/WriteMultipleCoils",
+			device:      1,
+			msg:         &modbusv1alpha1.WriteMultipleCoilsRequest{Address: 8, Values: []bool{true, false, true}},
+			wantDenied:  true,
+			wantTable:   config.Coils,
+			wantAddress: 10,
+		},
+		{
+			name:       "Write to another device",
+			principal:  "alice",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/WriteSingleCoil",
+			device:     2,
+			msg:        &modbusv1alpha1.WriteSingleCoilRequest{Coil: &modbusv1alpha1.BooleanAddress{Address: 1}},
+			wantDenied: true,
+		},
+		{
+			name:      "Raw PDU to the configured device",
+			principal: "alice",
+			procedure: "/modbustohttp.v1alpha1.ModbusService/SendRawPDU",
+			device:    1,
+			msg:       &modbusv1alpha1.SendRawPDURequest{FunctionCode: 65},
+		},
+		{
+			name:       "Raw PDU to another device",
+			principal:  "alice",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/SendRawPDU",
+			device:     1,
+			msg:        &modbusv1alpha1.SendRawPDURequest{FunctionCode: 65, UnitId: proto.Uint32(2)},
+			wantDenied: true,
+		},
+		{
+			name:       "Raw PDU with the wildcard procedure",
+			principal:  "root",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/SendRawPDU",
+			device:     1,
+			msg:        &modbusv1alpha1.SendRawPDURequest{FunctionCode: 65},
+			wantDenied: true,
+		},
+		{
+			name:       "Raw PDU with address ranges",
+			principal:  "bob",
+			procedure:  "/modbustohttp.v1alpha1.ModbusService/SendRawPDU",
+			device:     1,
+			msg:        &modbusv1alpha1.SendRawPDURequest{FunctionCode: 65},
+			wantDenied: true,
+		},
+		{
+			name:      "Wildcard procedure without a table",
+			principal: "root",
+			procedure: "/modbustohttp.v1alpha1.AuditService/ListAuditEvents",
+			device:    1,
+			msg:       &modbusv1alpha1.ListAuditEventsRequest{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(tt.principal, RequestAccess(tt.procedure, tt.device, tt.msg))
+			var deniedErr *DeniedError
+			if denied := errors.As(err, &deniedErr); denied != tt.wantDenied {
+				t.Fatalf("Authorize() error = %v, want denied %v", err, tt.wantDenied)
+			}
+			if deniedErr != nil && (deniedErr.Table != tt.wantTable || deniedErr.Address != tt.wantAddress) {
+				t.Errorf("Authorize() denied %s address %d, want %s address %d",
+					deniedErr.Table, deniedErr.Address, tt.wantTable, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestNewPolicy_UnknownRole(t *testing.T) {
+	_, err := NewPolicy(&config.Authorization{
+		Grants: []config.Grant{{Principal: "alice", Roles: []string{"missing"}}},
+	})
+	if err == nil {
+		t.Error("NewPolicy() error = nil, want error")
+	}
+}
+
+func TestRequestAccess(t *testing.T) {
+	access := RequestAccess(
+		"/modbustohttp.v1alpha1.ModbusService/ReadWriteMultipleRegisters",
+		1,
+		&modbusv1alpha1.ReadWriteMultipleRegistersRequest{
+			ReadAddress: 100, ReadQuantity: 10, WriteAddress: 200, Values: []uint32{1, 2},
+		},
+	)
+	want := []Range{
+		{Table: config.HoldingRegisters, Start: 100, End: 109},
+		{Table: config.HoldingRegisters, Start: 200, End: 201},
+	}
+	if access.Procedure != "ReadWriteMultipleRegisters" || !slices.Equal(access.Ranges, want) {
+		t.Errorf("RequestAccess() = %+v, want procedure ReadWriteMultipleRegisters and ranges %+v", access, want)
+	}
+}
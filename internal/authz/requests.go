@@ -0,0 +1,74 @@
+package authz
+
+import (
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"strings"
+)
+
+// RequestAccess returns the Access made by a call to the given procedure with the given request message on the modbus
+// server with the given slave ID. Requests which do not access a modbus table, such as reading device identification,
+// accessing file records or sending raw PDUs, have an empty Ranges. ReadFIFOQueue requests access the FIFO pointer
+// register. SendRawPDU requests access the device with the unit ID in the request, if it is set, and may access any of
+// its addresses.
+//
+// Listing audit events is Local, as it does not access the modbus server. ExecuteWrite requests are also Local, as the
+// address was authorized by PrepareWrite and the token can only be executed by the principal which prepared it.
+func RequestAccess(procedure string, device byte, msg any) Access {
+	access := Access{Procedure: procedure[strings.LastIndex(procedure, "/")+1:], Device: device}
+	switch msg := msg.(type) {
+	case *modbusv1alpha1.ReadCoilsRequest:
+		access.addRange(config.Coils, msg.GetAddress(), msg.GetQuantity())
+	case *modbusv1alpha1.ReadDiscreteInputsRequest:
+		access.addRange(config.DiscreteInputs, msg.GetAddress(), msg.GetQuantity())
+	case *modbusv1alpha1.ReadInputRegistersRequest:
+		access.addRange(config.InputRegisters, msg.GetAddress(), msg.GetQuantity())
+	case *modbusv1alpha1.ReadHoldingRegistersRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), msg.GetQuantity())
+	case *modbusv1alpha1.WriteSingleCoilRequest:
+		access.addRange(config.Coils, msg.GetCoil().GetAddress(), 1)
+	case *modbusv1alpha1.WriteMultipleCoilsRequest:
+		access.addRange(config.Coils, msg.GetAddress(), uint32(len(msg.GetValues())))
+	case *modbusv1alpha1.WriteSingleRegisterRequest:
+		access.addRange(config.HoldingRegisters, msg.GetRegister().GetAddress(), 1)
+	case *modbusv1alpha1.WriteMultipleRegistersRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), uint32(len(msg.GetValues())))
+	case *modbusv1alpha1.WriteBitInRegisterRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), 1)
+	case *modbusv1alpha1.MaskWriteRegisterRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), 1)
+	case *modbusv1alpha1.WriteBitsInRegisterRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), 1)
+	case *modbusv1alpha1.ReadRegisterAsBitsRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), 1)
+	case *modbusv1alpha1.ReadWriteMultipleRegistersRequest:
+		access.addRange(config.HoldingRegisters, msg.GetReadAddress(), msg.GetReadQuantity())
+		access.addRange(config.HoldingRegisters, msg.GetWriteAddress(), uint32(len(msg.GetValues())))
+	case *modbusv1alpha1.ReadFIFOQueueRequest:
+		access.addRange(config.HoldingRegisters, msg.GetAddress(), 1)
+	case *modbusv1alpha1.SendRawPDURequest:
+		if msg.UnitId != nil {
+			access.Device = byte(msg.GetUnitId())
+		}
+		access.AnyAddress = true
+	case *modbusv1alpha1.ExecuteWriteRequest, *modbusv1alpha1.ListAuditEventsRequest:
+		access.Local = true
+	case *modbusv1alpha1.PrepareWriteRequest:
+		if msg.GetCoil() != nil {
+			access.addRange(config.Coils, msg.GetCoil().GetAddress(), 1)
+		} else {
+			access.addRange(config.HoldingRegisters, msg.GetRegister().GetAddress(), 1)
+		}
+	}
+	return access
+}
+
+// addRange adds an address range accessed. A quantity of zero is treated as a single address so that the start
+// address is always checked.
+func (a *Access) addRange(table config.Table, address uint32, quantity uint32) {
+	accessRange := Range{Table: table, Start: address, End: address}
+	if quantity > 1 {
+		accessRange.End = address + quantity - 1
+	}
+	a.Ranges = append(a.Ranges, accessRange)
+}
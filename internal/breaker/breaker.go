@@ -0,0 +1,144 @@
+// Package breaker stops requests being sent to a modbus server which is unreachable, so that they fail fast instead of
+// each retrying the connection until it times out.
+package breaker
+
+import (
+	"fmt"
+	"modbustohttp/pkg/config"
+	"sync"
+	"time"
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed lets every request through. It is the state while the modbus server is reachable.
+	Closed State = iota
+	// Open rejects every request until the cool-down has passed.
+	Open
+	// HalfOpen lets a single request through to probe whether the modbus server is reachable again, and rejects the
+	// others until it completes.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// OpenError is returned by Allow when a request is rejected because the breaker is open.
+type OpenError struct {
+	// RetryAfter is how long until the breaker lets a request through again
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open, retry after %v", e.RetryAfter)
+}
+
+// Breaker counts the consecutive failures of the connection to the modbus server, and opens once they reach the
+// failure threshold. After the cool-down it lets a single request through, closing again if it succeeds or staying open
+// for another cool-down if it fails.
+type Breaker struct {
+	failureThreshold int
+	coolDown         time.Duration
+	// onChange is called with the new state whenever the state changes, with the mutex held.
+	onChange func(State)
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	// openedAt is when the breaker last opened, or when the probe was let through while half-open.
+	openedAt time.Time
+}
+
+// New returns a closed Breaker. onChange, if not nil, is called with the new state whenever the state changes, and must
+// not call the Breaker.
+func New(breakerConfig *config.CircuitBreaker, onChange func(State)) *Breaker {
+	failureThreshold := breakerConfig.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	coolDown := breakerConfig.CoolDown
+	if coolDown == 0 {
+		coolDown = 30 * time.Second
+	}
+	return &Breaker{
+		failureThreshold: failureThreshold,
+		coolDown:         coolDown,
+		onChange:         onChange,
+		now:              time.Now,
+	}
+}
+
+// Allow returns nil if a request may be sent, or an *OpenError if it must be rejected. Once the cool-down has passed,
+// the first request allowed is the probe, and its outcome must be recorded with Success or Failure. If the probe is not
+// recorded within another cool-down, a further probe is let through.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Closed {
+		return nil
+	}
+	now := b.now()
+	if retryAfter := b.openedAt.Add(b.coolDown).Sub(now); retryAfter > 0 {
+		if b.state == HalfOpen {
+			// The probe has not completed, and may close the breaker at any time.
+			retryAfter = 0
+		}
+		return &OpenError{RetryAfter: retryAfter}
+	}
+	b.openedAt = now
+	b.setState(HalfOpen)
+	return nil
+}
+
+// Success records that the modbus server was reached, closing the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.setState(Closed)
+}
+
+// Failure records that the modbus server could not be reached, opening the breaker if the failure threshold has been
+// reached or the probe failed.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == HalfOpen || (b.state == Closed && b.failures >= b.failureThreshold) {
+		b.openedAt = b.now()
+		b.setState(Open)
+	}
+}
+
+// State returns the current state of the breaker. An open breaker is reported as open until a request is let through
+// after the cool-down.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// setState changes the state, calling onChange if it differs from the current state. It must be called with the mutex
+// held.
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
@@ -0,0 +1,80 @@
+package breaker
+
+import (
+	"errors"
+	"modbustohttp/pkg/config"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var changes []State
+	b := New(&config.CircuitBreaker{FailureThreshold: 3, CoolDown: 10 * time.Second}, func(state State) {
+		changes = append(changes, state)
+	})
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	if err := b.Allow(); err != nil || b.State() != Closed {
+		t.Fatalf("Allow() = %v, State() = %v below the failure threshold, want nil, closed", err, b.State())
+	}
+
+	b.Failure()
+	var openErr *OpenError
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 10*time.Second {
+		t.Fatalf("Allow() once the failure threshold is reached = %v, want retry after 10s", err)
+	}
+	now = now.Add(4 * time.Second)
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 6*time.Second {
+		t.Errorf("Allow() during the cool-down = %v, want retry after 6s", err)
+	}
+
+	// Only one probe is let through after the cool-down, and the breaker opens again if it fails.
+	now = now.Add(6 * time.Second)
+	if err := b.Allow(); err != nil || b.State() != HalfOpen {
+		t.Fatalf("Allow() after the cool-down = %v, State() = %v, want nil, half-open", err, b.State())
+	}
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 0 {
+		t.Errorf("Allow() during the probe = %v, want retry after 0s", err)
+	}
+	b.Failure()
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 10*time.Second {
+		t.Fatalf("Allow() after the probe failed = %v, want retry after 10s", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after the cool-down error = %v", err)
+	}
+	b.Success()
+	if err := b.Allow(); err != nil || b.State() != Closed {
+		t.Errorf("Allow() after the probe succeeded = %v, State() = %v, want nil, closed", err, b.State())
+	}
+
+	if want := []State{Open, HalfOpen, Open, HalfOpen, Closed}; !slices.Equal(changes, want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestBreaker_ProbeLost(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(&config.CircuitBreaker{FailureThreshold: 1, CoolDown: time.Second}, nil)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	now = now.Add(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after the cool-down error = %v", err)
+	}
+	// The outcome of the probe is never recorded, so another is let through after a further cool-down.
+	now = now.Add(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() once the probe was lost error = %v", err)
+	}
+}
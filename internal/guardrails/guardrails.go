@@ -0,0 +1,191 @@
+package guardrails
+
+import (
+	"fmt"
+	"modbustohttp/pkg/config"
+	"slices"
+	"strings"
+)
+
+// ViolationType is the kind of write rule which was not followed.
+type ViolationType string
+
+const (
+	// AddressNotAllowed is a write to an address which is not covered by any rule when unlisted addresses are denied.
+	AddressNotAllowed ViolationType = "ADDRESS_NOT_ALLOWED"
+	// BelowMin is a write of a value smaller than the minimum of a rule.
+	BelowMin ViolationType = "BELOW_MIN"
+	// AboveMax is a write of a value larger than the maximum of a rule.
+	AboveMax ViolationType = "ABOVE_MAX"
+	// ValueNotAllowed is a write of a value which is not one of the allowed values of a rule.
+	ValueNotAllowed ViolationType = "VALUE_NOT_ALLOWED"
+	// StepTooLarge is a write which changes the current value by more than the max step of a rule.
+	StepTooLarge ViolationType = "STEP_TOO_LARGE"
+	// ArmRequired is a direct write to an address which must be written using PrepareWrite and ExecuteWrite.
+	ArmRequired ViolationType = "ARM_REQUIRED"
+)
+
+// Violation is a single write rule which was not followed.
+type Violation struct {
+	// Type is the kind of rule which was not followed
+	Type ViolationType
+	// Rule is the name of the rule which was not followed. It is empty for AddressNotAllowed.
+	Rule string
+	// Table is the table written to
+	Table config.Table
+	// Address is the address written to
+	Address uint32
+	// Description explains why the write is not allowed
+	Description string
+}
+
+// ViolationError is returned when a write does not follow the write rules.
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	descriptions := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		descriptions[i] = violation.Description
+	}
+	return "write not allowed: " + strings.Join(descriptions, "; ")
+}
+
+// Guard checks writes against the configured write rules.
+type Guard struct {
+	rules        []config.WriteRule
+	denyUnlisted bool
+}
+
+// New returns a Guard for the given config. It returns an error if a rule is invalid.
+func New(guardrailsConfig *config.Guardrails) (*Guard, error) {
+	for i, rule := range guardrailsConfig.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		if rule.Table != config.Coils && rule.Table != config.HoldingRegisters {
+			return nil, fmt.Errorf("write rule %q must apply to %s or %s", name, config.Coils, config.HoldingRegisters)
+		}
+		if rule.End < rule.Start {
+			return nil, fmt.Errorf("write rule %q ends before it starts", name)
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Max < *rule.Min {
+			return nil, fmt.Errorf("write rule %q has a max smaller than its min", name)
+		}
+	}
+	return &Guard{rules: guardrailsConfig.Rules, denyUnlisted: guardrailsConfig.DenyUnlisted}, nil
+}
+
+// covering returns the rules which apply to the given address.
+func (g *Guard) covering(table config.Table, address uint32) []config.WriteRule {
+	var rules []config.WriteRule
+	for _, rule := range g.rules {
+		if rule.Table == table && address >= uint32(rule.Start) && address <= uint32(rule.End) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Covers returns whether any rule applies to an address in the given range. Writes which only change part of a
+// register must read its current value to be checked against the rules.
+func (g *Guard) Covers(table config.Table, address uint32, quantity uint32) bool {
+	for offset := range quantity {
+		if len(g.covering(table, address+offset)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsCurrent returns whether a rule with a max step applies to an address in the given range, in which case the
+// current values must be passed to Check.
+func (g *Guard) NeedsCurrent(table config.Table, address uint32, quantity uint32) bool {
+	for offset := range quantity {
+		if slices.ContainsFunc(g.covering(table, address+offset), func(rule config.WriteRule) bool {
+			return rule.MaxStep != nil
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns a *ViolationError listing every rule the write of values starting at address does not follow. Max
+// steps are only checked if the current values of the addresses are given.
+func (g *Guard) Check(table config.Table, address uint32, values []uint32, current []uint32) error {
+	var violations []Violation
+	for i, value := range values {
+		valueAddress := address + uint32(i)
+		rules := g.covering(table, valueAddress)
+		if len(rules) == 0 && g.denyUnlisted {
+			violations = append(violations, Violation{
+				Type:        AddressNotAllowed,
+				Table:       table,
+				Address:     valueAddress,
+				Description: fmt.Sprintf("%s address %d is not covered by any write rule", table, valueAddress),
+			})
+			continue
+		}
+		for _, rule := range rules {
+			violation := Violation{Rule: rule.Name, Table: table, Address: valueAddress}
+			describe := func(violationType ViolationType, format string, args ...any) {
+				violation.Type = violationType
+				violation.Description = fmt.Sprintf("rule %q: %s address %d: ", rule.Name, table, valueAddress) +
+					fmt.Sprintf(format, args...)
+				violations = append(violations, violation)
+			}
+			if rule.Min != nil && value < uint32(*rule.Min) {
+				describe(BelowMin, "value %d is less than the minimum %d", value, *rule.Min)
+			}
+			if rule.Max != nil && value > uint32(*rule.Max) {
+				describe(AboveMax, "value %d is greater than the maximum %d", value, *rule.Max)
+			}
+			if len(rule.AllowedValues) > 0 && !slices.ContainsFunc(rule.AllowedValues, func(allowed uint16) bool {
+				return uint32(allowed) == value
+			}) {
+				describe(ValueNotAllowed, "value %d is not one of the allowed values %v", value, rule.AllowedValues)
+			}
+			if rule.MaxStep != nil && i < len(current) {
+				step := max(value, current[i]) - min(value, current[i])
+				if step > uint32(*rule.MaxStep) {
+					describe(StepTooLarge, "changing the value from %d to %d is a step of %d, more than the maximum %d",
+						current[i], value, step, *rule.MaxStep)
+				}
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckArm returns a *ViolationError listing every address in the given range which can only be written using
+// PrepareWrite and ExecuteWrite.
+func (g *Guard) CheckArm(table config.Table, address uint32, quantity uint32) error {
+	var violations []Violation
+	for offset := range quantity {
+		for _, rule := range g.covering(table, address+offset) {
+			if rule.RequireArm {
+				violations = append(violations, Violation{
+					Type:    ArmRequired,
+					Rule:    rule.Name,
+					Table:   table,
+					Address: address + offset,
+					Description: fmt.Sprintf(
+						"rule %q: %s address %d must be written using PrepareWrite and ExecuteWrite",
+						rule.Name, table, address+offset,
+					),
+				})
+				break
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ViolationError{Violations: violations}
+	}
+	return nil
+}
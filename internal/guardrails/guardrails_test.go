@@ -0,0 +1,135 @@
+package guardrails
+
+import (
+	"errors"
+	"modbustohttp/pkg/config"
+	"reflect"
+	"testing"
+)
+
+func ptr(v uint16) *uint16 {
+	return &v
+}
+
+func TestGuard_Check(t *testing.T) {
+	guard, err := New(&config.Guardrails{
+		Enabled:      true,
+		DenyUnlisted: true,
+		Rules: []config.WriteRule{
+			{
+				Name: "vfd-speed", Table: config.HoldingRegisters, Start: 100, End: 100,
+				Min: ptr(0), Max: ptr(1500), MaxStep: ptr(100),
+			},
+			{
+				Name: "mode", Table: config.HoldingRegisters, Start: 101, End: 101,
+				AllowedValues: []uint16{0, 1, 2},
+			},
+			{Name: "pumps", Table: config.Coils, Start: 0, End: 9},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		table   config.Table
+		address uint32
+		values  []uint32
+		current []uint32
+		want    []ViolationType
+	}{
+		{name: "Within bounds", table: config.HoldingRegisters, address: 100, values: []uint32{1200, 2}},
+		{
+			name: "Above max", table: config.HoldingRegisters, address: 100, values: []uint32{9999},
+			want: []ViolationType{AboveMax},
+		},
+		{
+			name: "Value not allowed", table: config.HoldingRegisters, address: 101, values: []uint32{3},
+			want: []ViolationType{ValueNotAllowed},
+		},
+		{
+			name: "Step too large", table: config.HoldingRegisters, address: 100, values: []uint32{900},
+			current: []uint32{700},
+			want:    []ViolationType{StepTooLarge},
+		},
+		{
+			name: "Step skipped without current values", table: config.HoldingRegisters, address: 100,
+			values: []uint32{900},
+		},
+		{
+			name: "Unlisted address", table: config.HoldingRegisters, address: 101, values: []uint32{1, 5},
+			want: []ViolationType{AddressNotAllowed},
+		},
+		{name: "Listed coils", table: config.Coils, address: 8, values: []uint32{1, 0}},
+		{
+			name: "Unlisted coil", table: config.Coils, address: 9, values: []uint32{1, 1},
+			want: []ViolationType{AddressNotAllowed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := guard.Check(tt.table, tt.address, tt.values, tt.current)
+			var got []ViolationType
+			var violationErr *ViolationError
+			if errors.As(err, &violationErr) {
+				for _, violation := range violationErr.Violations {
+					got = append(got, violation.Type)
+				}
+			} else if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuard_NeedsCurrent(t *testing.T) {
+	guard, err := New(&config.Guardrails{
+		Rules: []config.WriteRule{
+			{Name: "speed", Table: config.HoldingRegisters, Start: 10, End: 19, MaxStep: ptr(5)},
+			{Name: "mode", Table: config.HoldingRegisters, Start: 20, End: 29, Max: ptr(3)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if !guard.NeedsCurrent(config.HoldingRegisters, 5, 10) {
+		t.Error("NeedsCurrent() = false for a range overlapping a max step rule, want true")
+	}
+	if guard.NeedsCurrent(config.HoldingRegisters, 20, 5) {
+		t.Error("NeedsCurrent() = true for a range without a max step rule, want false")
+	}
+}
+
+func TestNew_InvalidTable(t *testing.T) {
+	_, err := New(&config.Guardrails{
+		Rules: []config.WriteRule{{Name: "inputs", Table: config.InputRegisters}},
+	})
+	if err == nil {
+		t.Error("New() error = nil, want error")
+	}
+}
+
+func TestGuard_CheckArm(t *testing.T) {
+	guard, err := New(&config.Guardrails{
+		Rules: []config.WriteRule{
+			{Name: "breaker", Table: config.Coils, Start: 5, End: 5, RequireArm: true},
+			{Name: "pumps", Table: config.Coils, Start: 0, End: 9},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := guard.CheckArm(config.Coils, 0, 5); err != nil {
+		t.Errorf("CheckArm() error = %v, want nil", err)
+	}
+	var violationErr *ViolationError
+	if err := guard.CheckArm(config.Coils, 4, 3); !errors.As(err, &violationErr) ||
+		len(violationErr.Violations) != 1 || violationErr.Violations[0].Address != 5 {
+		t.Errorf("CheckArm() error = %v, want a violation for address 5", err)
+	}
+}
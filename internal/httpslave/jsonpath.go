@@ -0,0 +1,80 @@
+package httpslave
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a step of a JSON path, either the key of an object member or the index of an array element.
+type segment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath parses a JSON path made of object keys and array indexes, such as $.sensors[0].temperature. The leading $
+// is optional.
+func parsePath(path string) ([]segment, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var segments []segment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : 1+end]
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			segments = append(segments, segment{key: key, isKey: true})
+			rest = rest[1+end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("path %q has an unterminated index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %q has an invalid index %q", path, rest[1:end])
+			}
+			segments = append(segments, segment{index: index})
+			rest = rest[end+1:]
+		default:
+			// A path such as sensors.temperature starts with a key.
+			if len(segments) > 0 || rest != strings.TrimPrefix(path, "$") {
+				return nil, fmt.Errorf("path %q is invalid at %q", path, rest)
+			}
+			rest = "." + rest
+		}
+	}
+	return segments, nil
+}
+
+// extract returns the value selected by the path in a decoded JSON document.
+func extract(document any, segments []segment) (any, error) {
+	value := document
+	for _, segment := range segments {
+		if segment.isKey {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot select key %q of a non-object", segment.key)
+			}
+			if value, ok = object[segment.key]; !ok {
+				return nil, fmt.Errorf("key %q not found", segment.key)
+			}
+			continue
+		}
+		array, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot select index %d of a non-array", segment.index)
+		}
+		if segment.index >= len(array) {
+			return nil, fmt.Errorf("index %d out of range", segment.index)
+		}
+		value = array[segment.index]
+	}
+	return value, nil
+}
@@ -0,0 +1,46 @@
+package httpslave
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	var document any
+	if err := json.Unmarshal([]byte(`{"sensors":[{"temperature":21.5},{"temperature":19}],"running":true}`),
+		&document,
+	); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{name: "Key", path: "$.running", want: true},
+		{name: "Without $", path: "running", want: true},
+		{name: "Index", path: "$.sensors[1].temperature", want: float64(19)},
+		{name: "Missing key", path: "$.pressure", wantErr: true},
+		{name: "Index out of range", path: "$.sensors[2]", wantErr: true},
+		{name: "Index of object", path: "$.running[0]", wantErr: true},
+		{name: "Unterminated index", path: "$.sensors[0", wantErr: true},
+		{name: "Negative index", path: "$.sensors[-1]", wantErr: true},
+		{name: "Empty key", path: "$..running", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := parsePath(tt.path)
+			if err == nil {
+				var got any
+				got, err = extract(document, segments)
+				if err == nil && got != tt.want {
+					t.Errorf("extract(%q) = %v, want %v", tt.path, got, tt.want)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extract(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,478 @@
+// Package httpslave serves values held by HTTP endpoints to Modbus TCP masters, so that masters which only speak
+// Modbus can read and write them.
+package httpslave
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	defaultRefreshInterval = 5 * time.Second
+	defaultRequestTimeout  = 5 * time.Second
+	// maxResponseSize is the largest response body read from an endpoint
+	maxResponseSize = 1 << 20
+)
+
+// mapping is a validated config.SlaveMapping.
+type mapping struct {
+	config.SlaveMapping
+	path []segment
+}
+
+// contains returns whether the mapping includes the address in the table.
+func (m mapping) contains(table config.Table, address int) bool {
+	return m.Table == table && address >= int(m.Address) && address < int(m.Address)+int(m.Quantity)
+}
+
+// document is the decoded response of an endpoint.
+type document struct {
+	value   any
+	fetched time.Time
+}
+
+// Handler is a modbusserver.Handler which reads and writes the values of HTTP endpoints. Reads are served from the
+// responses fetched by Run, and writes are sent to the endpoints before they are acknowledged.
+type Handler struct {
+	mappings        []mapping
+	client          *http.Client
+	logger          *slog.Logger
+	refreshInterval time.Duration
+	maxAge          time.Duration
+	requestTimeout  time.Duration
+
+	mu        sync.Mutex
+	documents map[string]document
+}
+
+// New returns a Handler for the mappings in the config, using the client to make requests.
+func New(slaveConfig *config.Slave, client *http.Client, logger *slog.Logger) (*Handler, error) {
+	h := &Handler{
+		client:          client,
+		logger:          logger,
+		refreshInterval: slaveConfig.RefreshInterval,
+		maxAge:          slaveConfig.MaxAge,
+		requestTimeout:  slaveConfig.RequestTimeout,
+		documents:       make(map[string]document),
+	}
+	if h.refreshInterval <= 0 {
+		h.refreshInterval = defaultRefreshInterval
+	}
+	if h.maxAge <= 0 {
+		h.maxAge = 3 * h.refreshInterval
+	}
+	if h.requestTimeout <= 0 {
+		h.requestTimeout = defaultRequestTimeout
+	}
+	for i, slaveMapping := range slaveConfig.Mappings {
+		if slaveMapping.Quantity == 0 {
+			slaveMapping.Quantity = 1
+		}
+		if slaveMapping.Scale == 0 {
+			slaveMapping.Scale = 1
+		}
+		switch slaveMapping.Table {
+		case config.Coils, config.HoldingRegisters:
+		case config.DiscreteInputs, config.InputRegisters:
+			if slaveMapping.WriteURL != "" {
+				return nil, fmt.Errorf("mapping %d has a write URL but %s cannot be written", i, slaveMapping.Table)
+			}
+		default:
+			return nil, fmt.Errorf("mapping %d is in unknown table %q", i, slaveMapping.Table)
+		}
+		if int(slaveMapping.Address)+int(slaveMapping.Quantity) > 65536 {
+			return nil, fmt.Errorf("mapping %d does not fit in the %s", i, slaveMapping.Table)
+		}
+		if slaveMapping.URL == "" {
+			return nil, fmt.Errorf("mapping %d has no URL", i)
+		}
+		path, err := parsePath(slaveMapping.Path)
+		if err != nil {
+			return nil, fmt.Errorf("mapping %d: %w", i, err)
+		}
+		for j, other := range h.mappings {
+			if other.Table == slaveMapping.Table && other.Address < slaveMapping.Address+slaveMapping.Quantity &&
+				slaveMapping.Address < other.Address+other.Quantity {
+				return nil, fmt.Errorf("mapping %d overlaps mapping %d", i, j)
+			}
+		}
+		h.mappings = append(h.mappings, mapping{SlaveMapping: slaveMapping, path: path})
+	}
+	return h, nil
+}
+
+// urls returns the URL of every mapping, without duplicates.
+func (h *Handler) urls() []string {
+	var urls []string
+	for _, m := range h.mappings {
+		if !slices.Contains(urls, m.URL) {
+			urls = append(urls, m.URL)
+		}
+	}
+	return urls
+}
+
+// Run fetches the endpoints at each refresh interval until the context is cancelled.
+func (h *Handler) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.refreshInterval)
+	defer ticker.Stop()
+	for {
+		h.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches every endpoint once. Endpoints which cannot be fetched keep their previous response, which is not
+// served once it is older than the max age.
+func (h *Handler) Refresh(ctx context.Context) {
+	for _, url := range h.urls() {
+		if err := h.fetch(ctx, url); err != nil {
+			h.logger.Warn("error refreshing endpoint",
+				slog.String("url", url),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// fetch reads the endpoint and caches its response.
+func (h *Handler) fetch(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, h.requestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	body, err := h.do(request)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("GET %s returned invalid JSON: %w", url, err)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.documents[url] = document{value: value, fetched: time.Now()}
+	return nil
+}
+
+// do sends the request, and returns the body of the response if it is successful.
+func (h *Handler) do(request *http.Request) ([]byte, error) {
+	response, err := h.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s returned %s", request.Method, request.URL, response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+}
+
+// values returns the values of the mapping from the cached response of its endpoint, or an exception code if they
+// cannot be served.
+func (h *Handler) values(m mapping) ([]uint16, byte) {
+	h.mu.Lock()
+	doc, ok := h.documents[m.URL]
+	h.mu.Unlock()
+	if !ok || time.Since(doc.fetched) > h.maxAge {
+		return nil, modbus.ExceptionCodeServerDeviceFailure
+	}
+	value, err := extract(doc.value, m.path)
+	if err != nil {
+		h.logger.Warn("error extracting value", slog.String("url", m.URL), slog.String("error", err.Error()))
+		return nil, modbus.ExceptionCodeServerDeviceFailure
+	}
+	values, err := toRegisters(value, int(m.Quantity), m.Scale, m.Signed)
+	if err != nil {
+		h.logger.Warn("error converting value", slog.String("url", m.URL), slog.String("error", err.Error()))
+		return nil, modbus.ExceptionCodeServerDeviceFailure
+	}
+	return values, 0
+}
+
+// toRegisters converts a JSON value to quantity register values. Numbers are multiplied by the scale and rounded, and
+// stored as two's complement if signed. Booleans are 1 if true and 0 if false.
+func toRegisters(value any, quantity int, scale float64, signed bool) ([]uint16, error) {
+	elements := []any{value}
+	if array, ok := value.([]any); ok {
+		elements = array
+	}
+	if len(elements) != quantity {
+		return nil, fmt.Errorf("%d values found, want %d", len(elements), quantity)
+	}
+	values := make([]uint16, quantity)
+	for i, element := range elements {
+		switch element := element.(type) {
+		case bool:
+			if element {
+				values[i] = 1
+			}
+		case float64:
+			scaled := math.Round(element * scale)
+			if signed && (scaled < math.MinInt16 || scaled > math.MaxInt16) ||
+				!signed && (scaled < 0 || scaled > math.MaxUint16) {
+				return nil, fmt.Errorf("value %v does not fit in a register", element)
+			}
+			values[i] = uint16(int32(scaled))
+		default:
+			return nil, fmt.Errorf("value %v is not a number or boolean", element)
+		}
+	}
+	return values, nil
+}
+
+// HandlePDU reads or writes the values of the endpoints mapped to the addresses of the request.
+func (h *Handler) HandlePDU(
+	ctx context.Context,
+	_ byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	switch request.FunctionCode {
+	case modbus.FuncCodeReadCoils:
+		return h.read(request, config.Coils, 2000), nil
+	case modbus.FuncCodeReadDiscreteInputs:
+		return h.read(request, config.DiscreteInputs, 2000), nil
+	case modbus.FuncCodeReadHoldingRegisters:
+		return h.read(request, config.HoldingRegisters, 125), nil
+	case modbus.FuncCodeReadInputRegisters:
+		return h.read(request, config.InputRegisters, 125), nil
+	case modbus.FuncCodeWriteSingleCoil:
+		if len(request.Data) != 4 {
+			return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue), nil
+		}
+		var value uint16
+		switch binary.BigEndian.Uint16(request.Data[2:]) {
+		case 0xFF00:
+			value = 1
+		case 0x0000:
+		default:
+			return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue), nil
+		}
+		return h.write(ctx, request, config.Coils, int(binary.BigEndian.Uint16(request.Data)), []uint16{value},
+			request,
+		), nil
+	case modbus.FuncCodeWriteSingleRegister:
+		if len(request.Data) != 4 {
+			return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue), nil
+		}
+		return h.write(ctx, request, config.HoldingRegisters, int(binary.BigEndian.Uint16(request.Data)),
+			[]uint16{binary.BigEndian.Uint16(request.Data[2:])}, request,
+		), nil
+	case modbus.FuncCodeWriteMultipleCoils:
+		return h.writeMultipleCoils(ctx, request), nil
+	case modbus.FuncCodeWriteMultipleRegisters:
+		return h.writeMultipleRegisters(ctx, request), nil
+	}
+	return modbusserver.Exception(request, modbus.ExceptionCodeIllegalFunction), nil
+}
+
+// addressQuantity returns the address and quantity at the start of the request data, or an exception code if the
+// quantity is not between 1 and maxQuantity.
+func addressQuantity(request *modbus.ProtocolDataUnit, maxQuantity int) (address int, quantity int, exception byte) {
+	if len(request.Data) < 4 {
+		return 0, 0, modbus.ExceptionCodeIllegalDataValue
+	}
+	address = int(binary.BigEndian.Uint16(request.Data))
+	quantity = int(binary.BigEndian.Uint16(request.Data[2:]))
+	if quantity < 1 || quantity > maxQuantity {
+		return 0, 0, modbus.ExceptionCodeIllegalDataValue
+	}
+	return address, quantity, 0
+}
+
+// span returns the mappings which include the addresses, in address order, or an illegal data address exception code
+// if any address is not mapped.
+func (h *Handler) span(table config.Table, address int, quantity int) ([]mapping, byte) {
+	var mappings []mapping
+	for next := address; next < address+quantity; {
+		i := slices.IndexFunc(h.mappings, func(m mapping) bool { return m.contains(table, next) })
+		if i == -1 {
+			return nil, modbus.ExceptionCodeIllegalDataAddress
+		}
+		mappings = append(mappings, h.mappings[i])
+		next = int(h.mappings[i].Address) + int(h.mappings[i].Quantity)
+	}
+	return mappings, 0
+}
+
+// read returns the values of the addresses in the request.
+func (h *Handler) read(request *modbus.ProtocolDataUnit, table config.Table, maxQuantity int) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 4 {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address, quantity, exception := addressQuantity(request, maxQuantity)
+	if exception != 0 {
+		return modbusserver.Exception(request, exception)
+	}
+	mappings, exception := h.span(table, address, quantity)
+	if exception != 0 {
+		return modbusserver.Exception(request, exception)
+	}
+	values := make([]uint16, 0, quantity)
+	for _, m := range mappings {
+		mappingValues, exception := h.values(m)
+		if exception != 0 {
+			return modbusserver.Exception(request, exception)
+		}
+		start := max(address-int(m.Address), 0)
+		end := min(address+quantity-int(m.Address), int(m.Quantity))
+		values = append(values, mappingValues[start:end]...)
+	}
+
+	if table == config.Coils || table == config.DiscreteInputs {
+		data := make([]byte, 1+(quantity+7)/8)
+		data[0] = byte(len(data) - 1)
+		for i, value := range values {
+			if value != 0 {
+				data[1+i/8] |= 1 << (i % 8)
+			}
+		}
+		return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+	}
+	data := []byte{byte(2 * quantity)}
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
+
+func (h *Handler) writeMultipleCoils(ctx context.Context, request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	address, quantity, exception := addressQuantity(request, 1968)
+	if exception != 0 {
+		return modbusserver.Exception(request, exception)
+	}
+	if len(request.Data) < 5 || int(request.Data[4]) != (quantity+7)/8 || len(request.Data) != 5+int(request.Data[4]) {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	values := make([]uint16, quantity)
+	for i := range values {
+		if request.Data[5+i/8]&(1<<(i%8)) != 0 {
+			values[i] = 1
+		}
+	}
+	response := &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: request.Data[:4]}
+	return h.write(ctx, request, config.Coils, address, values, response)
+}
+
+func (h *Handler) writeMultipleRegisters(
+	ctx context.Context,
+	request *modbus.ProtocolDataUnit,
+) *modbus.ProtocolDataUnit {
+	address, quantity, exception := addressQuantity(request, 123)
+	if exception != 0 {
+		return modbusserver.Exception(request, exception)
+	}
+	if len(request.Data) < 5 || int(request.Data[4]) != 2*quantity || len(request.Data) != 5+2*quantity {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	values := make([]uint16, quantity)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(request.Data[5+2*i:])
+	}
+	response := &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: request.Data[:4]}
+	return h.write(ctx, request, config.HoldingRegisters, address, values, response)
+}
+
+// write sends the values written to each mapping to its write endpoint, and returns the response if every endpoint
+// accepts them. A write to some of the addresses of a mapping sends every value of the mapping, using the cached
+// values for the addresses which are not written.
+func (h *Handler) write(
+	ctx context.Context,
+	request *modbus.ProtocolDataUnit,
+	table config.Table,
+	address int,
+	values []uint16,
+	response *modbus.ProtocolDataUnit,
+) *modbus.ProtocolDataUnit {
+	if address+len(values) > 65536 {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+	}
+	mappings, exception := h.span(table, address, len(values))
+	if exception != 0 {
+		return modbusserver.Exception(request, exception)
+	}
+	if slices.ContainsFunc(mappings, func(m mapping) bool { return m.WriteURL == "" }) {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+	}
+	for _, m := range mappings {
+		mappingValues := make([]uint16, m.Quantity)
+		start := max(address-int(m.Address), 0)
+		end := min(address+len(values)-int(m.Address), int(m.Quantity))
+		if start > 0 || end < int(m.Quantity) {
+			var exception byte
+			if mappingValues, exception = h.values(m); exception != 0 {
+				return modbusserver.Exception(request, exception)
+			}
+		}
+		copy(mappingValues[start:end], values[int(m.Address)+start-address:])
+		if err := h.post(ctx, m, mappingValues); err != nil {
+			h.logger.Warn("error writing endpoint", slog.String("url", m.WriteURL), slog.String("error", err.Error()))
+			return modbusserver.Exception(request, modbus.ExceptionCodeServerDeviceFailure)
+		}
+		// Read the endpoint again so that reads which follow the write return the values written.
+		if err := h.fetch(ctx, m.URL); err != nil {
+			h.logger.Warn("error refreshing endpoint", slog.String("url", m.URL), slog.String("error", err.Error()))
+		}
+	}
+	return response
+}
+
+// writeBody is the JSON body posted to a write endpoint.
+type writeBody struct {
+	// Address is the first address of the mapping
+	Address uint16 `json:"address"`
+	// Values are the values of the mapping, which are booleans for coils and numbers divided by the scale for holding
+	// registers
+	Values []any `json:"values"`
+}
+
+// post sends the values of the mapping to its write endpoint.
+func (h *Handler) post(ctx context.Context, m mapping, values []uint16) error {
+	body := writeBody{Address: m.Address, Values: make([]any, len(values))}
+	for i, value := range values {
+		switch {
+		case m.Table == config.Coils:
+			body.Values[i] = value != 0
+		case m.Signed:
+			body.Values[i] = float64(int16(value)) / m.Scale
+		default:
+			body.Values[i] = float64(value) / m.Scale
+		}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.requestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, m.WriteURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	_, err = h.do(request)
+	return err
+}
@@ -0,0 +1,206 @@
+package httpslave
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+// backend is an HTTP service holding the state of a plant, which accepts writes to its setpoints.
+type backend struct {
+	mu     sync.Mutex
+	state  string
+	writes []writeBody
+	fail   bool
+}
+
+func (b *backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if r.Method == http.MethodPost {
+		var body writeBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b.writes = append(b.writes, body)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	_, _ = w.Write([]byte(b.state))
+}
+
+// startSlave serves a Handler for the mappings until the end of the test, and returns a client connected to it.
+func startSlave(t *testing.T, b *backend, mappings ...config.SlaveMapping) (modbus.Client, *Handler) {
+	t.Helper()
+	httpServer := httptest.NewServer(b)
+	for i := range mappings {
+		mappings[i].URL = httpServer.URL + mappings[i].URL
+		if mappings[i].WriteURL != "" {
+			mappings[i].WriteURL = httpServer.URL + mappings[i].WriteURL
+		}
+	}
+	handler, err := New(&config.Slave{Mappings: mappings, MaxAge: time.Minute}, httpServer.Client(),
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handler.Refresh(context.Background())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := modbusserver.NewServer(handler)
+	go func() { _ = server.Serve(listener) }()
+	clientHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+	clientHandler.Timeout = time.Second
+	t.Cleanup(func() {
+		_ = clientHandler.Close()
+		_ = server.Close()
+		httpServer.Close()
+	})
+	return modbus.NewClient(clientHandler), handler
+}
+
+func TestHandler_Read(t *testing.T) {
+	b := &backend{state: `{"temperatures":[21.5,-3.25],"pressure":1013,"pump":{"running":true}}`}
+	client, _ := startSlave(t, b,
+		config.SlaveMapping{Table: config.InputRegisters, Address: 0, Quantity: 2, URL: "/plant",
+			Path: "$.temperatures", Scale: 10, Signed: true},
+		config.SlaveMapping{Table: config.InputRegisters, Address: 2, URL: "/plant", Path: "$.pressure"},
+		config.SlaveMapping{Table: config.DiscreteInputs, Address: 4, URL: "/plant", Path: "$.pump.running"},
+	)
+
+	got, err := client.ReadInputRegisters(0, 3)
+	if err != nil {
+		t.Fatalf("ReadInputRegisters() error = %v", err)
+	}
+	// 215, -33 as two's complement, and 1013
+	if want := []byte{0x00, 0xD7, 0xFF, 0xDF, 0x03, 0xF5}; !slices.Equal(got, want) {
+		t.Errorf("ReadInputRegisters() = %v, want %v", got, want)
+	}
+	if got, err := client.ReadInputRegisters(1, 1); err != nil || !slices.Equal(got, []byte{0xFF, 0xDF}) {
+		t.Errorf("ReadInputRegisters() within a mapping = %v, %v, want [255 223], nil", got, err)
+	}
+	if got, err := client.ReadDiscreteInputs(4, 1); err != nil || !slices.Equal(got, []byte{1}) {
+		t.Errorf("ReadDiscreteInputs() = %v, %v, want [1], nil", got, err)
+	}
+	if _, err := client.ReadInputRegisters(2, 2); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("ReadInputRegisters() past the mappings error = %v, want illegal data address", err)
+	}
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("ReadCoils() error = %v, want illegal data address", err)
+	}
+}
+
+func TestHandler_Write(t *testing.T) {
+	b := &backend{state: `{"setpoints":[10,20],"enabled":false}`}
+	client, _ := startSlave(t, b,
+		config.SlaveMapping{Table: config.HoldingRegisters, Address: 100, Quantity: 2, URL: "/plant",
+			Path: "$.setpoints", Scale: 2, WriteURL: "/setpoints"},
+		config.SlaveMapping{Table: config.Coils, Address: 0, URL: "/plant", Path: "$.enabled",
+			WriteURL: "/enabled"},
+		config.SlaveMapping{Table: config.HoldingRegisters, Address: 200, URL: "/plant", Path: "$.setpoints[0]"},
+	)
+
+	if _, err := client.WriteSingleRegister(101, 50); err != nil {
+		t.Fatalf("WriteSingleRegister() error = %v", err)
+	}
+	if _, err := client.WriteSingleCoil(0, 0xFF00); err != nil {
+		t.Fatalf("WriteSingleCoil() error = %v", err)
+	}
+	want := []writeBody{
+		{Address: 100, Values: []any{float64(10), float64(25)}},
+		{Address: 0, Values: []any{true}},
+	}
+	b.mu.Lock()
+	got := b.writes
+	b.mu.Unlock()
+	if len(got) != len(want) {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Address != want[i].Address || !slices.Equal(got[i].Values, want[i].Values) {
+			t.Errorf("writes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := client.WriteSingleRegister(200, 1); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("WriteSingleRegister() to a read only mapping error = %v, want illegal data address", err)
+	}
+	b.mu.Lock()
+	b.fail = true
+	b.mu.Unlock()
+	if _, err := client.WriteMultipleRegisters(100, 2, []byte{0, 1, 0, 2}); !isException(err,
+		modbus.ExceptionCodeServerDeviceFailure,
+	) {
+		t.Errorf("WriteMultipleRegisters() to a failing endpoint error = %v, want server device failure", err)
+	}
+}
+
+func TestHandler_Stale(t *testing.T) {
+	b := &backend{state: `{"value":1}`}
+	client, handler := startSlave(t, b,
+		config.SlaveMapping{Table: config.HoldingRegisters, Address: 0, URL: "/plant", Path: "$.value"},
+	)
+	handler.maxAge = 0
+	if _, err := client.ReadHoldingRegisters(0, 1); !isException(err, modbus.ExceptionCodeServerDeviceFailure) {
+		t.Errorf("ReadHoldingRegisters() of a stale value error = %v, want server device failure", err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []config.SlaveMapping
+	}{
+		{name: "Unknown table", mappings: []config.SlaveMapping{{Table: "registers", URL: "/"}}},
+		{name: "No URL", mappings: []config.SlaveMapping{{Table: config.Coils}}},
+		{name: "Invalid path", mappings: []config.SlaveMapping{{Table: config.Coils, URL: "/", Path: "$["}}},
+		{
+			name:     "Writable input",
+			mappings: []config.SlaveMapping{{Table: config.InputRegisters, URL: "/", WriteURL: "/"}},
+		},
+		{
+			name:     "Past the end",
+			mappings: []config.SlaveMapping{{Table: config.Coils, Address: 65535, Quantity: 2, URL: "/"}},
+		},
+		{
+			name: "Overlapping",
+			mappings: []config.SlaveMapping{
+				{Table: config.Coils, Address: 0, Quantity: 2, URL: "/"},
+				{Table: config.Coils, Address: 1, URL: "/"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&config.Slave{Mappings: tt.mappings}, http.DefaultClient, slog.Default()); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+func isException(err error, exceptionCode byte) bool {
+	var modbusErr *modbus.ModbusError
+	return errors.As(err, &modbusErr) && modbusErr.ExceptionCode == exceptionCode
+}
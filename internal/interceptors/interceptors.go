@@ -2,10 +2,22 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"modbustohttp/internal/auth"
+	"modbustohttp/internal/authz"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/ratelimit"
+	"modbustohttp/pkg/config"
+	"net"
+	"strconv"
+	"time"
 
 	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // NewLoggingInterceptor returns a Connect interceptor that logs the details of each request and response.
@@ -38,3 +50,118 @@ func NewLoggingInterceptor(logger *slog.Logger) connect.UnaryInterceptorFunc {
 		}
 	}
 }
+
+// NewMetricsInterceptor returns a Connect interceptor that records the number and latency of calls to each procedure.
+// Calls are counted by the resulting status code, with successful calls recorded as "ok".
+func NewMetricsInterceptor(m *metrics.Metrics) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			start := time.Now()
+			response, err := next(ctx, request)
+			code := "ok"
+			if err != nil {
+				code = connect.CodeOf(err).String()
+			}
+			m.RPCRequests.WithLabelValues(request.Spec().Procedure, code).Inc()
+			m.RPCDuration.WithLabelValues(request.Spec().Procedure).Observe(time.Since(start).Seconds())
+			return response, err
+		}
+	}
+}
+
+// NewAuthInterceptor returns a Connect interceptor that authenticates each request using the given authenticator and
+// attaches the resulting principal to the request context. Requests which already carry a principal, for example one
+// established from a client certificate, are passed through unchanged.
+func NewAuthInterceptor(authenticator *auth.Authenticator) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			if _, ok := auth.FromContext(ctx); ok {
+				return next(ctx, request)
+			}
+			principal, err := authenticator.Authenticate(request.Header())
+			if err != nil {
+				connectErr := connect.NewError(connect.CodeUnauthenticated, err)
+				connectErr.Meta().Set("WWW-Authenticate", "Bearer")
+				return nil, connectErr
+			}
+			return next(auth.NewContext(ctx, principal), request)
+		}
+	}
+}
+
+// NewAuthorizationInterceptor returns a Connect interceptor that checks each request is allowed by the roles granted to
+// the principal attached to the request context. Denied requests fail with the PermissionDenied code, and include the
+// first address which is not allowed as error details. The given slave ID is the device all requests are made to.
+func NewAuthorizationInterceptor(policy *authz.Policy, slaveID byte) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			principal, ok := auth.FromContext(ctx)
+			if !ok {
+				return nil, connect.NewError(connect.CodeUnauthenticated, auth.ErrNoCredentials)
+			}
+			access := authz.RequestAccess(request.Spec().Procedure, slaveID, request.Any())
+			err := policy.Authorize(principal.Name, access)
+			var deniedErr *authz.DeniedError
+			if errors.As(err, &deniedErr) {
+				connectErr := connect.NewError(connect.CodePermissionDenied, err)
+				info := &errdetails.ErrorInfo{
+					Reason: "PERMISSION_DENIED",
+					Domain: "modbustohttp",
+					Metadata: map[string]string{
+						"principal": deniedErr.Principal,
+						"procedure": deniedErr.Procedure,
+					},
+				}
+				if deniedErr.Table != "" {
+					info.Reason = "ADDRESS_NOT_PERMITTED"
+					info.Metadata["table"] = string(deniedErr.Table)
+					info.Metadata["address"] = strconv.FormatUint(uint64(deniedErr.Address), 10)
+				}
+				if detail, detailErr := connect.NewErrorDetail(info); detailErr == nil {
+					connectErr.AddDetail(detail)
+				}
+				return nil, connectErr
+			}
+			if err != nil {
+				return nil, connect.NewError(connect.CodeInternal, err)
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// NewRateLimitInterceptor returns a Connect interceptor that limits the rate of the requests of each client, by the
+// principal attached to the request context or by IP address. Requests over the limit queue for their turn, and fail
+// with the ResourceExhausted code if it would not come in time, with the time to retry after as the Retry-After header
+// and as error details.
+func NewRateLimitInterceptor(limiters *ratelimit.Keyed, key config.RateLimitKey) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			// The keys of principals and IP addresses are prefixed so that a principal cannot be named after an address.
+			clientKey := "ip:" + request.Peer().Addr
+			if host, _, err := net.SplitHostPort(request.Peer().Addr); err == nil {
+				clientKey = "ip:" + host
+			}
+			if principal, ok := auth.FromContext(ctx); ok && key == config.RateLimitByPrincipal {
+				clientKey = "principal:" + principal.Name
+			}
+			err := limiters.Wait(ctx, clientKey)
+			var exceededErr *ratelimit.ExceededError
+			if errors.As(err, &exceededErr) {
+				connectErr := connect.NewError(connect.CodeResourceExhausted, err)
+				// Retry-After is in whole seconds, so it is rounded up to avoid clients retrying too soon.
+				seconds := max(int64(math.Ceil(exceededErr.RetryAfter.Seconds())), 1)
+				connectErr.Meta().Set("Retry-After", strconv.FormatInt(seconds, 10))
+				retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(exceededErr.RetryAfter)}
+				if detail, detailErr := connect.NewErrorDetail(retryInfo); detailErr == nil {
+					connectErr.AddDetail(detail)
+				}
+				return nil, connectErr
+			}
+			if err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"modbustohttp/internal/auth"
+	"modbustohttp/internal/authz"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/ratelimit"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"modbustohttp/service/modbustohttp/v1alpha1/v1alpha1connect"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+// principalHeader is the header the test certificate middleware reads the principal from, standing in for the subject
+// of a verified client certificate.
+const principalHeader = "X-Test-Certificate-Subject"
+
+// recordingService is a modbus service which records the principal of the last request it handled.
+type recordingService struct {
+	v1alpha1connect.UnimplementedModbusServiceHandler
+
+	mu        sync.Mutex
+	principal string
+}
+
+func (s *recordingService) ReadCoils(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.ReadCoilsRequest],
+) (*connect.Response[modbusv1alpha1.ReadCoilsResponse], error) {
+	s.record(ctx)
+	return connect.NewResponse(&modbusv1alpha1.ReadCoilsResponse{}), nil
+}
+
+func (s *recordingService) ReadHoldingRegisters(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.ReadHoldingRegistersRequest],
+) (*connect.Response[modbusv1alpha1.ReadHoldingRegistersResponse], error) {
+	s.record(ctx)
+	return connect.NewResponse(&modbusv1alpha1.ReadHoldingRegistersResponse{}), nil
+}
+
+func (s *recordingService) record(ctx context.Context) {
+	principal, _ := auth.FromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.principal = principal.Name
+}
+
+func (s *recordingService) lastPrincipal() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.principal
+}
+
+// newTestService serves a recordingService with the given interceptors until the end of the test, and returns a client
+// for it and the service. Requests with the principalHeader carry its value as their principal before they reach the
+// interceptors, as requests with a verified client certificate do.
+func newTestService(
+	t *testing.T,
+	interceptors ...connect.Interceptor,
+) (v1alpha1connect.ModbusServiceClient, *recordingService) {
+	t.Helper()
+	service := &recordingService{}
+	mux := http.NewServeMux()
+	mux.Handle(v1alpha1connect.NewModbusServiceHandler(service, connect.WithInterceptors(interceptors...)))
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subject := r.Header.Get(principalHeader); subject != "" {
+			r = r.WithContext(auth.NewContext(r.Context(), auth.Principal{Name: subject}))
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	return v1alpha1connect.NewModbusServiceClient(httpServer.Client(), httpServer.URL), service
+}
+
+// newAuthenticator returns an Authenticator accepting the key "alice-key" for alice and "bob-key" for bob.
+func newAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	hash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	authenticator, err := auth.NewAuthenticator(&config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Name: "alice", Hash: hash("alice-key")},
+			{Name: "bob", Hash: hash("bob-key")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	return authenticator
+}
+
+// readHoldingRegisters returns a request to read one holding register with the given headers set.
+func readHoldingRegisters(
+	address uint32,
+	header map[string]string,
+) *connect.Request[modbusv1alpha1.ReadHoldingRegistersRequest] {
+	request := connect.NewRequest(&modbusv1alpha1.ReadHoldingRegistersRequest{
+		Address:  address,
+		Quantity: proto.Uint32(1),
+	})
+	for key, value := range header {
+		request.Header().Set(key, value)
+	}
+	return request
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client, service := newTestService(t, NewAuthInterceptor(newAuthenticator(t)))
+
+	tests := []struct {
+		name          string
+		header        map[string]string
+		wantPrincipal string
+	}{
+		{name: "API key", header: map[string]string{auth.APIKeyHeader: "alice-key"}, wantPrincipal: "alice"},
+		{
+			name:          "Certificate principal",
+			header:        map[string]string{principalHeader: "plc-gateway"},
+			wantPrincipal: "plc-gateway",
+		},
+		{name: "Missing credentials", header: nil},
+		{name: "Invalid API key", header: map[string]string{auth.APIKeyHeader: "mallory-key"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(0, tt.header))
+			if tt.wantPrincipal == "" {
+				var connectErr *connect.Error
+				if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeUnauthenticated {
+					t.Fatalf("ReadHoldingRegisters() error = %v, want code %v", err, connect.CodeUnauthenticated)
+				}
+				if got := connectErr.Meta().Get("WWW-Authenticate"); got != "Bearer" {
+					t.Errorf("WWW-Authenticate = %q, want %q", got, "Bearer")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadHoldingRegisters() error = %v", err)
+			}
+			if got := service.lastPrincipal(); got != tt.wantPrincipal {
+				t.Errorf("principal = %q, want %q", got, tt.wantPrincipal)
+			}
+		})
+	}
+}
+
+func TestAuthorizationInterceptor(t *testing.T) {
+	policy, err := authz.NewPolicy(&config.Authorization{
+		Enabled: true,
+		Roles: []config.Role{
+			{
+				Name:          "dashboard",
+				Procedures:    []string{"ReadHoldingRegisters"},
+				AddressRanges: []config.AddressRange{{Table: config.HoldingRegisters, Start: 0, End: 9}},
+			},
+		},
+		Grants: []config.Grant{{Principal: "grafana", Roles: []string{"dashboard"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	client, _ := newTestService(t, NewAuthorizationInterceptor(policy, 1))
+	grafana := map[string]string{principalHeader: "grafana"}
+
+	tests := []struct {
+		name         string
+		call         func() error
+		wantCode     connect.Code
+		wantReason   string
+		wantMetadata map[string]string
+	}{
+		{
+			name: "Allowed",
+			call: func() error {
+				_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(5, grafana))
+				return err
+			},
+		},
+		{
+			name: "Procedure not permitted",
+			call: func() error {
+				request := connect.NewRequest(&modbusv1alpha1.ReadCoilsRequest{Quantity: 1})
+				request.Header().Set(principalHeader, "grafana")
+				_, err := client.ReadCoils(context.Background(), request)
+				return err
+			},
+			wantCode:   connect.CodePermissionDenied,
+			wantReason: "PERMISSION_DENIED",
+			wantMetadata: map[string]string{
+				"principal": "grafana",
+				"procedure": "ReadCoils",
+			},
+		},
+		{
+			name: "Address not permitted",
+			call: func() error {
+				_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(10, grafana))
+				return err
+			},
+			wantCode:   connect.CodePermissionDenied,
+			wantReason: "ADDRESS_NOT_PERMITTED",
+			wantMetadata: map[string]string{
+				"principal": "grafana",
+				"procedure": "ReadHoldingRegisters",
+				"table":     string(config.HoldingRegisters),
+				"address":   "10",
+			},
+		},
+		{
+			name: "No principal",
+			call: func() error {
+				_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(5, nil))
+				return err
+			},
+			wantCode: connect.CodeUnauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("call error = %v", err)
+				}
+				return
+			}
+			var connectErr *connect.Error
+			if !errors.As(err, &connectErr) || connectErr.Code() != tt.wantCode {
+				t.Fatalf("call error = %v, want code %v", err, tt.wantCode)
+			}
+			if tt.wantReason == "" {
+				return
+			}
+			info := errorDetail[*errdetails.ErrorInfo](t, connectErr)
+			if info.GetReason() != tt.wantReason || info.GetDomain() != "modbustohttp" {
+				t.Errorf("ErrorInfo = %v, want reason %q in domain modbustohttp", info, tt.wantReason)
+			}
+			for key, want := range tt.wantMetadata {
+				if got := info.GetMetadata()[key]; got != want {
+					t.Errorf("ErrorInfo metadata %q = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	limiters := ratelimit.NewKeyed(&config.RateLimit{Rate: 0.5, Burst: 1})
+	client, _ := newTestService(t, NewRateLimitInterceptor(limiters, config.RateLimitByIP))
+
+	if _, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(0, nil)); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(0, nil))
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeResourceExhausted {
+		t.Fatalf("ReadHoldingRegisters() error = %v, want code %v", err, connect.CodeResourceExhausted)
+	}
+	// A token is added every 2 seconds, so the next comes in just under 2 seconds, which is rounded up.
+	if got := connectErr.Meta().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	retryInfo := errorDetail[*errdetails.RetryInfo](t, connectErr)
+	if delay := retryInfo.GetRetryDelay().AsDuration(); delay <= time.Second || delay > 2*time.Second {
+		t.Errorf("RetryInfo.RetryDelay = %v, want between 1s and 2s", delay)
+	}
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	client, _ := newTestService(t, NewMetricsInterceptor(m))
+
+	if _, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(0, nil)); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	_, err := client.WriteSingleCoil(context.Background(), connect.NewRequest(&modbusv1alpha1.WriteSingleCoilRequest{}))
+	if connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Fatalf("WriteSingleCoil() error = %v, want code %v", err, connect.CodeUnimplemented)
+	}
+
+	tests := []struct {
+		procedure string
+		code      string
+	}{
+		{procedure: v1alpha1connect.ModbusServiceReadHoldingRegistersProcedure, code: "ok"},
+		{procedure: v1alpha1connect.ModbusServiceWriteSingleCoilProcedure, code: connect.CodeUnimplemented.String()},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(m.RPCRequests.WithLabelValues(tt.procedure, tt.code)); got != 1 {
+			t.Errorf("RPCRequests{%s, %s} = %v, want 1", tt.procedure, tt.code, got)
+		}
+	}
+	if got := testutil.CollectAndCount(m.RPCDuration); got != 2 {
+		t.Errorf("RPCDuration has %d series, want 2", got)
+	}
+}
+
+// TestInterceptors_Order checks the interceptors work together in the order they are chained by the server: metrics,
+// then authentication, then rate limiting, then authorization.
+func TestInterceptors_Order(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	policy, err := authz.NewPolicy(&config.Authorization{
+		Enabled: true,
+		Roles:   []config.Role{{Name: "dashboard", Procedures: []string{"ReadHoldingRegisters"}}},
+		Grants:  []config.Grant{{Principal: "alice", Roles: []string{"dashboard"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	client, service := newTestService(t,
+		NewMetricsInterceptor(m),
+		NewAuthInterceptor(newAuthenticator(t)),
+		NewRateLimitInterceptor(ratelimit.NewKeyed(&config.RateLimit{Rate: 0.5, Burst: 1}), config.RateLimitByPrincipal),
+		NewAuthorizationInterceptor(policy, 1),
+	)
+	alice := map[string]string{auth.APIKeyHeader: "alice-key"}
+	bob := map[string]string{auth.APIKeyHeader: "bob-key"}
+
+	calls := []struct {
+		name     string
+		header   map[string]string
+		wantCode connect.Code
+	}{
+		// Requests which are not authenticated are rejected before taking a token, and are still counted.
+		{name: "Unauthenticated", header: nil, wantCode: connect.CodeUnauthenticated},
+		// The principal established by authentication is what is authorized.
+		{name: "Alice", header: alice},
+		// The principal established by authentication is what is limited, so alice's next request is over the limit.
+		{name: "Alice over the limit", header: alice, wantCode: connect.CodeResourceExhausted},
+		// Bob has a separate token. Requests are limited before they are authorized, so a denied request takes a token.
+		{name: "Bob denied", header: bob, wantCode: connect.CodePermissionDenied},
+		{name: "Bob over the limit", header: bob, wantCode: connect.CodeResourceExhausted},
+	}
+	for _, call := range calls {
+		_, err := client.ReadHoldingRegisters(context.Background(), readHoldingRegisters(0, call.header))
+		if (call.wantCode == 0 && err != nil) || (call.wantCode != 0 && connect.CodeOf(err) != call.wantCode) {
+			t.Fatalf("%s: ReadHoldingRegisters() error = %v, want code %v", call.name, err, call.wantCode)
+		}
+	}
+	if got := service.lastPrincipal(); got != "alice" {
+		t.Errorf("principal = %q, want %q", got, "alice")
+	}
+
+	procedure := v1alpha1connect.ModbusServiceReadHoldingRegistersProcedure
+	for code, want := range map[string]float64{
+		"ok":                                   1,
+		connect.CodeUnauthenticated.String():   1,
+		connect.CodeResourceExhausted.String(): 2,
+		connect.CodePermissionDenied.String():  1,
+	} {
+		if got := testutil.ToFloat64(m.RPCRequests.WithLabelValues(procedure, code)); got != want {
+			t.Errorf("RPCRequests{%s} = %v, want %v", code, got, want)
+		}
+	}
+}
+
+// errorDetail returns the first detail of type T in the error, failing the test if there is none.
+func errorDetail[T proto.Message](t *testing.T, connectErr *connect.Error) T {
+	t.Helper()
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if err != nil {
+			t.Fatalf("Value() error = %v", err)
+		}
+		if detail, ok := value.(T); ok {
+			return detail
+		}
+	}
+	var zero T
+	t.Fatalf("error %v has no %T detail", connectErr, zero)
+	return zero
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "modbustohttp"
+
+// Metrics holds the Prometheus collectors describing the internals of the bridge.
+type Metrics struct {
+	// RPCRequests counts the Connect RPCs handled, labelled by procedure and resulting status code.
+	RPCRequests *prometheus.CounterVec
+	// RPCDuration observes the latency of Connect RPCs, labelled by procedure.
+	RPCDuration *prometheus.HistogramVec
+	// ModbusRequests counts the Modbus transactions sent, labelled by function code and result.
+	ModbusRequests *prometheus.CounterVec
+	// ModbusDuration observes the latency of Modbus transactions, labelled by function code.
+	ModbusDuration *prometheus.HistogramVec
+	// ModbusExceptions counts the Modbus exception responses received, labelled by function code and exception code.
+	ModbusExceptions *prometheus.CounterVec
+	// ModbusConnected is 1 while the connection to the Modbus server is established and 0 otherwise.
+	ModbusConnected prometheus.Gauge
+	// ModbusReconnects counts the number of times the connection to the Modbus server was re-established.
+	ModbusReconnects prometheus.Counter
+	// ModbusQueueDepth is the number of Modbus transactions waiting for or using the connection.
+	ModbusQueueDepth prometheus.Gauge
+	// ModbusCircuitBreakerState is the state of the circuit breaker of the connection to the Modbus server: 0 while
+	// closed, 1 while open and 2 while half-open.
+	ModbusCircuitBreakerState prometheus.Gauge
+}
+
+// New creates the application metrics and registers them with the given registerer.
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		RPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "requests_total",
+			Help:      "Total number of RPCs handled, by procedure and status code.",
+		}, []string{"procedure", "code"}),
+		RPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "rpc",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of RPCs handled, by procedure.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"procedure"}),
+		ModbusRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "requests_total",
+			Help:      "Total number of Modbus transactions, by function code and result.",
+		}, []string{"function_code", "result"}),
+		ModbusDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of Modbus transactions, by function code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"function_code"}),
+		ModbusExceptions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "exceptions_total",
+			Help:      "Total number of Modbus exception responses, by function code and exception code.",
+		}, []string{"function_code", "exception_code"}),
+		ModbusConnected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "connected",
+			Help:      "Whether the connection to the Modbus server is established (1) or not (0).",
+		}),
+		ModbusReconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "reconnects_total",
+			Help:      "Total number of times the connection to the Modbus server was re-established.",
+		}),
+		ModbusQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "queue_depth",
+			Help:      "Number of Modbus transactions waiting for or using the connection.",
+		}),
+		ModbusCircuitBreakerState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "modbus",
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker of the connection to the Modbus server (0 closed, 1 open, 2 half-open).",
+		}),
+	}
+	registerer.MustRegister(
+		m.RPCRequests,
+		m.RPCDuration,
+		m.ModbusRequests,
+		m.ModbusDuration,
+		m.ModbusExceptions,
+		m.ModbusConnected,
+		m.ModbusReconnects,
+		m.ModbusQueueDepth,
+		m.ModbusCircuitBreakerState,
+	)
+	return m
+}
@@ -0,0 +1,375 @@
+// Package modbuspool sends the transactions of the modbus service over a pool of connections to the modbus server.
+package modbuspool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"modbustohttp/pkg/config"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+var (
+	// ErrTimeout is returned by Send if no connection became available within the connection timeout.
+	ErrTimeout = errors.New("modbuspool: timed out waiting for a connection")
+	// ErrNotSent is wrapped by the errors Send returns if the request was never sent, because no connection could be
+	// opened or became available in time, so it is safe to retry even if it writes.
+	ErrNotSent = errors.New("modbuspool: request not sent")
+)
+
+const (
+	// defaultIdleTimeout and defaultHealthCheckInterval are used if neither is configured, such as in a config file
+	// which leaves them out, as the pool would otherwise never close idle connections, check them or reopen the
+	// minimum. They are the defaults of the environment variables.
+	defaultIdleTimeout         = 60 * time.Second
+	defaultHealthCheckInterval = 30 * time.Second
+)
+
+// healthCheckRequest reads holding register 0, which almost every device supports. Any response shows the connection
+// is working, even an exception.
+var healthCheckRequest = &modbus.ProtocolDataUnit{
+	FunctionCode: modbus.FuncCodeReadHoldingRegisters,
+	Data:         []byte{0, 0, 0, 1},
+}
+
+// conn is a connection in the pool.
+type conn struct {
+	handler *modbus.TCPClientHandler
+	// generation is the generation of the pool when the connection was opened. Connections from earlier generations
+	// are closed when they are released.
+	generation int
+	// lastUsed is when the connection was last used to send a transaction
+	lastUsed time.Time
+	// lastChecked is when the health of the connection was last checked
+	lastChecked time.Time
+}
+
+// acquired is a connection handed to a request waiting for one, or the error opening it.
+type acquired struct {
+	conn *conn
+	err  error
+}
+
+// Pool is a modbus.ClientHandler which sends each transaction over a connection to the modbus server which is not in
+// use, opening connections up to the maximum. Requests waiting for a connection are served in the order they were
+// made. Transaction IDs are assigned by the pool, so they are unique across its connections.
+type Pool struct {
+	address             string
+	slaveID             byte
+	timeout             time.Duration
+	minConnections      int
+	maxConnections      int
+	idleTimeout         time.Duration
+	healthCheckInterval time.Duration
+	packager            modbus.Packager
+	logger              *slog.Logger
+
+	mu sync.Mutex
+	// idle are the open connections which are not in use, the most recently used last
+	idle []*conn
+	// open is the number of connections which are open or being opened
+	open int
+	// waiters are the requests waiting for a connection, in the order they were made
+	waiters    []chan acquired
+	generation int
+}
+
+// New returns a Pool of connections to the modbus server. No connections are opened until Connect or Send is called,
+// or Run keeps the minimum open. If neither the idle timeout nor the health check interval is set, the defaults are
+// used for both.
+func New(modbusConfig *config.Modbus, logger *slog.Logger) (*Pool, error) {
+	poolConfig := modbusConfig.Pool
+	maxConnections := poolConfig.MaxConnections
+	if maxConnections == 0 {
+		maxConnections = 1
+	}
+	if poolConfig.MinConnections < 0 || maxConnections < 0 || poolConfig.MinConnections > maxConnections {
+		return nil, fmt.Errorf("invalid connection pool size of %d to %d connections",
+			poolConfig.MinConnections, maxConnections,
+		)
+	}
+	if poolConfig.IdleTimeout == 0 && poolConfig.HealthCheckInterval == 0 {
+		poolConfig.IdleTimeout = defaultIdleTimeout
+		poolConfig.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	address := fmt.Sprintf("%s:%d", modbusConfig.Host, modbusConfig.Port)
+	// The packager of a handler which is never connected encodes every request, so that transaction IDs are assigned
+	// in one sequence.
+	packager := modbus.NewTCPClientHandler(address)
+	packager.SlaveId = modbusConfig.SlaveID
+	return &Pool{
+		address:             address,
+		slaveID:             modbusConfig.SlaveID,
+		timeout:             modbusConfig.ConnectionTimeout,
+		minConnections:      poolConfig.MinConnections,
+		maxConnections:      maxConnections,
+		idleTimeout:         poolConfig.IdleTimeout,
+		healthCheckInterval: poolConfig.HealthCheckInterval,
+		packager:            packager,
+		logger:              logger,
+	}, nil
+}
+
+// Encode encodes the request PDU in an ADU with the next transaction ID.
+func (p *Pool) Encode(pdu *modbus.ProtocolDataUnit) ([]byte, error) {
+	return p.packager.Encode(pdu)
+}
+
+// Decode decodes the PDU of an ADU.
+func (p *Pool) Decode(adu []byte) (*modbus.ProtocolDataUnit, error) {
+	return p.packager.Decode(adu)
+}
+
+// Verify verifies that the response ADU matches the request ADU.
+func (p *Pool) Verify(aduRequest []byte, aduResponse []byte) error {
+	return p.packager.Verify(aduRequest, aduResponse)
+}
+
+// Send sends the request ADU over a connection which is not in use, and returns the response ADU. If the transaction
+// fails, the connection is closed, as it may be broken or have a late response pending.
+func (p *Pool) Send(aduRequest []byte) ([]byte, error) {
+	c, err := p.acquire()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotSent, err)
+	}
+	aduResponse, err := c.handler.Send(aduRequest)
+	c.lastUsed = time.Now()
+	p.release(c, err != nil)
+	return aduResponse, err
+}
+
+// ClosesFailedConnections returns true, as Send closes the connection a transaction failed on, so the pool need not be
+// closed to discard it.
+func (p *Pool) ClosesFailedConnections() bool {
+	return true
+}
+
+// Connect opens a connection if none are open, and returns an error if it cannot be opened.
+func (p *Pool) Connect() error {
+	p.mu.Lock()
+	if p.open > 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	p.open++
+	p.mu.Unlock()
+	c, err := p.dial()
+	if err != nil {
+		p.mu.Lock()
+		p.open--
+		p.replace()
+		p.mu.Unlock()
+		return err
+	}
+	p.release(c, false)
+	return nil
+}
+
+// Close closes the open connections. Connections which are in use are closed once their transaction completes. The
+// pool can still be used, and opens new connections when they are needed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generation++
+	var errs []error
+	for _, c := range p.idle {
+		errs = append(errs, c.handler.Close())
+	}
+	p.open -= len(p.idle)
+	p.idle = nil
+	return errors.Join(errs...)
+}
+
+// Run closes idle connections, checks their health and opens the minimum number of connections, until the context
+// is cancelled.
+func (p *Pool) Run(ctx context.Context) {
+	interval := p.healthCheckInterval
+	if interval == 0 || (p.idleTimeout != 0 && p.idleTimeout < interval) {
+		interval = p.idleTimeout
+	}
+	p.maintain()
+	if interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.maintain()
+		}
+	}
+}
+
+// maintain closes the connections which have been idle for longer than the idle timeout, checks the health of the
+// other idle connections, and opens connections until the minimum are open.
+func (p *Pool) maintain() {
+	now := time.Now()
+	var check []*conn
+	p.mu.Lock()
+	keep := p.idle[:0]
+	for _, c := range p.idle {
+		switch {
+		case p.idleTimeout != 0 && now.Sub(c.lastUsed) >= p.idleTimeout && p.open > p.minConnections:
+			_ = c.handler.Close()
+			p.open--
+		case p.healthCheckInterval != 0 && now.Sub(c.lastUsed) >= p.healthCheckInterval &&
+			now.Sub(c.lastChecked) >= p.healthCheckInterval:
+			check = append(check, c)
+		default:
+			keep = append(keep, c)
+		}
+	}
+	p.idle = keep
+	missing := max(p.minConnections-p.open, 0)
+	p.open += missing
+	p.mu.Unlock()
+
+	for _, c := range check {
+		err := p.checkHealth(c)
+		if err != nil {
+			p.logger.Warn("closing unhealthy modbus connection", slog.String("error", err.Error()))
+		}
+		c.lastChecked = time.Now()
+		p.release(c, err != nil)
+	}
+	for range missing {
+		c, err := p.dial()
+		if err != nil {
+			p.logger.Warn("error opening modbus connection", slog.String("error", err.Error()))
+			p.mu.Lock()
+			p.open--
+			p.replace()
+			p.mu.Unlock()
+			continue
+		}
+		p.release(c, false)
+	}
+}
+
+// checkHealth sends the health check request over the connection.
+func (p *Pool) checkHealth(c *conn) error {
+	aduRequest, err := p.Encode(healthCheckRequest)
+	if err != nil {
+		return err
+	}
+	aduResponse, err := c.handler.Send(aduRequest)
+	if err != nil {
+		return err
+	}
+	return p.Verify(aduRequest, aduResponse)
+}
+
+// dial opens a new connection.
+func (p *Pool) dial() (*conn, error) {
+	p.mu.Lock()
+	generation := p.generation
+	p.mu.Unlock()
+	handler := modbus.NewTCPClientHandler(p.address)
+	handler.Timeout = p.timeout
+	handler.SlaveId = p.slaveID
+	// The pool closes idle connections itself, so that it can keep the minimum open.
+	handler.IdleTimeout = 0
+	if err := handler.Connect(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &conn{handler: handler, generation: generation, lastUsed: now, lastChecked: now}, nil
+}
+
+// acquire returns a connection which is not in use, opening one if fewer than the maximum are open, or waiting for one
+// to be released otherwise.
+func (p *Pool) acquire() (*conn, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		// The most recently used connection is reused, so that the others become idle and can be closed.
+		c := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return c, nil
+	}
+	if p.open < p.maxConnections {
+		p.open++
+		p.mu.Unlock()
+		c, err := p.dial()
+		if err != nil {
+			p.mu.Lock()
+			p.open--
+			p.replace()
+			p.mu.Unlock()
+		}
+		return c, err
+	}
+	wait := make(chan acquired, 1)
+	p.waiters = append(p.waiters, wait)
+	p.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case a := <-wait:
+		return a.conn, a.err
+	case <-timeout:
+		p.mu.Lock()
+		if i := slices.Index(p.waiters, wait); i != -1 {
+			p.waiters = slices.Delete(p.waiters, i, i+1)
+			p.mu.Unlock()
+			return nil, ErrTimeout
+		}
+		p.mu.Unlock()
+		// A connection was handed over as the timeout expired, so it must be used or released.
+		a := <-wait
+		return a.conn, a.err
+	}
+}
+
+// release returns the connection to the pool, handing it to the first waiting request if there is one. Connections
+// which failed, or were opened before the pool was closed, are closed instead.
+func (p *Pool) release(c *conn, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if failed || c.generation != p.generation {
+		_ = c.handler.Close()
+		p.open--
+		p.replace()
+		return
+	}
+	if len(p.waiters) > 0 {
+		wait := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		wait <- acquired{conn: c}
+		return
+	}
+	p.idle = append(p.idle, c)
+}
+
+// replace opens a connection for the first waiting request, if there is one, once a connection has been closed. It
+// must be called with the mutex held.
+func (p *Pool) replace() {
+	if len(p.waiters) == 0 || p.open >= p.maxConnections {
+		return
+	}
+	wait := p.waiters[0]
+	p.waiters = p.waiters[1:]
+	p.open++
+	go func() {
+		c, err := p.dial()
+		if err != nil {
+			p.mu.Lock()
+			p.open--
+			p.mu.Unlock()
+		}
+		wait <- acquired{conn: c, err: err}
+	}()
+}
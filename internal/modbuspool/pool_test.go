@@ -0,0 +1,233 @@
+package modbuspool
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+// countingListener counts the connections accepted.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+// startPool serves a simulator until the end of the test, and returns a Pool of connections to it.
+func startPool(t *testing.T, poolConfig config.ConnectionPool) (*Pool, *modbusserver.Simulator, *countingListener) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+	simulator := modbusserver.NewSimulator()
+	server := modbusserver.NewServer(simulator)
+	go func() { _ = server.Serve(counting) }()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	pool, err := New(&config.Modbus{
+		Host:              host,
+		Port:              portNumber,
+		SlaveID:           1,
+		ConnectionTimeout: time.Second,
+		Pool:              poolConfig,
+	}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = pool.Close()
+		_ = server.Close()
+	})
+	return pool, simulator, counting
+}
+
+// size returns the number of open and idle connections.
+func (p *Pool) size() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.open, len(p.idle)
+}
+
+func TestPool_Parallel(t *testing.T) {
+	pool, simulator, listener := startPool(t, config.ConnectionPool{MaxConnections: 3})
+	simulator.SetLatency(20 * time.Millisecond)
+	client := modbus.NewClient(pool)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 9 {
+		wg.Go(func() {
+			if _, err := client.ReadHoldingRegisters(0, 1); err != nil {
+				t.Errorf("ReadHoldingRegisters() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if got := listener.accepted.Load(); got != 3 {
+		t.Errorf("accepted %d connections, want 3", got)
+	}
+	// Nine transactions over three connections take three round trips, rather than nine over one connection.
+	if elapsed := time.Since(start); elapsed >= 9*20*time.Millisecond {
+		t.Errorf("transactions took %v, want them sent in parallel", elapsed)
+	}
+}
+
+func TestPool_Fair(t *testing.T) {
+	pool, simulator, _ := startPool(t, config.ConnectionPool{MaxConnections: 1})
+	simulator.SetLatency(20 * time.Millisecond)
+	client := modbus.NewClient(pool)
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Go(func() {
+			if _, err := client.ReadCoils(0, 1); err != nil {
+				t.Errorf("ReadCoils() error = %v", err)
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		})
+		// Give each request time to start waiting before the next is made.
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	for i, got := range order {
+		if got != i {
+			t.Fatalf("requests completed in order %v, want the order they were made", order)
+		}
+	}
+}
+
+func TestPool_Maintain(t *testing.T) {
+	pool, simulator, listener := startPool(t, config.ConnectionPool{
+		MinConnections:      1,
+		MaxConnections:      3,
+		IdleTimeout:         50 * time.Millisecond,
+		HealthCheckInterval: 10 * time.Millisecond,
+	})
+
+	pool.maintain()
+	if open, idle := pool.size(); open != 1 || idle != 1 {
+		t.Fatalf("size() = %d, %d, want the minimum of 1 connection open", open, idle)
+	}
+
+	simulator.SetLatency(20 * time.Millisecond)
+	client := modbus.NewClient(pool)
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() { _, _ = client.ReadCoils(0, 1) })
+	}
+	wg.Wait()
+	simulator.SetLatency(0)
+	if open, _ := pool.size(); open != 3 {
+		t.Fatalf("open = %d, want 3", open)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	pool.maintain()
+	if open, idle := pool.size(); open != 1 || idle != 1 {
+		t.Errorf("size() = %d, %d after the idle timeout, want 1, 1", open, idle)
+	}
+
+	// The remaining connection fails its health check, so it is replaced.
+	accepted := listener.accepted.Load()
+	simulator.Disconnect(1)
+	time.Sleep(10 * time.Millisecond)
+	pool.maintain()
+	pool.maintain()
+	if open, idle := pool.size(); open != 1 || idle != 1 {
+		t.Errorf("size() = %d, %d after a failed health check, want 1, 1", open, idle)
+	}
+	// The connection is only known to be accepted once a transaction has been sent over it.
+	if _, err := client.ReadCoils(0, 1); err != nil {
+		t.Errorf("ReadCoils() error = %v", err)
+	}
+	if got := listener.accepted.Load(); got != accepted+1 {
+		t.Errorf("accepted %d connections, want the unhealthy connection replaced", got-accepted)
+	}
+}
+
+func TestPool_Close(t *testing.T) {
+	pool, _, listener := startPool(t, config.ConnectionPool{})
+	client := modbus.NewClient(pool)
+	if _, err := client.ReadCoils(0, 1); err != nil {
+		t.Fatalf("ReadCoils() error = %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := client.ReadCoils(0, 1); err != nil {
+		t.Fatalf("ReadCoils() after Close() error = %v", err)
+	}
+	if got := listener.accepted.Load(); got != 2 {
+		t.Errorf("accepted %d connections, want 2", got)
+	}
+}
+
+func TestPool_NotSent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	_ = listener.Close()
+	pool, err := New(&config.Modbus{Host: addr.IP.String(), Port: addr.Port, ConnectionTimeout: time.Second},
+		slog.New(slog.DiscardHandler),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := modbus.NewClient(pool).ReadCoils(0, 1); !errors.Is(err, ErrNotSent) {
+		t.Errorf("ReadCoils() with no connection error = %v, want %v", err, ErrNotSent)
+	}
+}
+
+func TestPool_RunDefaults(t *testing.T) {
+	pool, _, counting := startPool(t, config.ConnectionPool{MinConnections: 1, MaxConnections: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+	// Without an idle timeout or health check interval, the defaults keep Run maintaining the pool.
+	select {
+	case <-done:
+		t.Fatal("Run() returned before the context was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	<-done
+	if got := counting.accepted.Load(); got != 1 {
+		t.Errorf("accepted %d connections, want the minimum of 1", got)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(&config.Modbus{Pool: config.ConnectionPool{MinConnections: 3, MaxConnections: 2}}, slog.Default())
+	if err == nil {
+		t.Error("New() error = nil, want error")
+	}
+}
@@ -0,0 +1,404 @@
+package modbusserver
+
+import (
+	"encoding/binary"
+	"slices"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	funcCodeDiagnostics           = 0x08
+	funcCodeGetCommEventCounter   = 0x0B
+	funcCodeGetCommEventLog       = 0x0C
+	funcCodeReadFileRecord        = 0x14
+	funcCodeWriteFileRecord       = 0x15
+	funcCodeEncapsulatedInterface = 0x2B
+)
+
+const (
+	meiTypeReadDeviceIdentification = 0x0E
+	// deviceIdentificationConformity is the conformity level of the simulator, which supports every category with
+	// both stream and individual access
+	deviceIdentificationConformity = 0x83
+	fileRecordReferenceType        = 0x06
+)
+
+// handle returns the response to the request, or the exception injected for its function code.
+func (s *Simulator) handle(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if exceptionCode, ok := s.exceptions[request.FunctionCode]; ok {
+		return Exception(request, exceptionCode)
+	}
+	switch request.FunctionCode {
+	case modbus.FuncCodeReadCoils:
+		return readBits(s.coils, request, 2000)
+	case modbus.FuncCodeReadDiscreteInputs:
+		return readBits(s.discreteInputs, request, 2000)
+	case modbus.FuncCodeReadHoldingRegisters:
+		return readRegisters(s.holdingRegisters, request, 125)
+	case modbus.FuncCodeReadInputRegisters:
+		return readRegisters(s.inputRegisters, request, 125)
+	case modbus.FuncCodeWriteSingleCoil:
+		return s.writeSingleCoil(request)
+	case modbus.FuncCodeWriteSingleRegister:
+		return s.writeSingleRegister(request)
+	case modbus.FuncCodeWriteMultipleCoils:
+		return s.writeMultipleCoils(request)
+	case modbus.FuncCodeWriteMultipleRegisters:
+		return s.writeMultipleRegisters(request)
+	case modbus.FuncCodeMaskWriteRegister:
+		return s.maskWriteRegister(request)
+	case modbus.FuncCodeReadWriteMultipleRegisters:
+		return s.readWriteMultipleRegisters(request)
+	case modbus.FuncCodeReadFIFOQueue:
+		return s.readFIFOQueue(request)
+	case funcCodeDiagnostics:
+		return s.diagnostics(request)
+	case funcCodeGetCommEventCounter:
+		data := binary.BigEndian.AppendUint16([]byte{0, 0}, s.counters.commEvents)
+		return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+	case funcCodeGetCommEventLog:
+		data := []byte{byte(6 + len(s.events)), 0, 0}
+		data = binary.BigEndian.AppendUint16(data, s.counters.commEvents)
+		data = binary.BigEndian.AppendUint16(data, s.counters.busMessages)
+		return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: append(data, s.events...)}
+	case funcCodeReadFileRecord:
+		return s.readFileRecord(request)
+	case funcCodeWriteFileRecord:
+		return s.writeFileRecord(request)
+	case funcCodeEncapsulatedInterface:
+		if len(request.Data) == 3 && request.Data[0] == meiTypeReadDeviceIdentification {
+			return s.readDeviceIdentification(request)
+		}
+	}
+	if handler, ok := s.functions[request.FunctionCode]; ok {
+		return handler(request)
+	}
+	return Exception(request, modbus.ExceptionCodeIllegalFunction)
+}
+
+// readAddressQuantity returns the address and quantity at the start of the request data, or an exception response if
+// the quantity is not between 1 and maxQuantity or the addresses do not fit in a table.
+func readAddressQuantity(
+	request *modbus.ProtocolDataUnit,
+	data []byte,
+	maxQuantity int,
+) (address int, quantity int, exception *modbus.ProtocolDataUnit) {
+	address = int(binary.BigEndian.Uint16(data))
+	quantity = int(binary.BigEndian.Uint16(data[2:]))
+	if quantity < 1 || quantity > maxQuantity {
+		return 0, 0, Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	if address+quantity > tableSize {
+		return 0, 0, Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+	}
+	return address, quantity, nil
+}
+
+func readBits(table []bool, request *modbus.ProtocolDataUnit, maxQuantity int) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 4 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address, quantity, exception := readAddressQuantity(request, request.Data, maxQuantity)
+	if exception != nil {
+		return exception
+	}
+	data := make([]byte, 1+(quantity+7)/8)
+	data[0] = byte(len(data) - 1)
+	for i, value := range table[address : address+quantity] {
+		if value {
+			data[1+i/8] |= 1 << (i % 8)
+		}
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
+
+func readRegisters(table []uint16, request *modbus.ProtocolDataUnit, maxQuantity int) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 4 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address, quantity, exception := readAddressQuantity(request, request.Data, maxQuantity)
+	if exception != nil {
+		return exception
+	}
+	return &modbus.ProtocolDataUnit{
+		FunctionCode: request.FunctionCode,
+		Data:         registerData(table[address : address+quantity]),
+	}
+}
+
+// registerData returns the byte count followed by the values.
+func registerData(values []uint16) []byte {
+	data := []byte{byte(2 * len(values))}
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+	return data
+}
+
+func (s *Simulator) writeSingleCoil(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 4 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	switch binary.BigEndian.Uint16(request.Data[2:]) {
+	case 0xFF00:
+		s.coils[binary.BigEndian.Uint16(request.Data)] = true
+	case 0x0000:
+		s.coils[binary.BigEndian.Uint16(request.Data)] = false
+	default:
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	return request
+}
+
+func (s *Simulator) writeSingleRegister(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 4 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	s.holdingRegisters[binary.BigEndian.Uint16(request.Data)] = binary.BigEndian.Uint16(request.Data[2:])
+	return request
+}
+
+func (s *Simulator) writeMultipleCoils(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) < 6 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address, quantity, exception := readAddressQuantity(request, request.Data, 1968)
+	if exception != nil {
+		return exception
+	}
+	if int(request.Data[4]) != (quantity+7)/8 || len(request.Data) != 5+int(request.Data[4]) {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	for i := range quantity {
+		s.coils[address+i] = request.Data[5+i/8]&(1<<(i%8)) != 0
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: request.Data[:4]}
+}
+
+func (s *Simulator) writeMultipleRegisters(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) < 7 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address, quantity, exception := readAddressQuantity(request, request.Data, 123)
+	if exception != nil {
+		return exception
+	}
+	if int(request.Data[4]) != 2*quantity || len(request.Data) != 5+2*quantity {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	for i := range quantity {
+		s.holdingRegisters[address+i] = binary.BigEndian.Uint16(request.Data[5+2*i:])
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: request.Data[:4]}
+}
+
+func (s *Simulator) maskWriteRegister(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 6 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	address := binary.BigEndian.Uint16(request.Data)
+	andMask := binary.BigEndian.Uint16(request.Data[2:])
+	orMask := binary.BigEndian.Uint16(request.Data[4:])
+	s.holdingRegisters[address] = (s.holdingRegisters[address] & andMask) | (orMask &^ andMask)
+	return request
+}
+
+func (s *Simulator) readWriteMultipleRegisters(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) < 11 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	readAddress, readQuantity, exception := readAddressQuantity(request, request.Data, 125)
+	if exception != nil {
+		return exception
+	}
+	writeAddress, writeQuantity, exception := readAddressQuantity(request, request.Data[4:], 121)
+	if exception != nil {
+		return exception
+	}
+	if int(request.Data[8]) != 2*writeQuantity || len(request.Data) != 9+2*writeQuantity {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	// The write is performed before the read.
+	for i := range writeQuantity {
+		s.holdingRegisters[writeAddress+i] = binary.BigEndian.Uint16(request.Data[9+2*i:])
+	}
+	return &modbus.ProtocolDataUnit{
+		FunctionCode: request.FunctionCode,
+		Data:         registerData(s.holdingRegisters[readAddress : readAddress+readQuantity]),
+	}
+}
+
+func (s *Simulator) readFIFOQueue(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) != 2 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	values := s.fifos[binary.BigEndian.Uint16(request.Data)]
+	if len(values) > 31 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	data := binary.BigEndian.AppendUint16(nil, uint16(2+2*len(values)))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(values)))
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
+
+func (s *Simulator) diagnostics(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	if len(request.Data) < 2 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	subfunction := binary.BigEndian.Uint16(request.Data)
+	var count uint16
+	switch subfunction {
+	case 0x00:
+		// Return Query Data echoes the request.
+		return request
+	case 0x0A:
+		s.counters = counters{}
+		return request
+	case 0x0B:
+		count = s.counters.busMessages
+	case 0x0C:
+		// Communication errors cannot occur over TCP.
+		count = 0
+	case 0x0D:
+		count = s.counters.busExceptionErrors
+	case 0x0E:
+		count = s.counters.serverMessages
+	case 0x0F:
+		count = s.counters.serverNoResponses
+	default:
+		return Exception(request, modbus.ExceptionCodeIllegalFunction)
+	}
+	data := binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(nil, subfunction), count)
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
+
+// fileRecordSubRequest is a sub-request of a Read or Write File Record request.
+type fileRecordSubRequest struct {
+	file   uint16
+	record int
+	length int
+	// data are the values written, for a Write File Record sub-request
+	data []byte
+}
+
+// parseFileRecordSubRequests parses the sub-requests of a file record request, which contain values if withValues is
+// set. It returns false if the request is invalid.
+func parseFileRecordSubRequests(data []byte, withValues bool) ([]fileRecordSubRequest, bool) {
+	if len(data) < 8 || int(data[0]) != len(data)-1 {
+		return nil, false
+	}
+	var subRequests []fileRecordSubRequest
+	for data = data[1:]; len(data) > 0; {
+		if len(data) < 7 || data[0] != fileRecordReferenceType {
+			return nil, false
+		}
+		subRequest := fileRecordSubRequest{
+			file:   binary.BigEndian.Uint16(data[1:]),
+			record: int(binary.BigEndian.Uint16(data[3:])),
+			length: int(binary.BigEndian.Uint16(data[5:])),
+		}
+		data = data[7:]
+		if withValues {
+			if len(data) < 2*subRequest.length {
+				return nil, false
+			}
+			subRequest.data, data = data[:2*subRequest.length], data[2*subRequest.length:]
+		}
+		subRequests = append(subRequests, subRequest)
+	}
+	return subRequests, true
+}
+
+func (s *Simulator) readFileRecord(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	subRequests, ok := parseFileRecordSubRequests(request.Data, false)
+	if !ok {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	data := []byte{0}
+	for _, subRequest := range subRequests {
+		if subRequest.file == 0 || subRequest.record+subRequest.length > fileSize {
+			return Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+		}
+		data = append(data, byte(1+2*subRequest.length), fileRecordReferenceType)
+		for _, value := range s.file(subRequest.file)[subRequest.record : subRequest.record+subRequest.length] {
+			data = binary.BigEndian.AppendUint16(data, value)
+		}
+	}
+	if len(data) > maxPDULength-1 {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	data[0] = byte(len(data) - 1)
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
+
+func (s *Simulator) writeFileRecord(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	subRequests, ok := parseFileRecordSubRequests(request.Data, true)
+	if !ok {
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	for _, subRequest := range subRequests {
+		if subRequest.file == 0 || subRequest.record+subRequest.length > fileSize {
+			return Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+		}
+	}
+	for _, subRequest := range subRequests {
+		file := s.file(subRequest.file)
+		for i := range subRequest.length {
+			file[subRequest.record+i] = binary.BigEndian.Uint16(subRequest.data[2*i:])
+		}
+	}
+	// The response is an echo of the request.
+	return request
+}
+
+func (s *Simulator) readDeviceIdentification(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+	category, objectID := request.Data[1], request.Data[2]
+	var lastObjectID byte
+	switch category {
+	case 1:
+		lastObjectID = 0x02
+	case 2:
+		lastObjectID = 0x7F
+	case 3:
+		lastObjectID = 0xFF
+	case 4:
+		value, ok := s.deviceIdentification[objectID]
+		if !ok {
+			return Exception(request, modbus.ExceptionCodeIllegalDataAddress)
+		}
+		data := []byte{meiTypeReadDeviceIdentification, category, deviceIdentificationConformity, 0, 0, 1}
+		data = append(append(data, objectID, byte(len(value))), value...)
+		return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+	default:
+		return Exception(request, modbus.ExceptionCodeIllegalDataValue)
+	}
+	// Stream access restarts from the first object if the object ID is not in the category.
+	if objectID > lastObjectID {
+		objectID = 0
+	}
+	var objectIDs []byte
+	for id := range s.deviceIdentification {
+		if id >= objectID && id <= lastObjectID {
+			objectIDs = append(objectIDs, id)
+		}
+	}
+	slices.Sort(objectIDs)
+
+	data := []byte{meiTypeReadDeviceIdentification, category, deviceIdentificationConformity, 0, 0, 0}
+	for _, id := range objectIDs {
+		value := s.deviceIdentification[id]
+		// Objects which do not fit in the response follow in the next response.
+		if 1+len(data)+2+len(value) > maxPDULength && data[5] > 0 {
+			data[3] = 0xFF
+			data[4] = id
+			break
+		}
+		data = append(append(data, id, byte(len(value))), value...)
+		data[5]++
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: data}
+}
@@ -0,0 +1,179 @@
+package modbusserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"modbustohttp/pkg/config"
+	"time"
+)
+
+// defaultWaveformInterval is how often waveforms are updated if their interval is not set.
+const defaultWaveformInterval = 100 * time.Millisecond
+
+// validateProfile returns an error if the profile cannot be applied.
+func validateProfile(profile *config.SimulatorProfile) error {
+	for _, values := range profile.Values {
+		switch values.Table {
+		case config.Coils, config.DiscreteInputs, config.HoldingRegisters, config.InputRegisters:
+		default:
+			return fmt.Errorf("values at address %d are in unknown table %q", values.Address, values.Table)
+		}
+		if int(values.Address)+len(values.Values) > tableSize {
+			return fmt.Errorf("values at address %d do not fit in the %s", values.Address, values.Table)
+		}
+	}
+	for _, waveform := range profile.Waveforms {
+		if waveform.Table != config.HoldingRegisters && waveform.Table != config.InputRegisters {
+			return fmt.Errorf("waveform at address %d must drive %s or %s", waveform.Address,
+				config.HoldingRegisters, config.InputRegisters,
+			)
+		}
+		switch waveform.Shape {
+		case config.Sine, config.Ramp:
+			if waveform.Period <= 0 {
+				return fmt.Errorf("%s waveform at address %d has no period", waveform.Shape, waveform.Address)
+			}
+		case config.RandomWalk:
+		default:
+			return fmt.Errorf("waveform at address %d has unknown shape %q", waveform.Address, waveform.Shape)
+		}
+		if waveform.Max < waveform.Min {
+			return fmt.Errorf("waveform at address %d has a max smaller than its min", waveform.Address)
+		}
+	}
+	for _, mirror := range profile.Mirrors {
+		if mirror.StatusTable != config.HoldingRegisters && mirror.StatusTable != config.InputRegisters {
+			return fmt.Errorf("mirror of register %d must write its status to %s or %s", mirror.Register,
+				config.HoldingRegisters, config.InputRegisters,
+			)
+		}
+	}
+	return nil
+}
+
+// ApplyProfile sets the initial values of the profile, and simulates its behaviour until the context is cancelled.
+// It returns an error without changing the Simulator if the profile is invalid.
+func (s *Simulator) ApplyProfile(ctx context.Context, profile *config.SimulatorProfile) error {
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+	for _, values := range profile.Values {
+		switch values.Table {
+		case config.Coils:
+			s.SetCoils(values.Address, bits(values.Values)...)
+		case config.DiscreteInputs:
+			s.SetDiscreteInputs(values.Address, bits(values.Values)...)
+		case config.HoldingRegisters:
+			s.SetHoldingRegisters(values.Address, values.Values...)
+		case config.InputRegisters:
+			s.SetInputRegisters(values.Address, values.Values...)
+		}
+	}
+	for _, waveform := range profile.Waveforms {
+		go s.runWaveform(ctx, waveform)
+	}
+	for _, toggle := range profile.Toggles {
+		s.OnWrite(func(table config.Table, address uint16, quantity int) {
+			if table != config.Coils || !contains(address, quantity, toggle.Coil) {
+				return
+			}
+			value := s.Coils(toggle.Coil, 1)[0]
+			after(ctx, time.Duration(toggle.Delay), func() { s.SetDiscreteInputs(toggle.Input, value) })
+		})
+	}
+	for _, mirror := range profile.Mirrors {
+		s.OnWrite(func(table config.Table, address uint16, quantity int) {
+			if table != config.HoldingRegisters || !contains(address, quantity, mirror.Register) {
+				return
+			}
+			value := s.HoldingRegisters(mirror.Register, 1)[0]
+			after(ctx, time.Duration(mirror.Delay), func() {
+				if mirror.StatusTable == config.InputRegisters {
+					s.SetInputRegisters(mirror.Status, value)
+				} else {
+					s.SetHoldingRegisters(mirror.Status, value)
+				}
+			})
+		})
+	}
+	return nil
+}
+
+// after calls f after the delay, unless the context is cancelled first.
+func after(ctx context.Context, delay time.Duration, f func()) {
+	if delay <= 0 {
+		f()
+		return
+	}
+	time.AfterFunc(delay, func() {
+		if ctx.Err() == nil {
+			f()
+		}
+	})
+}
+
+// runWaveform updates the register driven by the waveform at each interval until the context is cancelled.
+func (s *Simulator) runWaveform(ctx context.Context, waveform config.SimulatorWaveform) {
+	interval := time.Duration(waveform.Interval)
+	if interval <= 0 {
+		interval = defaultWaveformInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	start := time.Now()
+	value := waveform.Min + (waveform.Max-waveform.Min)/2
+	for {
+		switch waveform.Shape {
+		case config.Sine, config.Ramp:
+			value = waveformValue(waveform, time.Since(start))
+		case config.RandomWalk:
+			value = randomStep(waveform, value)
+		}
+		if waveform.Table == config.InputRegisters {
+			s.SetInputRegisters(waveform.Address, value)
+		} else {
+			s.SetHoldingRegisters(waveform.Address, value)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// waveformValue returns the value of a sine or ramp waveform the elapsed time after it started.
+func waveformValue(waveform config.SimulatorWaveform, elapsed time.Duration) uint16 {
+	phase := math.Mod(float64(elapsed), float64(waveform.Period)) / float64(waveform.Period)
+	span := float64(waveform.Max - waveform.Min)
+	if waveform.Shape == config.Sine {
+		return waveform.Min + uint16(math.Round(span*(1+math.Sin(2*math.Pi*phase))/2))
+	}
+	return waveform.Min + uint16(math.Round(span*phase))
+}
+
+// randomStep returns the next value of a random walk, which stays between the min and max of the waveform.
+func randomStep(waveform config.SimulatorWaveform, value uint16) uint16 {
+	step := int(waveform.Step)
+	if step == 0 {
+		step = 1
+	}
+	next := int(value) + rand.IntN(2*step+1) - step
+	return uint16(min(max(next, int(waveform.Min)), int(waveform.Max)))
+}
+
+// bits returns whether each value is not zero.
+func bits(values []uint16) []bool {
+	result := make([]bool, len(values))
+	for i, value := range values {
+		result[i] = value != 0
+	}
+	return result
+}
+
+// contains returns whether quantity addresses starting at address include target.
+func contains(address uint16, quantity int, target uint16) bool {
+	return target >= address && int(target) < int(address)+quantity
+}
@@ -0,0 +1,153 @@
+package modbusserver
+
+import (
+	"context"
+	"modbustohttp/pkg/config"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+func TestSimulator_ApplyProfile(t *testing.T) {
+	simulator := NewSimulator()
+	client := modbus.NewClient(startServer(t, simulator))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := simulator.ApplyProfile(ctx, &config.SimulatorProfile{
+		Values: []config.SimulatorValues{
+			{Table: config.DiscreteInputs, Address: 5, Values: []uint16{1, 0, 2}},
+			{Table: config.HoldingRegisters, Address: 10, Values: []uint16{7, 8}},
+		},
+		Toggles: []config.SimulatorToggle{{Coil: 3, Input: 20, Delay: config.Duration(20 * time.Millisecond)}},
+		Mirrors: []config.SimulatorMirror{{Register: 11, StatusTable: config.InputRegisters, Status: 30}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyProfile() error = %v", err)
+	}
+	if got := simulator.DiscreteInputs(5, 3); !slices.Equal(got, []bool{true, false, true}) {
+		t.Errorf("DiscreteInputs() = %v, want [true false true]", got)
+	}
+	if got := simulator.HoldingRegisters(10, 2); !slices.Equal(got, []uint16{7, 8}) {
+		t.Errorf("HoldingRegisters() = %v, want [7 8]", got)
+	}
+
+	if _, err := client.WriteMultipleRegisters(10, 2, []byte{0, 1, 0, 42}); err != nil {
+		t.Fatalf("WriteMultipleRegisters() error = %v", err)
+	}
+	if got := simulator.InputRegisters(30, 1); got[0] != 42 {
+		t.Errorf("InputRegisters() mirroring the write = %d, want 42", got[0])
+	}
+
+	if _, err := client.WriteSingleCoil(3, 0xFF00); err != nil {
+		t.Fatalf("WriteSingleCoil() error = %v", err)
+	}
+	if simulator.DiscreteInputs(20, 1)[0] {
+		t.Error("DiscreteInputs() before the toggle delay = true, want false")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !simulator.DiscreteInputs(20, 1)[0] {
+		if time.Now().After(deadline) {
+			t.Fatal("DiscreteInputs() after the toggle delay = false, want true")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSimulator_ApplyProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile config.SimulatorProfile
+	}{
+		{
+			name:    "Unknown table",
+			profile: config.SimulatorProfile{Values: []config.SimulatorValues{{Table: "registers"}}},
+		},
+		{
+			name: "Values past the end",
+			profile: config.SimulatorProfile{Values: []config.SimulatorValues{
+				{Table: config.Coils, Address: 65535, Values: []uint16{1, 1}},
+			}},
+		},
+		{
+			name: "Waveform driving coils",
+			profile: config.SimulatorProfile{Waveforms: []config.SimulatorWaveform{
+				{Table: config.Coils, Shape: config.RandomWalk},
+			}},
+		},
+		{
+			name: "Sine without period",
+			profile: config.SimulatorProfile{Waveforms: []config.SimulatorWaveform{
+				{Table: config.InputRegisters, Shape: config.Sine, Max: 10},
+			}},
+		},
+		{
+			name: "Max smaller than min",
+			profile: config.SimulatorProfile{Waveforms: []config.SimulatorWaveform{
+				{Table: config.InputRegisters, Shape: config.RandomWalk, Min: 10, Max: 5},
+			}},
+		},
+		{
+			name: "Mirror to coils",
+			profile: config.SimulatorProfile{Mirrors: []config.SimulatorMirror{
+				{Register: 1, StatusTable: config.Coils},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewSimulator().ApplyProfile(context.Background(), &tt.profile); err == nil {
+				t.Error("ApplyProfile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestWaveformValue(t *testing.T) {
+	sine := config.SimulatorWaveform{Shape: config.Sine, Min: 100, Max: 200, Period: config.Duration(4 * time.Second)}
+	ramp := config.SimulatorWaveform{Shape: config.Ramp, Min: 0, Max: 1000, Period: config.Duration(10 * time.Second)}
+	tests := []struct {
+		name     string
+		waveform config.SimulatorWaveform
+		elapsed  time.Duration
+		want     uint16
+	}{
+		{name: "Sine start", waveform: sine, elapsed: 0, want: 150},
+		{name: "Sine peak", waveform: sine, elapsed: time.Second, want: 200},
+		{name: "Sine trough", waveform: sine, elapsed: 3 * time.Second, want: 100},
+		{name: "Ramp middle", waveform: ramp, elapsed: 5 * time.Second, want: 500},
+		{name: "Ramp wraps", waveform: ramp, elapsed: 12 * time.Second, want: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := waveformValue(tt.waveform, tt.elapsed); got != tt.want {
+				t.Errorf("waveformValue(%v) = %d, want %d", tt.elapsed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomStep(t *testing.T) {
+	waveform := config.SimulatorWaveform{Shape: config.RandomWalk, Min: 10, Max: 12, Step: 5}
+	value := uint16(11)
+	for range 100 {
+		value = randomStep(waveform, value)
+		if value < 10 || value > 12 {
+			t.Fatalf("randomStep() = %d, want between 10 and 12", value)
+		}
+	}
+}
+
+func TestLoadSimulatorProfile_Example(t *testing.T) {
+	profile, err := config.LoadSimulatorProfile("../../simulator.example.yaml")
+	if err != nil {
+		t.Fatalf("LoadSimulatorProfile() error = %v", err)
+	}
+	if err := validateProfile(profile); err != nil {
+		t.Errorf("validateProfile() error = %v", err)
+	}
+	if got := time.Duration(profile.Waveforms[0].Period); got != time.Minute {
+		t.Errorf("Waveforms[0].Period = %v, want %v", got, time.Minute)
+	}
+}
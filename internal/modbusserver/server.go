@@ -0,0 +1,199 @@
+package modbusserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	// mbapHeaderLength is the length of the Modbus Application Protocol header which precedes each PDU
+	mbapHeaderLength = 7
+	// maxPDULength is the largest PDU allowed by the Modbus specification
+	maxPDULength = 253
+)
+
+// ErrServerClosed is returned by Serve after Close is called.
+var ErrServerClosed = errors.New("modbusserver: server closed")
+
+// ErrDisconnect can be returned by a Handler to close the connection without responding.
+var ErrDisconnect = errors.New("modbusserver: disconnect")
+
+// remoteAddrKey is the context key of the address of the client which sent a request.
+type remoteAddrKey struct{}
+
+// RemoteAddr returns the address of the client which sent the request being handled, if the context was passed to a
+// Handler by a Server.
+func RemoteAddr(ctx context.Context) (net.Addr, bool) {
+	addr, ok := ctx.Value(remoteAddrKey{}).(net.Addr)
+	return addr, ok
+}
+
+// Handler handles the request PDUs received by a Server.
+type Handler interface {
+	// HandlePDU returns the response PDU to the request PDU sent to the given unit ID. If it returns an error, the
+	// connection is closed without a response. The context is cancelled when the server is closed, and holds the
+	// address of the client, which is returned by RemoteAddr.
+	HandlePDU(ctx context.Context, unitID byte, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error)
+}
+
+// HandlerFunc is a function which can be used as a Handler.
+type HandlerFunc func(ctx context.Context, unitID byte, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error)
+
+// HandlePDU calls f.
+func (f HandlerFunc) HandlePDU(
+	ctx context.Context,
+	unitID byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	return f(ctx, unitID, request)
+}
+
+// Exception returns the exception response to the request with the given exception code.
+func Exception(request *modbus.ProtocolDataUnit, exceptionCode byte) *modbus.ProtocolDataUnit {
+	return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode | 0x80, Data: []byte{exceptionCode}}
+}
+
+// Server is a Modbus TCP server which passes each request received to a Handler. The transactions on a connection are
+// handled one at a time, in the order they are received.
+type Server struct {
+	handler Handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	wg        sync.WaitGroup
+}
+
+// NewServer returns a Server which passes each request received to the handler.
+func NewServer(handler Handler) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		handler:   handler,
+		ctx:       ctx,
+		cancel:    cancel,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves the connections accepted. It always returns a non-nil error.
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves the connections accepted by the listener until it fails or the server is closed, in which case it
+// returns ErrServerClosed. The listener is closed when Serve returns.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = listener.Close()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, listener)
+		s.mu.Unlock()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the server, closing its listeners and connections, and waits for the connections to be released.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.cancel()
+	for listener := range s.listeners {
+		_ = listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn handles the transactions received on the connection until it is closed or the handler returns an error.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+	ctx := context.WithValue(s.ctx, remoteAddrKey{}, conn.RemoteAddr())
+	header := make([]byte, mbapHeaderLength)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		// The length counts the unit identifier and the PDU, which contains at least the function code.
+		length := int(binary.BigEndian.Uint16(header[4:]))
+		if binary.BigEndian.Uint16(header[2:]) != 0 || length < 2 || length > maxPDULength+1 {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		response, err := s.handler.HandlePDU(ctx, header[6], &modbus.ProtocolDataUnit{
+			FunctionCode: pdu[0],
+			Data:         pdu[1:],
+		})
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(encode(header, response)); err != nil {
+			return
+		}
+	}
+}
+
+// encode returns the response ADU to the request with the given MBAP header.
+func encode(requestHeader []byte, response *modbus.ProtocolDataUnit) []byte {
+	adu := make([]byte, mbapHeaderLength, mbapHeaderLength+1+len(response.Data))
+	copy(adu, requestHeader[:mbapHeaderLength])
+	binary.BigEndian.PutUint16(adu[4:], uint16(2+len(response.Data)))
+	adu = append(adu, response.FunctionCode)
+	return append(adu, response.Data...)
+}
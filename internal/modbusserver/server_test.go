@@ -0,0 +1,123 @@
+package modbusserver
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+// startServer serves the handler on a local port until the end of the test, and returns a client handler connected to
+// it.
+func startServer(t *testing.T, handler Handler) *modbus.TCPClientHandler {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(handler)
+	go func() { _ = server.Serve(listener) }()
+	clientHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+	clientHandler.Timeout = time.Second
+	t.Cleanup(func() {
+		_ = clientHandler.Close()
+		_ = server.Close()
+	})
+	return clientHandler
+}
+
+func TestSimulator(t *testing.T) {
+	simulator := NewSimulator()
+	client := modbus.NewClient(startServer(t, simulator))
+
+	simulator.SetDiscreteInputs(10, true, false, true)
+	if got, err := client.ReadDiscreteInputs(10, 3); err != nil || !slices.Equal(got, []byte{0b101}) {
+		t.Errorf("ReadDiscreteInputs() = %v, %v, want [5], nil", got, err)
+	}
+	simulator.SetInputRegisters(5, 0x1234)
+	if got, err := client.ReadInputRegisters(5, 1); err != nil || !slices.Equal(got, []byte{0x12, 0x34}) {
+		t.Errorf("ReadInputRegisters() = %v, %v, want [18 52], nil", got, err)
+	}
+
+	if _, err := client.WriteMultipleCoils(100, 10, []byte{0xFF, 0x02}); err != nil {
+		t.Fatalf("WriteMultipleCoils() error = %v", err)
+	}
+	want := []bool{true, true, true, true, true, true, true, true, false, true}
+	if got := simulator.Coils(100, 10); !slices.Equal(got, want) {
+		t.Errorf("Coils() = %v, want %v", got, want)
+	}
+
+	if _, err := client.WriteMultipleRegisters(200, 2, []byte{0x00, 0x0F, 0x00, 0xF0}); err != nil {
+		t.Fatalf("WriteMultipleRegisters() error = %v", err)
+	}
+	if _, err := client.MaskWriteRegister(200, 0x00F0, 0x0005); err != nil {
+		t.Fatalf("MaskWriteRegister() error = %v", err)
+	}
+	if got := simulator.HoldingRegisters(200, 2); !slices.Equal(got, []uint16{0x0005, 0x00F0}) {
+		t.Errorf("HoldingRegisters() = %v, want [5 240]", got)
+	}
+
+	if _, err := client.ReadHoldingRegisters(65535, 2); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("ReadHoldingRegisters() past the end error = %v, want illegal data address", err)
+	}
+}
+
+func TestSimulator_Faults(t *testing.T) {
+	simulator := NewSimulator()
+	clientHandler := startServer(t, simulator)
+	client := modbus.NewClient(clientHandler)
+
+	simulator.SetException(modbus.FuncCodeReadCoils, modbus.ExceptionCodeServerDeviceBusy)
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeServerDeviceBusy) {
+		t.Errorf("ReadCoils() error = %v, want server device busy", err)
+	}
+	simulator.SetException(modbus.FuncCodeReadCoils, 0)
+	if _, err := client.ReadCoils(0, 1); err != nil {
+		t.Errorf("ReadCoils() after removing the exception error = %v", err)
+	}
+
+	simulator.Disconnect(1)
+	if _, err := client.ReadCoils(0, 1); err == nil {
+		t.Error("ReadCoils() while disconnecting error = nil, want error")
+	}
+	_ = clientHandler.Close()
+	if _, err := client.ReadCoils(0, 1); err != nil {
+		t.Errorf("ReadCoils() after reconnecting error = %v", err)
+	}
+
+	simulator.SetLatency(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := client.ReadCoils(0, 1); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("ReadCoils() with latency took %v, error = %v, want at least 50ms", time.Since(start), err)
+	}
+}
+
+func TestServer_Close(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(NewSimulator())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	clientHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+	defer func() { _ = clientHandler.Close() }()
+	if _, err := modbus.NewClient(clientHandler).ReadCoils(0, 1); err != nil {
+		t.Fatalf("ReadCoils() error = %v", err)
+	}
+	if err := server.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve() error = %v, want %v", err, ErrServerClosed)
+	}
+}
+
+func isException(err error, exceptionCode byte) bool {
+	var modbusErr *modbus.ModbusError
+	return errors.As(err, &modbusErr) && modbusErr.ExceptionCode == exceptionCode
+}
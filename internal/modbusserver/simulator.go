@@ -0,0 +1,322 @@
+package modbusserver
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	// tableSize is the number of addresses in each table
+	tableSize = 65536
+	// fileSize is the number of records in each file
+	fileSize = 10000
+	// maxEvents is the number of events kept in the communication event log
+	maxEvents = 64
+)
+
+// FunctionHandler returns the response PDU to a request with a function code which the Simulator does not implement,
+// such as a vendor specific function code.
+type FunctionHandler func(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit
+
+// WriteHook is called after a client writes quantity values starting at address to a table.
+type WriteHook func(table config.Table, address uint16, quantity int)
+
+// counters are the diagnostic counters of a Simulator.
+type counters struct {
+	busMessages        uint16
+	busExceptionErrors uint16
+	serverMessages     uint16
+	serverNoResponses  uint16
+	// commEvents is the number of transactions completed successfully, as returned by Get Comm Event Counter
+	commEvents uint16
+}
+
+// Simulator is a Handler which simulates a modbus server with in-memory tables, for development and tests. It answers
+// requests sent to any unit ID. Exceptions, latency and disconnects can be injected to simulate faults.
+type Simulator struct {
+	mu               sync.Mutex
+	coils            []bool
+	discreteInputs   []bool
+	holdingRegisters []uint16
+	inputRegisters   []uint16
+	// fifos are the values of the FIFO queues, by the address of their pointer register
+	fifos map[uint16][]uint16
+	// files are the records of the files which have been written, by file number
+	files map[uint16][]uint16
+	// deviceIdentification are the device identification objects, by object ID
+	deviceIdentification map[byte][]byte
+	functions            map[byte]FunctionHandler
+	writeHooks           []WriteHook
+	exceptions           map[byte]byte
+	latency              time.Duration
+	disconnects          int
+	counters             counters
+	// events is the communication event log, the most recent first
+	events []byte
+}
+
+// NewSimulator returns a Simulator with every table set to zero, and device identification objects describing the
+// simulator.
+func NewSimulator() *Simulator {
+	return &Simulator{
+		coils:            make([]bool, tableSize),
+		discreteInputs:   make([]bool, tableSize),
+		holdingRegisters: make([]uint16, tableSize),
+		inputRegisters:   make([]uint16, tableSize),
+		fifos:            make(map[uint16][]uint16),
+		files:            make(map[uint16][]uint16),
+		deviceIdentification: map[byte][]byte{
+			0x00: []byte("modbustohttp"),
+			0x01: []byte("simulator"),
+			0x02: []byte("1.0"),
+		},
+		functions:  make(map[byte]FunctionHandler),
+		exceptions: make(map[byte]byte),
+	}
+}
+
+// checkRange panics if quantity values starting at address do not fit in a table of the given size.
+func checkRange(address int, quantity int, size int) {
+	if address+quantity > size {
+		panic(fmt.Sprintf("modbusserver: %d values at address %d do not fit in %d addresses", quantity, address, size))
+	}
+}
+
+// SetCoils sets the coils starting at address to the values.
+func (s *Simulator) SetCoils(address uint16, values ...bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), len(values), tableSize)
+	copy(s.coils[address:], values)
+}
+
+// Coils returns quantity coils starting at address.
+func (s *Simulator) Coils(address uint16, quantity int) []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), quantity, tableSize)
+	return append([]bool(nil), s.coils[address:int(address)+quantity]...)
+}
+
+// SetDiscreteInputs sets the discrete inputs starting at address to the values.
+func (s *Simulator) SetDiscreteInputs(address uint16, values ...bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), len(values), tableSize)
+	copy(s.discreteInputs[address:], values)
+}
+
+// DiscreteInputs returns quantity discrete inputs starting at address.
+func (s *Simulator) DiscreteInputs(address uint16, quantity int) []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), quantity, tableSize)
+	return append([]bool(nil), s.discreteInputs[address:int(address)+quantity]...)
+}
+
+// SetHoldingRegisters sets the holding registers starting at address to the values.
+func (s *Simulator) SetHoldingRegisters(address uint16, values ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), len(values), tableSize)
+	copy(s.holdingRegisters[address:], values)
+}
+
+// HoldingRegisters returns quantity holding registers starting at address.
+func (s *Simulator) HoldingRegisters(address uint16, quantity int) []uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), quantity, tableSize)
+	return append([]uint16(nil), s.holdingRegisters[address:int(address)+quantity]...)
+}
+
+// SetInputRegisters sets the input registers starting at address to the values.
+func (s *Simulator) SetInputRegisters(address uint16, values ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), len(values), tableSize)
+	copy(s.inputRegisters[address:], values)
+}
+
+// InputRegisters returns quantity input registers starting at address.
+func (s *Simulator) InputRegisters(address uint16, quantity int) []uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(address), quantity, tableSize)
+	return append([]uint16(nil), s.inputRegisters[address:int(address)+quantity]...)
+}
+
+// SetFIFO sets the values of the FIFO queue with the pointer register at address, the oldest first.
+func (s *Simulator) SetFIFO(address uint16, values ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fifos[address] = append([]uint16(nil), values...)
+}
+
+// SetFileRecords sets the records of the file starting at record to the values.
+func (s *Simulator) SetFileRecords(file uint16, record uint16, values ...uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(record), len(values), fileSize)
+	copy(s.file(file)[record:], values)
+}
+
+// FileRecords returns quantity records of the file starting at record.
+func (s *Simulator) FileRecords(file uint16, record uint16, quantity int) []uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkRange(int(record), quantity, fileSize)
+	return append([]uint16(nil), s.file(file)[record:int(record)+quantity]...)
+}
+
+// file returns the records of the file, creating it if it has not been written.
+func (s *Simulator) file(file uint16) []uint16 {
+	if s.files[file] == nil {
+		s.files[file] = make([]uint16, fileSize)
+	}
+	return s.files[file]
+}
+
+// SetDeviceIdentification sets the value of a device identification object.
+func (s *Simulator) SetDeviceIdentification(objectID byte, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviceIdentification[objectID] = []byte(value)
+}
+
+// HandleFunction handles requests with the function code using the handler, instead of returning an illegal function
+// exception. It can be used to simulate vendor specific function codes.
+func (s *Simulator) HandleFunction(functionCode byte, handler FunctionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions[functionCode] = handler
+}
+
+// OnWrite calls the hook after each request which writes to the coils or holding registers. The hook is called after
+// the response is built, so it can use the other methods of the Simulator.
+func (s *Simulator) OnWrite(hook WriteHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeHooks = append(s.writeHooks, hook)
+}
+
+// SetException makes every request with the function code return the exception code. An exception code of zero
+// removes the exception.
+func (s *Simulator) SetException(functionCode byte, exceptionCode byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exceptionCode == 0 {
+		delete(s.exceptions, functionCode)
+		return
+	}
+	s.exceptions[functionCode] = exceptionCode
+}
+
+// SetLatency delays every response by the duration.
+func (s *Simulator) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// Disconnect makes the next count requests close the connection without responding.
+func (s *Simulator) Disconnect(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disconnects = count
+}
+
+// HandlePDU handles the request, after the configured latency.
+func (s *Simulator) HandlePDU(
+	ctx context.Context,
+	_ byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	s.mu.Lock()
+	latency := s.latency
+	disconnect := s.disconnects > 0
+	s.counters.busMessages++
+	s.counters.serverMessages++
+	if disconnect {
+		s.disconnects--
+		s.counters.serverNoResponses++
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	if disconnect {
+		return nil, ErrDisconnect
+	}
+
+	s.mu.Lock()
+	response := s.handle(request)
+	s.recordEvents(request, response)
+	hooks := s.writeHooks
+	s.mu.Unlock()
+
+	if response.FunctionCode&0x80 == 0 {
+		if table, address, quantity, ok := written(request); ok {
+			for _, hook := range hooks {
+				hook(table, address, quantity)
+			}
+		}
+	}
+	return response, nil
+}
+
+// written returns the addresses written by a successful request, if it writes to the coils or holding registers.
+func written(request *modbus.ProtocolDataUnit) (table config.Table, address uint16, quantity int, ok bool) {
+	switch request.FunctionCode {
+	case modbus.FuncCodeWriteSingleCoil:
+		return config.Coils, binary.BigEndian.Uint16(request.Data), 1, true
+	case modbus.FuncCodeWriteMultipleCoils:
+		return config.Coils, binary.BigEndian.Uint16(request.Data), int(binary.BigEndian.Uint16(request.Data[2:])), true
+	case modbus.FuncCodeWriteSingleRegister, modbus.FuncCodeMaskWriteRegister:
+		return config.HoldingRegisters, binary.BigEndian.Uint16(request.Data), 1, true
+	case modbus.FuncCodeWriteMultipleRegisters:
+		return config.HoldingRegisters, binary.BigEndian.Uint16(request.Data),
+			int(binary.BigEndian.Uint16(request.Data[2:])), true
+	case modbus.FuncCodeReadWriteMultipleRegisters:
+		return config.HoldingRegisters, binary.BigEndian.Uint16(request.Data[4:]),
+			int(binary.BigEndian.Uint16(request.Data[6:])), true
+	}
+	return "", 0, 0, false
+}
+
+// recordEvents updates the counters and communication event log for the transaction.
+func (s *Simulator) recordEvents(request *modbus.ProtocolDataUnit, response *modbus.ProtocolDataUnit) {
+	// The send event records the type of exception returned, if any.
+	sendEvent := byte(0x40)
+	if response.FunctionCode&0x80 != 0 {
+		s.counters.busExceptionErrors++
+		switch response.Data[0] {
+		case modbus.ExceptionCodeIllegalFunction, modbus.ExceptionCodeIllegalDataAddress,
+			modbus.ExceptionCodeIllegalDataValue:
+			sendEvent |= 0x01
+		case modbus.ExceptionCodeServerDeviceFailure:
+			sendEvent |= 0x02
+		case modbus.ExceptionCodeAcknowledge, modbus.ExceptionCodeServerDeviceBusy:
+			sendEvent |= 0x04
+		}
+	} else if request.FunctionCode != funcCodeGetCommEventCounter && request.FunctionCode != funcCodeGetCommEventLog {
+		s.counters.commEvents++
+	}
+	s.events = append([]byte{sendEvent, 0x80}, s.events...)
+	if len(s.events) > maxEvents {
+		s.events = s.events[:maxEvents]
+	}
+}
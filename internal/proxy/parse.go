@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"modbustohttp/internal/authz"
+	"modbustohttp/pkg/config"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	funcCodeReadFIFOQueue             = 0x18
+	funcCodeEncapsulatedInterface     = 0x2B
+	meiTypeReadDeviceIdentification   = 0x0E
+	maxReadBits                       = 2000
+	maxReadRegisters                  = 125
+	maxWriteBits                      = 1968
+	maxWriteRegisters                 = 123
+	maxReadWriteMultipleWriteQuantity = 121
+)
+
+// operation is what a request PDU does, in the terms of the equivalent RPC.
+type operation struct {
+	// function is the ModbusFunction which must be supported to forward the request
+	function config.ModbusFunction
+	// procedure is the name of the equivalent RPC method, which the request is authorized as
+	procedure string
+	// ranges are the address ranges accessed
+	ranges []authz.Range
+	// cacheable is set if the response only depends on the values read, so it can be served to other masters
+	cacheable bool
+	// writeTable is the table written to, or empty if the request does not write
+	writeTable   config.Table
+	writeAddress uint32
+	values       []uint32
+	// masked is set for mask writes, which write (current & andMask) | (orMask & ^andMask)
+	masked  bool
+	andMask uint16
+	orMask  uint16
+}
+
+// read sets the read of quantity addresses of the table starting at address.
+func (op *operation) read(table config.Table, address uint32, quantity uint32) {
+	op.ranges = append(op.ranges, authz.Range{Table: table, Start: address, End: address + quantity - 1})
+}
+
+// write sets the write of the values to the table starting at address.
+func (op *operation) write(table config.Table, address uint32, values []uint32) {
+	op.ranges = append(op.ranges, authz.Range{Table: table, Start: address, End: address + uint32(len(values)) - 1})
+	op.writeTable = table
+	op.writeAddress = address
+	op.values = values
+}
+
+// parse returns the operation of the request PDU, or the exception code to respond with if the function code is not
+// supported by the proxy or the request is malformed.
+func parse(request *modbus.ProtocolDataUnit) (operation, byte) {
+	data := request.Data
+	var op operation
+	switch request.FunctionCode {
+	case modbus.FuncCodeReadCoils, modbus.FuncCodeReadDiscreteInputs,
+		modbus.FuncCodeReadHoldingRegisters, modbus.FuncCodeReadInputRegisters:
+		if len(data) != 4 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		address, quantity := uint32(binary.BigEndian.Uint16(data)), uint32(binary.BigEndian.Uint16(data[2:]))
+		table, function, limit := readFunction(request.FunctionCode)
+		if quantity < 1 || quantity > limit || address+quantity > 1<<16 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = function, string(function)
+		op.read(table, address, quantity)
+		op.cacheable = true
+	case modbus.FuncCodeWriteSingleCoil:
+		if len(data) != 4 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		var value uint32
+		switch binary.BigEndian.Uint16(data[2:]) {
+		case 0xFF00:
+			value = 1
+		case 0x0000:
+		default:
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.WriteSingleCoil, "WriteSingleCoil"
+		op.write(config.Coils, uint32(binary.BigEndian.Uint16(data)), []uint32{value})
+	case modbus.FuncCodeWriteSingleRegister:
+		if len(data) != 4 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.WriteSingleRegister, "WriteSingleRegister"
+		op.write(config.HoldingRegisters, uint32(binary.BigEndian.Uint16(data)),
+			[]uint32{uint32(binary.BigEndian.Uint16(data[2:]))},
+		)
+	case modbus.FuncCodeWriteMultipleCoils:
+		if len(data) < 5 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		address, quantity := uint32(binary.BigEndian.Uint16(data)), int(binary.BigEndian.Uint16(data[2:]))
+		if quantity < 1 || quantity > maxWriteBits || address+uint32(quantity) > 1<<16 ||
+			int(data[4]) != (quantity+7)/8 || len(data) != 5+int(data[4]) {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		values := make([]uint32, quantity)
+		for i := range values {
+			values[i] = uint32(data[5+i/8]>>(i%8)) & 1
+		}
+		op.function, op.procedure = config.WriteMultipleCoils, "WriteMultipleCoils"
+		op.write(config.Coils, address, values)
+	case modbus.FuncCodeWriteMultipleRegisters:
+		if len(data) < 5 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		address, quantity := uint32(binary.BigEndian.Uint16(data)), int(binary.BigEndian.Uint16(data[2:]))
+		values, ok := registers(data[4:], quantity, maxWriteRegisters)
+		if !ok || address+uint32(quantity) > 1<<16 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.WriteMultipleRegisters, "WriteMultipleRegisters"
+		op.write(config.HoldingRegisters, address, values)
+	case modbus.FuncCodeMaskWriteRegister:
+		if len(data) != 6 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.MaskWriteSingleRegister, "MaskWriteRegister"
+		address := uint32(binary.BigEndian.Uint16(data))
+		op.ranges = []authz.Range{{Table: config.HoldingRegisters, Start: address, End: address}}
+		op.writeTable = config.HoldingRegisters
+		op.writeAddress = address
+		op.masked = true
+		op.andMask = binary.BigEndian.Uint16(data[2:])
+		op.orMask = binary.BigEndian.Uint16(data[4:])
+	case modbus.FuncCodeReadWriteMultipleRegisters:
+		if len(data) < 9 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		readAddress, readQuantity := uint32(binary.BigEndian.Uint16(data)), uint32(binary.BigEndian.Uint16(data[2:]))
+		writeAddress, writeQuantity := uint32(binary.BigEndian.Uint16(data[4:])), int(binary.BigEndian.Uint16(data[6:]))
+		values, ok := registers(data[8:], writeQuantity, maxReadWriteMultipleWriteQuantity)
+		if !ok || readQuantity < 1 || readQuantity > maxReadRegisters || readAddress+readQuantity > 1<<16 ||
+			writeAddress+uint32(writeQuantity) > 1<<16 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.ReadWriteMultipleRegisters, "ReadWriteMultipleRegisters"
+		op.read(config.HoldingRegisters, readAddress, readQuantity)
+		op.write(config.HoldingRegisters, writeAddress, values)
+	case funcCodeReadFIFOQueue:
+		if len(data) != 2 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.ReadFIFOQueue, "ReadFIFOQueue"
+		op.read(config.HoldingRegisters, uint32(binary.BigEndian.Uint16(data)), 1)
+	case funcCodeEncapsulatedInterface:
+		if len(data) < 1 || data[0] != meiTypeReadDeviceIdentification {
+			return op, modbus.ExceptionCodeIllegalFunction
+		}
+		if len(data) != 3 {
+			return op, modbus.ExceptionCodeIllegalDataValue
+		}
+		op.function, op.procedure = config.ReadDeviceIdentification, "ReadDeviceIdentification"
+		op.cacheable = true
+	default:
+		return op, modbus.ExceptionCodeIllegalFunction
+	}
+	return op, 0
+}
+
+// readFunction returns the table, ModbusFunction and maximum quantity of a read function code.
+func readFunction(functionCode byte) (config.Table, config.ModbusFunction, uint32) {
+	switch functionCode {
+	case modbus.FuncCodeReadCoils:
+		return config.Coils, config.ReadCoils, maxReadBits
+	case modbus.FuncCodeReadDiscreteInputs:
+		return config.DiscreteInputs, config.ReadDiscreteInputs, maxReadBits
+	case modbus.FuncCodeReadHoldingRegisters:
+		return config.HoldingRegisters, config.ReadHoldingRegisters, maxReadRegisters
+	default:
+		return config.InputRegisters, config.ReadInputRegisters, maxReadRegisters
+	}
+}
+
+// registers decodes the byte count and values of quantity registers, returning false if the quantity is not between
+// 1 and limit or does not match the data.
+func registers(data []byte, quantity int, limit int) ([]uint32, bool) {
+	if quantity < 1 || quantity > limit || len(data) < 1 || int(data[0]) != 2*quantity || len(data) != 1+2*quantity {
+		return nil, false
+	}
+	values := make([]uint32, quantity)
+	for i := range values {
+		values[i] = uint32(binary.BigEndian.Uint16(data[1+2*i:]))
+	}
+	return values, true
+}
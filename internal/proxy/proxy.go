@@ -0,0 +1,416 @@
+// Package proxy forwards the transactions of many modbus masters over a single connection to a modbus server, for
+// servers which only accept a few connections.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/authz"
+	"modbustohttp/internal/guardrails"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+)
+
+// Upstream forwards request PDUs to the modbus server. It is implemented by modbusservice.Service, so that the proxy
+// shares its connection.
+type Upstream interface {
+	// Forward sends the request PDU to the unit ID, and returns the response PDU, which may be an exception response.
+	Forward(ctx context.Context, unitID byte, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error)
+}
+
+// principalNetwork identifies the masters which connect from a network as a principal.
+type principalNetwork struct {
+	prefix    netip.Prefix
+	principal string
+}
+
+// maxCacheEntries is the largest number of responses cached, so that masters making many different reads cannot grow
+// the cache without limit.
+const maxCacheEntries = 1024
+
+// cacheKey identifies a read request.
+type cacheKey struct {
+	unitID       byte
+	functionCode byte
+	data         string
+}
+
+// cachedResponse is the response to a read request.
+type cachedResponse struct {
+	response *modbus.ProtocolDataUnit
+	received time.Time
+}
+
+// Proxy is a modbusserver.Handler which forwards the requests of modbus masters to the Upstream. Each request is
+// authorized, checked against the write guardrails and audited in the same way as the equivalent RPC. The transaction
+// IDs of the masters are replaced by the transaction IDs of the upstream connection, and restored in the responses.
+type Proxy struct {
+	upstream     Upstream
+	modbusConfig *config.Modbus
+	policy       *authz.Policy
+	guard        *guardrails.Guard
+	auditLogger  *audit.Logger
+	principals   []principalNetwork
+	cacheMaxAge  time.Duration
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu    sync.Mutex
+	cache map[cacheKey]cachedResponse
+}
+
+// New returns a Proxy which forwards requests to the upstream. Requests are not authorized if the policy is nil,
+// writes are not checked if the guard is nil and writes are not audited if the audit logger is nil.
+func New(
+	proxyConfig *config.Proxy,
+	modbusConfig *config.Modbus,
+	upstream Upstream,
+	policy *authz.Policy,
+	guard *guardrails.Guard,
+	auditLogger *audit.Logger,
+	logger *slog.Logger,
+) (*Proxy, error) {
+	p := &Proxy{
+		upstream:     upstream,
+		modbusConfig: modbusConfig,
+		policy:       policy,
+		guard:        guard,
+		auditLogger:  auditLogger,
+		cacheMaxAge:  proxyConfig.CacheMaxAge,
+		logger:       logger,
+		now:          time.Now,
+		cache:        make(map[cacheKey]cachedResponse),
+	}
+	for _, principal := range proxyConfig.Principals {
+		prefix, err := netip.ParsePrefix(principal.Network)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(principal.Network)
+			if addrErr != nil {
+				return nil, fmt.Errorf("principal %q has invalid network %q", principal.Principal, principal.Network)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p.principals = append(p.principals, principalNetwork{prefix: prefix.Masked(), principal: principal.Principal})
+	}
+	return p, nil
+}
+
+// principal returns the name of the principal of the master which sent the request, or an empty string if the master
+// is not identified.
+func (p *Proxy) principal(ctx context.Context) string {
+	remoteAddr, ok := modbusserver.RemoteAddr(ctx)
+	if !ok {
+		return ""
+	}
+	tcpAddr, ok := remoteAddr.(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	addr, ok := netip.AddrFromSlice(tcpAddr.IP)
+	if !ok {
+		return ""
+	}
+	addr = addr.Unmap()
+	for _, network := range p.principals {
+		if network.prefix.Contains(addr) {
+			return network.principal
+		}
+	}
+	return ""
+}
+
+// HandlePDU authorizes and checks the request, and forwards it to the upstream unless its response can be served from
+// the cache.
+func (p *Proxy) HandlePDU(
+	ctx context.Context,
+	unitID byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	op, exceptionCode := parse(request)
+	if exceptionCode != 0 {
+		return modbusserver.Exception(request, exceptionCode), nil
+	}
+	if slices.Index(p.modbusConfig.FunctionsSupported, op.function) == -1 {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalFunction), nil
+	}
+	// Masters commonly address a Modbus TCP server as unit 0 or 255, which is forwarded to the configured slave ID.
+	if unitID == 0 || unitID == 0xFF {
+		unitID = p.modbusConfig.SlaveID
+	}
+	if p.policy != nil {
+		principal := p.principal(ctx)
+		err := p.policy.Authorize(principal, authz.Access{Procedure: op.procedure, Device: unitID, Ranges: op.ranges})
+		var deniedErr *authz.DeniedError
+		if errors.As(err, &deniedErr) {
+			p.logger.Warn("proxied request denied", slog.String("error", err.Error()))
+			if deniedErr.Table != "" {
+				return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataAddress), nil
+			}
+			return modbusserver.Exception(request, modbus.ExceptionCodeIllegalFunction), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key := cacheKey{unitID: unitID, functionCode: request.FunctionCode, data: string(request.Data)}
+	if op.cacheable && p.cacheMaxAge > 0 {
+		if response, ok := p.cached(key); ok {
+			return response, nil
+		}
+	}
+	var current []uint32
+	if op.writeTable != "" {
+		current, exceptionCode = p.checkWrite(ctx, unitID, op)
+		if exceptionCode != 0 {
+			return modbusserver.Exception(request, exceptionCode), nil
+		}
+		if current == nil {
+			current = p.readBeforeWrite(ctx, unitID, op)
+		}
+	}
+
+	response, err := p.upstream.Forward(ctx, unitID, request)
+	if op.writeTable != "" {
+		p.recordWrite(ctx, op, current, request, response, err)
+	}
+	if err != nil {
+		p.logger.Warn("error forwarding proxied request", slog.String("error", err.Error()))
+		// The exception codes for gateways tell the master whether the modbus server could be reached, and a busy
+		// exception tells it to retry later when the rate limit of the modbus server has been exceeded.
+		switch connect.CodeOf(err) {
+		case connect.CodeUnavailable:
+			return modbusserver.Exception(request, modbus.ExceptionCodeGatewayPathUnavailable), nil
+		case connect.CodeResourceExhausted:
+			return modbusserver.Exception(request, modbus.ExceptionCodeServerDeviceBusy), nil
+		}
+		return modbusserver.Exception(request, modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond), nil
+	}
+	if op.writeTable != "" {
+		// Any write may change the values of cached reads of the unit, for example through a mirrored status register.
+		p.mu.Lock()
+		for cachedKey := range p.cache {
+			if cachedKey.unitID == unitID {
+				delete(p.cache, cachedKey)
+			}
+		}
+		p.mu.Unlock()
+	}
+	if op.cacheable && p.cacheMaxAge > 0 && response.FunctionCode == request.FunctionCode {
+		p.store(key, response)
+	}
+	return response, nil
+}
+
+// cached returns the cached response to the read, if it is no older than the max age. An older response is dropped.
+func (p *Proxy) cached(key cacheKey) (*modbus.ProtocolDataUnit, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cached, ok := p.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if p.now().Sub(cached.received) > p.cacheMaxAge {
+		delete(p.cache, key)
+		return nil, false
+	}
+	return cached.response, true
+}
+
+// store caches the response to the read. If the cache is full, the responses older than the max age are dropped first,
+// and the response is not cached if none were.
+func (p *Proxy) store(key cacheKey, response *modbus.ProtocolDataUnit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if _, ok := p.cache[key]; !ok && len(p.cache) >= maxCacheEntries {
+		for cachedKey, cached := range p.cache {
+			if now.Sub(cached.received) > p.cacheMaxAge {
+				delete(p.cache, cachedKey)
+			}
+		}
+		if len(p.cache) >= maxCacheEntries {
+			return
+		}
+	}
+	p.cache[key] = cachedResponse{response: response, received: now}
+}
+
+// checkWrite checks the write against the write guardrails, reading the current values first if a rule needs them.
+// It returns the current values if they were read, and an exception code if the write is not allowed.
+func (p *Proxy) checkWrite(ctx context.Context, unitID byte, op operation) ([]uint32, byte) {
+	if p.guard == nil {
+		return nil, 0
+	}
+	if op.masked {
+		// The value written by a mask write depends on the current value, so it must be read to check the rules.
+		if !p.guard.Covers(op.writeTable, op.writeAddress, 1) {
+			return nil, 0
+		}
+	} else if err := p.guard.CheckArm(op.writeTable, op.writeAddress, uint32(len(op.values))); err != nil {
+		p.logger.Warn("proxied write violates guardrails", slog.String("error", err.Error()))
+		return nil, modbus.ExceptionCodeIllegalDataValue
+	}
+
+	var current []uint32
+	quantity := uint32(len(op.values))
+	if op.masked {
+		quantity = 1
+	}
+	if op.masked || p.guard.NeedsCurrent(op.writeTable, op.writeAddress, quantity) {
+		var err error
+		current, err = p.readCurrent(ctx, unitID, op.writeTable, op.writeAddress, quantity)
+		if err != nil {
+			p.logger.Warn("error reading current values to check write rules", slog.String("error", err.Error()))
+			return nil, modbus.ExceptionCodeServerDeviceFailure
+		}
+	}
+	values := op.values
+	if op.masked {
+		if err := p.guard.CheckArm(op.writeTable, op.writeAddress, 1); err != nil {
+			p.logger.Warn("proxied write violates guardrails", slog.String("error", err.Error()))
+			return nil, modbus.ExceptionCodeIllegalDataValue
+		}
+		values = []uint32{maskedValue(op, current[0])}
+	}
+	if err := p.guard.Check(op.writeTable, op.writeAddress, values, current); err != nil {
+		p.logger.Warn("proxied write violates guardrails", slog.String("error", err.Error()))
+		return nil, modbus.ExceptionCodeIllegalDataValue
+	}
+	return current, 0
+}
+
+// maskedValue returns the value a mask write results in when the register has the current value.
+func maskedValue(op operation, current uint32) uint32 {
+	return uint32(audit.Mask{AndMask: op.andMask, OrMask: op.orMask}.Apply(uint16(current)))
+}
+
+// readBeforeWrite reads the values replaced by the write so that they can be recorded in the audit event. It returns
+// nil if auditing is disabled, reading before writes is not configured, the read function is not supported or the
+// read fails, as the values are only informational and must not prevent the write.
+func (p *Proxy) readBeforeWrite(ctx context.Context, unitID byte, op operation) []uint32 {
+	if p.auditLogger == nil || !p.auditLogger.ReadBeforeWrite() {
+		return nil
+	}
+	function := config.ReadHoldingRegisters
+	if op.writeTable == config.Coils {
+		function = config.ReadCoils
+	}
+	if slices.Index(p.modbusConfig.FunctionsSupported, function) == -1 {
+		return nil
+	}
+	quantity := uint32(len(op.values))
+	if op.masked {
+		quantity = 1
+	}
+	current, err := p.readCurrent(ctx, unitID, op.writeTable, op.writeAddress, quantity)
+	if err != nil {
+		return nil
+	}
+	return current
+}
+
+// recordWrite records an audit event for a forwarded write, if auditing is enabled. The procedure of the event is the
+// equivalent RPC method prefixed with "proxy/", and the write fails if forwarding it failed or the modbus server
+// responded with an exception.
+func (p *Proxy) recordWrite(
+	ctx context.Context,
+	op operation,
+	current []uint32,
+	request *modbus.ProtocolDataUnit,
+	response *modbus.ProtocolDataUnit,
+	err error,
+) {
+	if p.auditLogger == nil {
+		return
+	}
+	event := audit.Event{
+		Time:      time.Now().UTC(),
+		Principal: p.principal(ctx),
+		Procedure: "proxy/" + op.procedure,
+		Table:     op.writeTable,
+		Address:   op.writeAddress,
+		OldValues: current,
+		NewValues: op.values,
+	}
+	if event.Principal == "" {
+		event.Principal = "anonymous"
+	}
+	if remoteAddr, ok := modbusserver.RemoteAddr(ctx); ok {
+		event.Peer = remoteAddr.String()
+	}
+	if op.masked {
+		event.Mask = &audit.Mask{AndMask: op.andMask, OrMask: op.orMask}
+		if len(current) == 1 {
+			event.NewValues = []uint32{maskedValue(op, current[0])}
+		}
+	}
+	if err == nil && response.FunctionCode != request.FunctionCode {
+		modbusErr := &modbus.ModbusError{FunctionCode: request.FunctionCode}
+		if len(response.Data) > 0 {
+			modbusErr.ExceptionCode = response.Data[0]
+		}
+		err = modbusErr
+	}
+	event.Success = err == nil
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if recordErr := p.auditLogger.Record(event); recordErr != nil {
+		// The write has already been made, so failing the request would misreport its outcome to the master.
+		p.logger.Error("error recording audit event",
+			slog.String("procedure", event.Procedure),
+			slog.String("error", recordErr.Error()),
+		)
+	}
+}
+
+// readCurrent reads the current values of quantity coils or holding registers starting at address.
+func (p *Proxy) readCurrent(
+	ctx context.Context,
+	unitID byte,
+	table config.Table,
+	address uint32,
+	quantity uint32,
+) ([]uint32, error) {
+	functionCode := byte(modbus.FuncCodeReadHoldingRegisters)
+	if table == config.Coils {
+		functionCode = modbus.FuncCodeReadCoils
+	}
+	response, err := p.upstream.Forward(ctx, unitID, &modbus.ProtocolDataUnit{
+		FunctionCode: functionCode,
+		Data:         []byte{byte(address >> 8), byte(address), byte(quantity >> 8), byte(quantity)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.FunctionCode != functionCode || len(response.Data) < 1 || len(response.Data) != 1+int(response.Data[0]) {
+		return nil, fmt.Errorf("invalid response to reading %s", table)
+	}
+	values := make([]uint32, quantity)
+	for i := range values {
+		if table == config.Coils {
+			if int(1+i/8) >= len(response.Data) {
+				return nil, fmt.Errorf("invalid response to reading %s", table)
+			}
+			values[i] = uint32(response.Data[1+i/8]>>(i%8)) & 1
+			continue
+		}
+		if 2+2*i >= len(response.Data) {
+			return nil, fmt.Errorf("invalid response to reading %s", table)
+		}
+		values[i] = uint32(response.Data[1+2*i])<<8 | uint32(response.Data[2+2*i])
+	}
+	return values, nil
+}
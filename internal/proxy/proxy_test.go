@@ -0,0 +1,366 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/authz"
+	"modbustohttp/internal/guardrails"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+)
+
+var allFunctions = []config.ModbusFunction{
+	config.ReadCoils, config.ReadDiscreteInputs, config.ReadHoldingRegisters, config.ReadInputRegisters,
+	config.WriteSingleCoil, config.WriteMultipleCoils, config.WriteSingleRegister, config.WriteMultipleRegisters,
+	config.MaskWriteSingleRegister, config.ReadWriteMultipleRegisters, config.ReadFIFOQueue,
+	config.ReadDeviceIdentification,
+}
+
+// upstream forwards requests to a simulator, counting the requests forwarded.
+type upstream struct {
+	simulator *modbusserver.Simulator
+
+	mu      sync.Mutex
+	unitIDs []byte
+	err     error
+}
+
+func (u *upstream) Forward(
+	ctx context.Context,
+	unitID byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	u.mu.Lock()
+	u.unitIDs = append(u.unitIDs, unitID)
+	err := u.err
+	u.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return u.simulator.HandlePDU(ctx, unitID, request)
+}
+
+func (u *upstream) fail(err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.err = err
+}
+
+func (u *upstream) forwarded() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.unitIDs)
+}
+
+// startProxy serves a Proxy until the end of the test, and returns a function connecting clients to it.
+func startProxy(
+	t *testing.T,
+	proxyConfig *config.Proxy,
+	policy *authz.Policy,
+	guard *guardrails.Guard,
+	auditLogger *audit.Logger,
+) (func() modbus.Client, *upstream) {
+	t.Helper()
+	u := &upstream{simulator: modbusserver.NewSimulator()}
+	modbusConfig := &config.Modbus{SlaveID: 1, FunctionsSupported: allFunctions}
+	p, err := New(proxyConfig, modbusConfig, u, policy, guard, auditLogger, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := modbusserver.NewServer(p)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return func() modbus.Client {
+		clientHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+		clientHandler.Timeout = time.Second
+		t.Cleanup(func() { _ = clientHandler.Close() })
+		return modbus.NewClient(clientHandler)
+	}, u
+}
+
+func TestProxy_Forward(t *testing.T) {
+	dial, u := startProxy(t, &config.Proxy{}, nil, nil, nil)
+	u.simulator.SetHoldingRegisters(0, 1, 2, 3)
+
+	// Each master uses its own transaction IDs, which must be restored in the responses it receives.
+	var wg sync.WaitGroup
+	for range 4 {
+		client := dial()
+		wg.Go(func() {
+			for range 20 {
+				got, err := client.ReadHoldingRegisters(0, 3)
+				if err != nil || !slices.Equal(got, []byte{0, 1, 0, 2, 0, 3}) {
+					t.Errorf("ReadHoldingRegisters() = %v, %v, want [0 1 0 2 0 3], nil", got, err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	client := dial()
+	if _, err := client.WriteMultipleCoils(4, 3, []byte{0b101}); err != nil {
+		t.Fatalf("WriteMultipleCoils() error = %v", err)
+	}
+	if got := u.simulator.Coils(4, 3); !slices.Equal(got, []bool{true, false, true}) {
+		t.Errorf("Coils() = %v, want [true false true]", got)
+	}
+	// Unit ID 0 is forwarded to the configured slave ID.
+	if u.unitIDs[0] != 1 {
+		t.Errorf("forwarded unit ID = %d, want 1", u.unitIDs[0])
+	}
+}
+
+func TestProxy_Cache(t *testing.T) {
+	dial, u := startProxy(t, &config.Proxy{CacheMaxAge: time.Minute}, nil, nil, nil)
+	u.simulator.SetInputRegisters(0, 42)
+	first, second := dial(), dial()
+
+	for _, client := range []modbus.Client{first, second} {
+		if got, err := client.ReadInputRegisters(0, 1); err != nil || !slices.Equal(got, []byte{0, 42}) {
+			t.Errorf("ReadInputRegisters() = %v, %v, want [0 42], nil", got, err)
+		}
+	}
+	if got := u.forwarded(); got != 1 {
+		t.Errorf("forwarded = %d, want 1 as the second read is served from the cache", got)
+	}
+
+	if _, err := first.WriteSingleRegister(0, 1); err != nil {
+		t.Fatalf("WriteSingleRegister() error = %v", err)
+	}
+	if _, err := second.ReadInputRegisters(0, 1); err != nil {
+		t.Fatalf("ReadInputRegisters() error = %v", err)
+	}
+	if got := u.forwarded(); got != 3 {
+		t.Errorf("forwarded = %d, want 3 as the write clears the cache", got)
+	}
+}
+
+func TestProxy_CacheEviction(t *testing.T) {
+	u := &upstream{simulator: modbusserver.NewSimulator()}
+	modbusConfig := &config.Modbus{SlaveID: 1, FunctionsSupported: allFunctions}
+	p, err := New(&config.Proxy{CacheMaxAge: time.Minute}, modbusConfig, u, nil, nil, nil, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	read := func(address uint16) {
+		t.Helper()
+		request := &modbus.ProtocolDataUnit{
+			FunctionCode: modbus.FuncCodeReadInputRegisters,
+			Data:         []byte{byte(address >> 8), byte(address), 0, 1},
+		}
+		if response, err := p.HandlePDU(context.Background(), 1, request); err != nil || response.FunctionCode&0x80 != 0 {
+			t.Fatalf("HandlePDU() = %v, %v, want a response", response, err)
+		}
+	}
+
+	for address := range uint16(maxCacheEntries + 1) {
+		read(address)
+	}
+	if got := len(p.cache); got != maxCacheEntries {
+		t.Errorf("len(cache) = %d, want %d", got, maxCacheEntries)
+	}
+
+	// A stale response is dropped when it is looked up.
+	now = now.Add(2 * time.Minute)
+	read(0)
+	if got := u.forwarded(); got != maxCacheEntries+2 {
+		t.Errorf("forwarded = %d, want %d as the stale response is not served", got, maxCacheEntries+2)
+	}
+	// Once the cache is full, the stale responses are dropped to make room.
+	read(maxCacheEntries + 1)
+	if got := len(p.cache); got != 2 {
+		t.Errorf("len(cache) = %d after the stale responses were dropped, want 2", got)
+	}
+}
+
+func TestProxy_Authorization(t *testing.T) {
+	policy, err := authz.NewPolicy(&config.Authorization{
+		Enabled: true,
+		Roles: []config.Role{{
+			Name:          "reader",
+			Procedures:    []string{"ReadHoldingRegisters"},
+			AddressRanges: []config.AddressRange{{Table: config.HoldingRegisters, Start: 0, End: 9}},
+		}},
+		Grants: []config.Grant{{Principal: "plc", Roles: []string{"reader"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial, _ := startProxy(t, &config.Proxy{
+		Principals: []config.ProxyPrincipal{{Network: "127.0.0.0/8", Principal: "plc"}},
+	}, policy, nil, nil)
+	client := dial()
+	if _, err := client.ReadHoldingRegisters(0, 10); err != nil {
+		t.Errorf("ReadHoldingRegisters() error = %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(5, 10); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("ReadHoldingRegisters() outside the role error = %v, want illegal data address", err)
+	}
+	if _, err := client.WriteSingleRegister(0, 1); !isException(err, modbus.ExceptionCodeIllegalFunction) {
+		t.Errorf("WriteSingleRegister() error = %v, want illegal function", err)
+	}
+
+	dial, _ = startProxy(t, &config.Proxy{
+		Principals: []config.ProxyPrincipal{{Network: "10.0.0.1", Principal: "plc"}},
+	}, policy, nil, nil)
+	if _, err := dial().ReadHoldingRegisters(0, 1); !isException(err, modbus.ExceptionCodeIllegalFunction) {
+		t.Errorf("ReadHoldingRegisters() by an unidentified master error = %v, want illegal function", err)
+	}
+}
+
+func TestProxy_Guardrails(t *testing.T) {
+	maxValue, maxStep := uint16(100), uint16(10)
+	guard, err := guardrails.New(&config.Guardrails{
+		Enabled: true,
+		Rules: []config.WriteRule{
+			{Name: "setpoint", Table: config.HoldingRegisters, Start: 0, End: 9, Max: &maxValue},
+			{Name: "valve", Table: config.HoldingRegisters, Start: 10, End: 10, MaxStep: &maxStep},
+			{Name: "breaker", Table: config.Coils, Start: 0, End: 0, RequireArm: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial, u := startProxy(t, &config.Proxy{}, nil, guard, nil)
+	u.simulator.SetHoldingRegisters(10, 50)
+	client := dial()
+	if _, err := client.WriteMultipleRegisters(0, 2, []byte{0, 50, 0, 100}); err != nil {
+		t.Errorf("WriteMultipleRegisters() error = %v", err)
+	}
+	tests := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "Max", write: func() error { _, err := client.WriteSingleRegister(1, 101); return err }},
+		{name: "Mask", write: func() error { _, err := client.MaskWriteRegister(1, 0xFF00, 0x00FF); return err }},
+		{name: "Max step", write: func() error { _, err := client.WriteSingleRegister(10, 61); return err }},
+		{name: "Armed", write: func() error { _, err := client.WriteSingleCoil(0, 0xFF00); return err }},
+		{name: "Read write", write: func() error {
+			_, err := client.ReadWriteMultipleRegisters(0, 1, 9, 1, []byte{1, 0})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); !isException(err, modbus.ExceptionCodeIllegalDataValue) {
+				t.Errorf("write error = %v, want illegal data value", err)
+			}
+		})
+	}
+	if _, err := client.WriteSingleRegister(10, 60); err != nil {
+		t.Errorf("WriteSingleRegister() within the max step error = %v", err)
+	}
+	if got := u.simulator.HoldingRegisters(0, 11); !slices.Equal(got, []uint16{50, 100, 0, 0, 0, 0, 0, 0, 0, 0, 60}) {
+		t.Errorf("HoldingRegisters() = %v, want only the allowed writes", got)
+	}
+}
+
+func TestProxy_Audit(t *testing.T) {
+	auditLogger := audit.NewLogger(&config.Audit{
+		Path:            filepath.Join(t.TempDir(), "audit.jsonl"),
+		ReadBeforeWrite: true,
+	})
+	t.Cleanup(func() { _ = auditLogger.Close() })
+	dial, u := startProxy(t, &config.Proxy{
+		Principals: []config.ProxyPrincipal{{Network: "127.0.0.1", Principal: "plc"}},
+	}, nil, nil, auditLogger)
+	u.simulator.SetHoldingRegisters(5, 0x1234)
+	client := dial()
+
+	if _, err := client.WriteSingleRegister(5, 7); err != nil {
+		t.Fatalf("WriteSingleRegister() error = %v", err)
+	}
+	if _, err := client.MaskWriteRegister(5, 0xFFF0, 0x0003); err != nil {
+		t.Fatalf("MaskWriteRegister() error = %v", err)
+	}
+	if _, err := client.ReadHoldingRegisters(5, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	u.fail(connect.NewError(connect.CodeUnavailable, errors.New("connection refused")))
+	if _, err := client.WriteSingleCoil(3, 0xFF00); err == nil {
+		t.Fatal("WriteSingleCoil() error = nil, want gateway path unavailable")
+	}
+
+	events, err := auditLogger.List(audit.Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []audit.Event{
+		{Procedure: "proxy/WriteSingleCoil", Table: config.Coils, Address: 3, NewValues: []uint32{1}},
+		{
+			Procedure: "proxy/MaskWriteRegister", Table: config.HoldingRegisters, Address: 5,
+			OldValues: []uint32{7}, NewValues: []uint32{3}, Mask: &audit.Mask{AndMask: 0xFFF0, OrMask: 0x0003},
+			Success: true,
+		},
+		{
+			Procedure: "proxy/WriteSingleRegister", Table: config.HoldingRegisters, Address: 5,
+			OldValues: []uint32{0x1234}, NewValues: []uint32{7}, Success: true,
+		},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("List() returned %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Principal != "plc" || event.Peer == "" || event.Procedure != want[i].Procedure ||
+			event.Table != want[i].Table || event.Address != want[i].Address ||
+			!slices.Equal(event.OldValues, want[i].OldValues) || !slices.Equal(event.NewValues, want[i].NewValues) ||
+			(event.Mask == nil) != (want[i].Mask == nil) || (event.Mask != nil && *event.Mask != *want[i].Mask) ||
+			event.Success != want[i].Success {
+			t.Errorf("List() event %d = %+v, want %+v from plc", i, event, want[i])
+		}
+	}
+	if events[0].Error == "" {
+		t.Error("List() event 0 has no error, want the forwarding error")
+	}
+}
+
+func TestProxy_Unavailable(t *testing.T) {
+	dial, u := startProxy(t, &config.Proxy{}, nil, nil, nil)
+	client := dial()
+	u.fail(connect.NewError(connect.CodeUnavailable, errors.New("connection refused")))
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeGatewayPathUnavailable) {
+		t.Errorf("ReadCoils() error = %v, want gateway path unavailable", err)
+	}
+	u.fail(connect.NewError(connect.CodeDeadlineExceeded, errors.New("timeout")))
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond) {
+		t.Errorf("ReadCoils() error = %v, want gateway target device failed to respond", err)
+	}
+	u.fail(connect.NewError(connect.CodeResourceExhausted, errors.New("rate limit exceeded")))
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeServerDeviceBusy) {
+		t.Errorf("ReadCoils() error = %v, want server device busy", err)
+	}
+}
+
+func TestNew_InvalidPrincipal(t *testing.T) {
+	_, err := New(&config.Proxy{Principals: []config.ProxyPrincipal{{Network: "10.0.0.0/33", Principal: "plc"}}},
+		&config.Modbus{}, nil, nil, nil, nil, slog.Default(),
+	)
+	if err == nil {
+		t.Error("New() error = nil, want error")
+	}
+}
+
+func isException(err error, exceptionCode byte) bool {
+	var modbusErr *modbus.ModbusError
+	return errors.As(err, &modbusErr) && modbusErr.ExceptionCode == exceptionCode
+}
@@ -0,0 +1,159 @@
+// Package ratelimit limits the rate of requests with token buckets, so that devices which cannot keep up are not
+// polled too fast and a single client cannot starve the others.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"sync"
+	"time"
+)
+
+// ExceededError is returned by Wait when no token will be available before the request must give up waiting.
+type ExceededError struct {
+	// RetryAfter is how long until a token would have been available
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %v", e.RetryAfter)
+}
+
+// Limiter is a token bucket which is refilled at the rate and holds up to the burst of tokens. Each request takes a
+// token, and requests made while the bucket is empty wait for a token in the order they were made.
+type Limiter struct {
+	rate    float64
+	burst   float64
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// tokens is the number of tokens in the bucket when it was last refilled. It is negative while requests are
+	// waiting, as each has reserved the token it will take.
+	tokens float64
+	// refilledAt is when the tokens were last refilled
+	refilledAt time.Time
+}
+
+// New returns a full Limiter. The rate must be greater than zero.
+func New(rateLimit *config.RateLimit) *Limiter {
+	burst := rateLimit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    rateLimit.Rate,
+		burst:   float64(burst),
+		maxWait: rateLimit.MaxWait,
+		now:     time.Now,
+		tokens:  float64(burst),
+	}
+}
+
+// Wait takes a token, waiting until one is available if the bucket is empty. Requests wait for at most the max wait,
+// or until the deadline of ctx if it is sooner. If a token will not be available by then, Wait returns an
+// *ExceededError at once without taking a token. If ctx is done while waiting, its error is returned.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	l.refill(now)
+	wait := time.Duration(0)
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	deadline := now.Add(l.maxWait)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if wait > 0 && now.Add(wait).After(deadline) {
+		l.mu.Unlock()
+		return &ExceededError{RetryAfter: wait}
+	}
+	l.tokens--
+	l.mu.Unlock()
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// The reserved token is returned, so that the requests waiting behind this one are not delayed by it.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// full returns whether the bucket is full at the given time, in which case the Limiter is the same as a new one.
+func (l *Limiter) full(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(now)
+	return l.tokens >= l.burst
+}
+
+// refill adds the tokens accrued since the last refill. It must be called with the mutex held.
+func (l *Limiter) refill(now time.Time) {
+	if !l.refilledAt.IsZero() && now.After(l.refilledAt) {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.refilledAt).Seconds()*l.rate)
+	}
+	l.refilledAt = now
+}
+
+// minSweep is the number of keys a Keyed holds before it first drops the Limiters of idle keys.
+const minSweep = 1024
+
+// Keyed holds a Limiter for each key, such as a principal or a client IP address. The Limiters of keys which have been
+// idle long enough for their buckets to refill are dropped as keys are added, so that they do not accumulate.
+type Keyed struct {
+	rateLimit config.RateLimit
+	now       func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+	// sweepAt is the number of keys at which idle keys are next dropped
+	sweepAt int
+}
+
+// NewKeyed returns a Keyed whose Limiters all have the rate limit. The rate must be greater than zero.
+func NewKeyed(rateLimit *config.RateLimit) *Keyed {
+	return &Keyed{
+		rateLimit: *rateLimit,
+		now:       time.Now,
+		limiters:  make(map[string]*Limiter),
+		sweepAt:   minSweep,
+	}
+}
+
+// Wait takes a token from the Limiter of the key, as Limiter.Wait does.
+func (k *Keyed) Wait(ctx context.Context, key string) error {
+	k.mu.Lock()
+	limiter, ok := k.limiters[key]
+	if !ok {
+		if len(k.limiters) >= k.sweepAt {
+			k.sweep()
+		}
+		limiter = New(&k.rateLimit)
+		limiter.now = k.now
+		k.limiters[key] = limiter
+	}
+	k.mu.Unlock()
+	return limiter.Wait(ctx)
+}
+
+// sweep drops the Limiters whose buckets are full. It must be called with the mutex held.
+func (k *Keyed) sweep() {
+	now := k.now()
+	for key, limiter := range k.limiters {
+		if limiter.full(now) {
+			delete(k.limiters, key)
+		}
+	}
+	k.sweepAt = max(minSweep, 2*len(k.limiters))
+}
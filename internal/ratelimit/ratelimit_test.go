@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"modbustohttp/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := New(&config.RateLimit{Rate: 10, Burst: 2})
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() within the burst error = %v", err)
+		}
+	}
+	var exceededErr *ExceededError
+	if err := limiter.Wait(ctx); !errors.As(err, &exceededErr) || exceededErr.RetryAfter != 100*time.Millisecond {
+		t.Fatalf("Wait() with no max wait = %v, want retry after 100ms", err)
+	}
+
+	now = now.Add(50 * time.Millisecond)
+	if err := limiter.Wait(ctx); !errors.As(err, &exceededErr) || exceededErr.RetryAfter != 50*time.Millisecond {
+		t.Errorf("Wait() after half a token = %v, want retry after 50ms", err)
+	}
+	now = now.Add(time.Second)
+	for range 2 {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Errorf("Wait() once the bucket refilled error = %v", err)
+		}
+	}
+}
+
+func TestLimiter_Queue(t *testing.T) {
+	limiter := New(&config.RateLimit{Rate: 50, Burst: 1, MaxWait: time.Second})
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	// The first token is taken from the full bucket, and the others are refilled every 20ms.
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 tokens taken in %v, want at least 40ms", elapsed)
+	}
+
+	// A request whose deadline passes before its token is available fails at once.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	var exceededErr *ExceededError
+	if err := limiter.Wait(deadlineCtx); !errors.As(err, &exceededErr) {
+		t.Errorf("Wait() with a short deadline error = %v, want rate limit exceeded", err)
+	}
+
+	// A request which is cancelled while waiting returns its token.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(5*time.Millisecond, cancel)
+	if err := limiter.Wait(cancelCtx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() cancelled error = %v, want %v", err, context.Canceled)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait() took %v, want the cancelled token returned", elapsed)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyed := NewKeyed(&config.RateLimit{Rate: 1, Burst: 1})
+	keyed.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := keyed.Wait(ctx, "alice"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := keyed.Wait(ctx, "bob"); err != nil {
+		t.Errorf("Wait() for another key error = %v", err)
+	}
+	var exceededErr *ExceededError
+	if err := keyed.Wait(ctx, "alice"); !errors.As(err, &exceededErr) {
+		t.Errorf("Wait() over the limit error = %v, want rate limit exceeded", err)
+	}
+
+	for i := range minSweep - 2 {
+		_ = keyed.Wait(ctx, string(rune(i+1000)))
+	}
+	now = now.Add(time.Second)
+	_ = keyed.Wait(ctx, "carol")
+	if got := len(keyed.limiters); got != 1 {
+		t.Errorf("len(limiters) = %d after the idle keys were swept, want 1", got)
+	}
+}
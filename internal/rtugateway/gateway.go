@@ -0,0 +1,208 @@
+// Package rtugateway forwards the requests of Modbus TCP masters to the slaves on an RTU serial bus, so that the bridge
+// can replace a dedicated gateway.
+package rtugateway
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"sync"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/goburrow/serial"
+)
+
+var (
+	// errPathUnavailable is returned if the request could not be sent on the bus.
+	errPathUnavailable = errors.New("rtugateway: path unavailable")
+	// errTargetFailed is returned if the slave did not send a valid response in time.
+	errTargetFailed = errors.New("rtugateway: target device failed to respond")
+)
+
+// Gateway is a modbusserver.Handler which forwards requests to the RTU slave whose address is the unit ID. Only one
+// request is on the bus at a time, and each request is sent after the silent interval which ends the previous frame.
+type Gateway struct {
+	port            io.ReadWriter
+	baudRate        int
+	responseTimeout time.Duration
+	logger          *slog.Logger
+
+	mu sync.Mutex
+	// idleAt is when the silent interval after the last frame on the bus ends.
+	idleAt time.Time
+	// stale is set after a transaction fails, as the slave may still be sending a late or corrupted response.
+	stale bool
+}
+
+// Open opens the serial port of the RTU bus.
+func Open(gatewayConfig *config.Gateway) (serial.Port, error) {
+	port, err := serial.Open(&serial.Config{
+		Address:  gatewayConfig.Device,
+		BaudRate: gatewayConfig.BaudRate,
+		DataBits: gatewayConfig.DataBits,
+		StopBits: gatewayConfig.StopBits,
+		Parity:   gatewayConfig.Parity,
+		Timeout:  responseTimeout(gatewayConfig),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening serial port %s: %w", gatewayConfig.Device, err)
+	}
+	return port, nil
+}
+
+// responseTimeout returns the configured response timeout, or 1 second if it is not set.
+func responseTimeout(gatewayConfig *config.Gateway) time.Duration {
+	if gatewayConfig.ResponseTimeout == 0 {
+		return time.Second
+	}
+	return gatewayConfig.ResponseTimeout
+}
+
+// New returns a Gateway which sends requests on the port. Reads from the port should return an error once no bytes
+// have been received for a while, as reads from a serial.Port return serial.ErrTimeout.
+func New(gatewayConfig *config.Gateway, port io.ReadWriter, logger *slog.Logger) *Gateway {
+	baudRate := gatewayConfig.BaudRate
+	if baudRate == 0 {
+		baudRate = 19200
+	}
+	return &Gateway{
+		port:            port,
+		baudRate:        baudRate,
+		responseTimeout: responseTimeout(gatewayConfig),
+		logger:          logger,
+	}
+}
+
+// HandlePDU sends the request to the slave whose address is the unit ID, and returns its response. If the slave does
+// not respond in time, or its response is corrupted, a gateway target device failed to respond exception is returned.
+func (g *Gateway) HandlePDU(
+	ctx context.Context,
+	unitID byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	// Address 0 is the broadcast address, which slaves never respond to, and 248 to 255 are reserved, so there is no
+	// slave to forward the request to.
+	if unitID == 0 || unitID > 247 {
+		return modbusserver.Exception(request, modbus.ExceptionCodeGatewayPathUnavailable), nil
+	}
+	// The gateway must know the length of the response to find its end, so only the public function codes are
+	// forwarded.
+	if _, err := frameLength(nil, []byte{unitID, request.FunctionCode}); err != nil || request.FunctionCode&0x80 != 0 {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalFunction), nil
+	}
+	frame := encode(unitID, request)
+	if len(frame) > maxFrameLength {
+		return modbusserver.Exception(request, modbus.ExceptionCodeIllegalDataValue), nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	response, err := g.transact(frame)
+	if err != nil {
+		g.stale = true
+		g.logger.Warn("error forwarding request to RTU slave",
+			slog.Int("unit_id", int(unitID)),
+			slog.String("error", err.Error()),
+		)
+		if errors.Is(err, errPathUnavailable) {
+			return modbusserver.Exception(request, modbus.ExceptionCodeGatewayPathUnavailable), nil
+		}
+		return modbusserver.Exception(request, modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond), nil
+	}
+	if response == nil {
+		// The slave does not respond once it is forced into listen only mode, but the master expects a response, so it
+		// is sent the echo the slave would have sent.
+		return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: request.Data}, nil
+	}
+	if response[1]&^0x80 != request.FunctionCode {
+		g.logger.Warn("RTU slave responded with a different function code",
+			slog.Int("unit_id", int(unitID)),
+			slog.Int("function_code", int(response[1])),
+		)
+		return modbusserver.Exception(request, modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond), nil
+	}
+	return &modbus.ProtocolDataUnit{FunctionCode: response[1], Data: response[2 : len(response)-2]}, nil
+}
+
+// transact sends the request frame and returns the response frame once its CRC has been verified, or nil if the slave
+// does not respond to the request. It must be called with the mutex held.
+func (g *Gateway) transact(request []byte) ([]byte, error) {
+	if g.stale {
+		g.drain()
+		g.stale = false
+	}
+	// Slaves detect the start of a frame by the silence before it, so the request must not be sent until the silent
+	// interval after the last frame has passed.
+	if wait := time.Until(g.idleAt); wait > 0 {
+		time.Sleep(wait)
+	}
+	defer func() { g.idleAt = time.Now().Add(silence(g.baudRate)) }()
+	if _, err := g.port.Write(request); err != nil {
+		return nil, fmt.Errorf("%w: %w", errPathUnavailable, err)
+	}
+	if !respondsTo(request) {
+		return nil, nil
+	}
+
+	// The write returns before the request has been sent, so the time taken to send it is added to the timeout.
+	deadline := time.Now().Add(time.Duration(len(request))*characterTime(g.baudRate) + g.responseTimeout)
+	response := make([]byte, 0, maxFrameLength)
+	buf := make([]byte, maxFrameLength)
+	for {
+		length, err := frameLength(request, response)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errTargetFailed, err)
+		}
+		if length > maxFrameLength {
+			return nil, fmt.Errorf("%w: response of %d bytes is too long", errTargetFailed, length)
+		}
+		if length != 0 && len(response) >= length {
+			response = response[:length]
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: timed out after %d bytes", errTargetFailed, len(response))
+		}
+		n, err := g.port.Read(buf[:maxFrameLength-len(response)])
+		response = append(response, buf[:n]...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w after %d bytes", errTargetFailed, err, len(response))
+		}
+	}
+
+	if response[0] != request[0] {
+		return nil, fmt.Errorf("%w: response from slave %d", errTargetFailed, response[0])
+	}
+	if got, want := binary.LittleEndian.Uint16(response[len(response)-2:]), crc(response[:len(response)-2]); got != want {
+		return nil, fmt.Errorf("%w: response CRC %04x does not match %04x", errTargetFailed, got, want)
+	}
+	return response, nil
+}
+
+// drain discards the bytes received until a read from the port times out, so that the rest of a response which
+// arrived after its transaction failed is not read as the response to the next request. It gives up after the
+// response timeout if the bus is never silent. It must be called with the mutex held.
+func (g *Gateway) drain() {
+	deadline := time.Now().Add(g.responseTimeout)
+	buf := make([]byte, maxFrameLength)
+	discarded := 0
+	for time.Now().Before(deadline) {
+		n, err := g.port.Read(buf)
+		discarded += n
+		if err != nil || n == 0 {
+			break
+		}
+	}
+	if discarded > 0 {
+		g.logger.Warn("discarded late bytes from RTU bus", slog.Int("bytes", discarded))
+	}
+}
@@ -0,0 +1,265 @@
+package rtugateway
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/goburrow/serial"
+)
+
+// bus is an RTU serial bus with a single simulated slave. Responses are read a few bytes at a time, as they would be
+// from a serial port.
+type bus struct {
+	simulator *modbusserver.Simulator
+	address   byte
+
+	mu      sync.Mutex
+	pending []byte
+	// gaps are the times between the end of each response and the next request.
+	gaps     []time.Duration
+	lastRead time.Time
+	corrupt  bool
+	// late withholds each response from the first read after the request, so that it arrives after the gateway has
+	// given up on it.
+	late     bool
+	withheld bool
+}
+
+func (b *bus) Write(frame []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.lastRead.IsZero() {
+		b.gaps = append(b.gaps, time.Since(b.lastRead))
+	}
+	if frame[0] != b.address || binary.LittleEndian.Uint16(frame[len(frame)-2:]) != crc(frame[:len(frame)-2]) {
+		return len(frame), nil
+	}
+	// A slave forced into listen only mode does not respond.
+	if frame[1] == funcCodeDiagnostics && binary.BigEndian.Uint16(frame[2:]) == diagnosticForceListenOnly {
+		return len(frame), nil
+	}
+	response, err := b.simulator.HandlePDU(context.Background(), b.address, &modbus.ProtocolDataUnit{
+		FunctionCode: frame[1],
+		Data:         frame[2 : len(frame)-2],
+	})
+	if err != nil {
+		return len(frame), nil
+	}
+	responseFrame := encode(b.address, response)
+	if b.corrupt {
+		responseFrame[len(responseFrame)-1]++
+	}
+	// Bytes which were not read are still in the input, after which the response is received.
+	b.pending = append(b.pending, responseFrame...)
+	b.withheld = b.late
+	return len(frame), nil
+}
+
+func (b *bus) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.pending) == 0 || b.withheld {
+		b.withheld = false
+		return 0, serial.ErrTimeout
+	}
+	n := copy(p[:min(len(p), 3)], b.pending)
+	b.pending = b.pending[n:]
+	b.lastRead = time.Now()
+	return n, nil
+}
+
+// startGateway serves a Gateway to the bus until the end of the test, and returns a function connecting clients to it.
+func startGateway(t *testing.T, b *bus, baudRate int) func(unitID byte) *modbus.TCPClientHandler {
+	t.Helper()
+	gateway := New(&config.Gateway{BaudRate: baudRate, ResponseTimeout: 50 * time.Millisecond}, b,
+		slog.New(slog.DiscardHandler),
+	)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := modbusserver.NewServer(gateway)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return func(unitID byte) *modbus.TCPClientHandler {
+		clientHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+		clientHandler.SlaveId = unitID
+		clientHandler.Timeout = time.Second
+		t.Cleanup(func() { _ = clientHandler.Close() })
+		return clientHandler
+	}
+}
+
+func TestGateway(t *testing.T) {
+	b := &bus{simulator: modbusserver.NewSimulator(), address: 7}
+	b.simulator.SetHoldingRegisters(0, 1, 2, 3)
+	dial := startGateway(t, b, 19200)
+	client := modbus.NewClient(dial(7))
+
+	if got, err := client.ReadHoldingRegisters(0, 3); err != nil || !slices.Equal(got, []byte{0, 1, 0, 2, 0, 3}) {
+		t.Errorf("ReadHoldingRegisters() = %v, %v, want [0 1 0 2 0 3], nil", got, err)
+	}
+	if _, err := client.WriteMultipleCoils(4, 3, []byte{0b101}); err != nil {
+		t.Errorf("WriteMultipleCoils() error = %v", err)
+	}
+	if got := b.simulator.Coils(4, 3); !slices.Equal(got, []bool{true, false, true}) {
+		t.Errorf("Coils() = %v, want [true false true]", got)
+	}
+	if _, err := client.ReadHoldingRegisters(65535, 2); !isException(err, modbus.ExceptionCodeIllegalDataAddress) {
+		t.Errorf("ReadHoldingRegisters() past the end error = %v, want illegal data address", err)
+	}
+
+	if _, err := modbus.NewClient(dial(8)).ReadCoils(0, 1); !isException(err,
+		modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond,
+	) {
+		t.Errorf("ReadCoils() from a missing slave error = %v, want gateway target device failed to respond", err)
+	}
+	if _, err := modbus.NewClient(dial(0)).WriteSingleCoil(0, 0xFF00); !isException(err,
+		modbus.ExceptionCodeGatewayPathUnavailable,
+	) {
+		t.Errorf("WriteSingleCoil() to the broadcast address error = %v, want gateway path unavailable", err)
+	}
+	b.mu.Lock()
+	b.corrupt = true
+	b.mu.Unlock()
+	if _, err := client.ReadCoils(0, 1); !isException(err, modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond) {
+		t.Errorf("ReadCoils() with a corrupted response error = %v, want gateway target device failed to respond", err)
+	}
+}
+
+func TestGateway_LateResponse(t *testing.T) {
+	b := &bus{simulator: modbusserver.NewSimulator(), address: 3, late: true}
+	b.simulator.SetHoldingRegisters(0, 1, 2)
+	client := modbus.NewClient(startGateway(t, b, 19200)(3))
+
+	if _, err := client.ReadHoldingRegisters(0, 1); !isException(err,
+		modbus.ExceptionCodeGatewayTargetDeviceFailedToRespond,
+	) {
+		t.Fatalf("ReadHoldingRegisters() with a late response error = %v, want gateway target device failed to respond", err)
+	}
+	b.mu.Lock()
+	b.late = false
+	b.mu.Unlock()
+	// The late response to the first request is from the same slave, so it must be discarded rather than returned.
+	if got, err := client.ReadHoldingRegisters(1, 1); err != nil || !slices.Equal(got, []byte{0, 2}) {
+		t.Errorf("ReadHoldingRegisters() after a late response = %v, %v, want [0 2], nil", got, err)
+	}
+}
+
+func TestGateway_Diagnostics(t *testing.T) {
+	b := &bus{simulator: modbusserver.NewSimulator(), address: 2}
+	handler := startGateway(t, b, 19200)(2)
+
+	// Return Query Data echoes any amount of data, so the length of its response is only known from the request.
+	for _, data := range [][]byte{{0xAB}, {1, 2}, {1, 2, 3, 4, 5, 6, 7}} {
+		request := &modbus.ProtocolDataUnit{FunctionCode: funcCodeDiagnostics, Data: append([]byte{0, 0}, data...)}
+		if response, err := sendPDU(handler, request); err != nil || !slices.Equal(response.Data, request.Data) {
+			t.Errorf("ReturnQueryData(% x) = %v, %v, want an echo", data, response, err)
+		}
+	}
+
+	// The slave does not respond once it is forced into listen only mode, so the gateway does not wait for it.
+	start := time.Now()
+	request := &modbus.ProtocolDataUnit{FunctionCode: funcCodeDiagnostics, Data: []byte{0, 4, 0, 0}}
+	if response, err := sendPDU(handler, request); err != nil || !slices.Equal(response.Data, request.Data) {
+		t.Errorf("ForceListenOnly() = %v, %v, want an echo", response, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("ForceListenOnly() took %v, want less than the response timeout", elapsed)
+	}
+}
+
+// sendPDU sends the request PDU with the handler and returns the response PDU.
+func sendPDU(handler *modbus.TCPClientHandler, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error) {
+	aduRequest, err := handler.Encode(request)
+	if err != nil {
+		return nil, err
+	}
+	aduResponse, err := handler.Send(aduRequest)
+	if err != nil {
+		return nil, err
+	}
+	return handler.Decode(aduResponse)
+}
+
+func TestGateway_Silence(t *testing.T) {
+	b := &bus{simulator: modbusserver.NewSimulator(), address: 1}
+	dial := startGateway(t, b, 9600)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		client := modbus.NewClient(dial(1))
+		wg.Go(func() {
+			for range 5 {
+				if _, err := client.ReadInputRegisters(0, 10); err != nil {
+					t.Errorf("ReadInputRegisters() error = %v", err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.gaps) != 14 {
+		t.Errorf("len(gaps) = %d, want 14", len(b.gaps))
+	}
+	for _, gap := range b.gaps {
+		if gap < silence(9600) {
+			t.Errorf("request sent %v after the last response, want at least %v", gap, silence(9600))
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got := encode(1, &modbus.ProtocolDataUnit{FunctionCode: 3, Data: []byte{0, 0, 0, 10}})
+	if want := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}; !slices.Equal(got, want) {
+		t.Errorf("encode() = % x, want % x", got, want)
+	}
+}
+
+func TestFrameLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		request []byte
+		frame   []byte
+		want    int
+		wantErr bool
+	}{
+		{name: "Empty", frame: nil, want: 0},
+		{name: "Exception", frame: []byte{1, 0x83}, want: 5},
+		{name: "Byte count", frame: []byte{1, 0x03, 6}, want: 11},
+		{name: "Byte count not received", frame: []byte{1, 0x03}, want: 0},
+		{name: "Write", frame: []byte{1, 0x10}, want: 8},
+		{name: "Mask write", frame: []byte{1, 0x16}, want: 10},
+		{name: "Diagnostics", request: []byte{1, 0x08, 0, 0, 1, 2, 3, 0xC0, 0xDE}, frame: []byte{1, 0x08}, want: 9},
+		{name: "FIFO", frame: []byte{1, 0x18, 0, 6}, want: 12},
+		{name: "Device identification", frame: []byte{1, 0x2B, 0x0E, 1, 1, 0, 0, 2, 0, 3, 'a', 'b', 'c', 1, 1}, want: 18},
+		{name: "Device identification object not received", frame: []byte{1, 0x2B, 0x0E, 1, 1, 0, 0, 2, 0, 3}, want: 0},
+		{name: "Unknown", frame: []byte{1, 0x41}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := frameLength(tt.request, tt.frame)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("frameLength() = %d, %v, want %d, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func isException(err error, exceptionCode byte) bool {
+	var modbusErr *modbus.ModbusError
+	return errors.As(err, &modbusErr) && modbusErr.ExceptionCode == exceptionCode
+}
@@ -0,0 +1,132 @@
+package rtugateway
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+const (
+	// maxFrameLength is the largest RTU frame, including the slave address and CRC.
+	maxFrameLength = 256
+	// bitsPerCharacter is the number of bits sent for each byte: a start bit, 8 data bits, a parity or second stop bit,
+	// and a stop bit.
+	bitsPerCharacter = 11
+
+	funcCodeDiagnostics           = 0x08
+	funcCodeGetCommEventCounter   = 0x0B
+	funcCodeGetCommEventLog       = 0x0C
+	funcCodeReportServerID        = 0x11
+	funcCodeReadFileRecord        = 0x14
+	funcCodeWriteFileRecord       = 0x15
+	funcCodeEncapsulatedInterface = 0x2B
+
+	// diagnosticForceListenOnly is the Diagnostics sub-function which makes the slave stop responding until it is
+	// restarted.
+	diagnosticForceListenOnly = 0x0004
+)
+
+// errUnknownLength is returned by frameLength if the length of responses to the function code cannot be determined.
+var errUnknownLength = errors.New("rtugateway: unknown response length")
+
+// crc returns the Modbus CRC-16 of the data.
+func crc(data []byte) uint16 {
+	value := uint16(0xFFFF)
+	for _, b := range data {
+		value ^= uint16(b)
+		for range 8 {
+			if value&1 != 0 {
+				value = value>>1 ^ 0xA001
+			} else {
+				value >>= 1
+			}
+		}
+	}
+	return value
+}
+
+// encode returns the RTU frame of the request PDU sent to the slave address.
+func encode(address byte, pdu *modbus.ProtocolDataUnit) []byte {
+	frame := make([]byte, 0, len(pdu.Data)+4)
+	frame = append(frame, address, pdu.FunctionCode)
+	frame = append(frame, pdu.Data...)
+	// The CRC is sent low byte first, unlike the rest of the protocol.
+	return binary.LittleEndian.AppendUint16(frame, crc(frame))
+}
+
+// characterTime returns how long it takes to send a character at the baud rate.
+func characterTime(baudRate int) time.Duration {
+	return time.Duration(bitsPerCharacter) * time.Second / time.Duration(baudRate)
+}
+
+// silence returns the silent interval of 3.5 character times which separates frames at the baud rate. Above 19200 baud
+// a fixed interval of 1.75ms is used, as recommended by the serial line specification.
+func silence(baudRate int) time.Duration {
+	if baudRate > 19200 {
+		return 1750 * time.Microsecond
+	}
+	return characterTime(baudRate) * 7 / 2
+}
+
+// respondsTo returns whether the slave responds to the request frame. A slave forced into listen only mode does not
+// respond to the request which forced it.
+func respondsTo(request []byte) bool {
+	return len(request) < 6 || request[1] != funcCodeDiagnostics ||
+		binary.BigEndian.Uint16(request[2:]) != diagnosticForceListenOnly
+}
+
+// frameLength returns the length of the response frame to the request frame which starts with the bytes received so
+// far, or zero if more bytes must be received to know it. RTU frames are delimited by silence, so the length is
+// determined from the request, function code and byte counts instead, which does not depend on the timing of the
+// serial port.
+func frameLength(request []byte, frame []byte) (int, error) {
+	if len(frame) < 2 {
+		return 0, nil
+	}
+	functionCode := frame[1]
+	if functionCode&0x80 != 0 {
+		// Slave address, function code, exception code and CRC
+		return 5, nil
+	}
+	switch functionCode {
+	case modbus.FuncCodeReadCoils, modbus.FuncCodeReadDiscreteInputs, modbus.FuncCodeReadHoldingRegisters,
+		modbus.FuncCodeReadInputRegisters, modbus.FuncCodeReadWriteMultipleRegisters, funcCodeGetCommEventLog,
+		funcCodeReportServerID, funcCodeReadFileRecord, funcCodeWriteFileRecord:
+		if len(frame) < 3 {
+			return 0, nil
+		}
+		return 5 + int(frame[2]), nil
+	case modbus.FuncCodeWriteSingleCoil, modbus.FuncCodeWriteSingleRegister, modbus.FuncCodeWriteMultipleCoils,
+		modbus.FuncCodeWriteMultipleRegisters, funcCodeGetCommEventCounter:
+		return 8, nil
+	case funcCodeDiagnostics:
+		// Diagnostics responses echo the request, or replace its data with a counter or register of the same length.
+		return len(request), nil
+	case modbus.FuncCodeMaskWriteRegister:
+		return 10, nil
+	case modbus.FuncCodeReadFIFOQueue:
+		if len(frame) < 4 {
+			return 0, nil
+		}
+		return 6 + int(binary.BigEndian.Uint16(frame[2:])), nil
+	case funcCodeEncapsulatedInterface:
+		// The objects of a device identification response each have an ID and a length, following a header of the MEI
+		// type, read device ID code, conformity level, more follows, next object ID and number of objects.
+		const headerLength = 8
+		if len(frame) < headerLength {
+			return 0, nil
+		}
+		length := headerLength
+		for range frame[headerLength-1] {
+			if len(frame) < length+2 {
+				return 0, nil
+			}
+			length += 2 + int(frame[length+1])
+		}
+		return length + 2, nil
+	default:
+		return 0, errUnknownLength
+	}
+}
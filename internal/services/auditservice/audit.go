@@ -0,0 +1,62 @@
+package auditservice
+
+import (
+	"context"
+	"errors"
+	"modbustohttp/internal/audit"
+
+	"connectrpc.com/connect"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// defaultLimit is the number of events listed if the request does not specify a limit.
+const defaultLimit = 100
+
+type Service struct {
+	// auditLogger is the audit log events are listed from. It is nil if auditing is disabled.
+	auditLogger *audit.Logger
+}
+
+func (s Service) ListAuditEvents(
+	_ context.Context,
+	req *connect.Request[modbusv1alpha1.ListAuditEventsRequest],
+) (*connect.Response[modbusv1alpha1.ListAuditEventsResponse], error) {
+	if s.auditLogger == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("auditing is not enabled"))
+	}
+	filter := audit.Filter{
+		Principal:    req.Msg.GetPrincipal(),
+		Table:        MapTableToConfig(req.Msg.GetTable()),
+		StartAddress: req.Msg.GetStartAddress(),
+		EndAddress:   req.Msg.EndAddress,
+		Limit:        defaultLimit,
+	}
+	if req.Msg.GetSince() != nil {
+		filter.Since = req.Msg.GetSince().AsTime()
+	}
+	if req.Msg.GetUntil() != nil {
+		filter.Until = req.Msg.GetUntil().AsTime()
+	}
+	if req.Msg.Success != nil {
+		success := req.Msg.GetSuccess()
+		filter.Success = &success
+	}
+	if req.Msg.Limit != nil {
+		filter.Limit = int(req.Msg.GetLimit())
+	}
+
+	events, err := s.auditLogger.List(filter)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	auditEvents := make([]*modbusv1alpha1.AuditEvent, len(events))
+	for i, event := range events {
+		auditEvents[i] = MapEventToAuditEvent(event)
+	}
+	return connect.NewResponse(&modbusv1alpha1.ListAuditEventsResponse{Events: auditEvents}), nil
+}
+
+func NewService(auditLogger *audit.Logger) *Service {
+	return &Service{auditLogger}
+}
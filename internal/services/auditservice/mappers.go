@@ -0,0 +1,72 @@
+package auditservice
+
+import (
+	"modbustohttp/internal/audit"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MapTableToConfig maps a Table from the API to a config.Table. TABLE_UNSPECIFIED maps to an empty config.Table.
+func MapTableToConfig(table modbusv1alpha1.Table) config.Table {
+	switch table {
+	case modbusv1alpha1.Table_TABLE_COILS:
+		return config.Coils
+	case modbusv1alpha1.Table_TABLE_DISCRETE_INPUTS:
+		return config.DiscreteInputs
+	case modbusv1alpha1.Table_TABLE_INPUT_REGISTERS:
+		return config.InputRegisters
+	case modbusv1alpha1.Table_TABLE_HOLDING_REGISTERS:
+		return config.HoldingRegisters
+	case modbusv1alpha1.Table_TABLE_FILE_RECORDS:
+		return config.FileRecords
+	default:
+		return ""
+	}
+}
+
+// MapConfigToTable maps a config.Table to a Table in the API.
+func MapConfigToTable(table config.Table) modbusv1alpha1.Table {
+	switch table {
+	case config.Coils:
+		return modbusv1alpha1.Table_TABLE_COILS
+	case config.DiscreteInputs:
+		return modbusv1alpha1.Table_TABLE_DISCRETE_INPUTS
+	case config.InputRegisters:
+		return modbusv1alpha1.Table_TABLE_INPUT_REGISTERS
+	case config.HoldingRegisters:
+		return modbusv1alpha1.Table_TABLE_HOLDING_REGISTERS
+	case config.FileRecords:
+		return modbusv1alpha1.Table_TABLE_FILE_RECORDS
+	default:
+		return modbusv1alpha1.Table_TABLE_UNSPECIFIED
+	}
+}
+
+// MapEventToAuditEvent maps an audit.Event to an AuditEvent in the API.
+func MapEventToAuditEvent(event audit.Event) *modbusv1alpha1.AuditEvent {
+	auditEvent := &modbusv1alpha1.AuditEvent{
+		Time:         timestamppb.New(event.Time),
+		Principal:    event.Principal,
+		Peer:         event.Peer,
+		Procedure:    event.Procedure,
+		Table:        MapConfigToTable(event.Table),
+		FileNumber:   event.FileNumber,
+		Address:      event.Address,
+		OldValues:    event.OldValues,
+		NewValues:    event.NewValues,
+		Success:      event.Success,
+		Error:        event.Error,
+		FunctionCode: uint32(event.FunctionCode),
+		Data:         event.Data,
+		UnitId:       uint32(event.UnitID),
+	}
+	if event.Mask != nil {
+		andMask := uint32(event.Mask.AndMask)
+		orMask := uint32(event.Mask.OrMask)
+		auditEvent.AndMask = &andMask
+		auditEvent.OrMask = &orMask
+	}
+	return auditEvent
+}
@@ -2,16 +2,28 @@ package health
 
 import (
 	"context"
+	"modbustohttp/internal/breaker"
 
 	"connectrpc.com/grpchealth"
-	"github.com/goburrow/modbus"
 )
 
+// Connector connects to the modbus server, such as modbus.TCPClientHandler or modbuspool.Pool.
+type Connector interface {
+	Connect() error
+}
+
 type ModbusChecker struct {
-	ModbusHandler *modbus.TCPClientHandler
+	ModbusHandler Connector
+	// Breaker is the circuit breaker of the connection to the modbus server. It is nil if the circuit breaker is
+	// disabled.
+	Breaker *breaker.Breaker
 }
 
 func (m ModbusChecker) Check(_ context.Context, _ *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	// Requests fail while the breaker is open, so the service is not serving even if the modbus server has come back.
+	if m.Breaker != nil && m.Breaker.State() == breaker.Open {
+		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
+	}
 	err := m.ModbusHandler.Connect()
 	if err != nil {
 		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
@@ -20,6 +32,6 @@ func (m ModbusChecker) Check(_ context.Context, _ *grpchealth.CheckRequest) (*gr
 	}
 }
 
-func NewModbusChecker(modbusHandler *modbus.TCPClientHandler) grpchealth.Checker {
-	return ModbusChecker{ModbusHandler: modbusHandler}
+func NewModbusChecker(modbusHandler Connector, circuitBreaker *breaker.Breaker) grpchealth.Checker {
+	return ModbusChecker{ModbusHandler: modbusHandler, Breaker: circuitBreaker}
 }
@@ -0,0 +1,149 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"modbustohttp/internal/arm"
+	"modbustohttp/internal/audit"
+	"modbustohttp/pkg/config"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// writeFunction returns the modbus function used to write a single value to the table.
+func writeFunction(table config.Table) config.ModbusFunction {
+	if table == config.Coils {
+		return config.WriteSingleCoil
+	}
+	return config.WriteSingleRegister
+}
+
+// readArmedCurrent reads the current value of the address a prepared write is for. Prepared writes can only be made
+// if the current value can be read, so that ExecuteWrite can check it has not changed.
+func (s Service) readArmedCurrent(client modbus.Client, table config.Table, address uint32) (uint32, error) {
+	current, err := s.readCurrent(client, table, address, 1)
+	if errors.Is(err, errReadNotSupported) {
+		return 0, connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return current[0], nil
+}
+
+func (s Service) PrepareWrite(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.PrepareWriteRequest],
+) (*connect.Response[modbusv1alpha1.PrepareWriteResponse], error) {
+	write := arm.PendingWrite{Principal: principalName(ctx)}
+	table := modbusv1alpha1.Table_TABLE_HOLDING_REGISTERS
+	switch {
+	case req.Msg.GetCoil() != nil:
+		table = modbusv1alpha1.Table_TABLE_COILS
+		write.Table = config.Coils
+		write.Address = req.Msg.GetCoil().GetAddress()
+		if req.Msg.GetCoil().GetValue() {
+			write.Value = 1
+		}
+	default:
+		write.Table = config.HoldingRegisters
+		write.Address = req.Msg.GetRegister().GetAddress()
+		write.Value = req.Msg.GetRegister().GetValue()
+	}
+	if slices.Index(s.modbusConfig.FunctionsSupported, writeFunction(write.Table)) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	if s.guard != nil {
+		if err := guardrailError(s.guard.Check(write.Table, write.Address, []uint32{write.Value}, nil)); err != nil {
+			return nil, err
+		}
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := s.client(ctx)
+	write.Current, err = s.readArmedCurrent(client, write.Table, write.Address)
+	if err != nil {
+		return nil, err
+	}
+	if s.guard != nil {
+		err = s.guard.Check(write.Table, write.Address, []uint32{write.Value}, []uint32{write.Current})
+		if err != nil {
+			return nil, guardrailError(err)
+		}
+	}
+
+	token, write, err := s.armStore.Prepare(write)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&modbusv1alpha1.PrepareWriteResponse{
+		Token:        token,
+		ExpiresAt:    timestamppb.New(write.ExpiresAt),
+		Table:        table,
+		Address:      write.Address,
+		Value:        write.Value,
+		CurrentValue: write.Current,
+	}), nil
+}
+
+func (s Service) ExecuteWrite(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ExecuteWriteRequest],
+) (*connect.Response[modbusv1alpha1.ExecuteWriteResponse], error) {
+	// The token is only taken once the write can be sent, so that it is not used up by a write which is not supported
+	// or a modbus server which cannot be reached, and can be executed again before it expires.
+	write, err := s.armStore.Peek(req.Msg.GetToken(), principalName(ctx))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	if slices.Index(s.modbusConfig.FunctionsSupported, writeFunction(write.Table)) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err = s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	write, err = s.armStore.Take(req.Msg.GetToken(), principalName(ctx))
+	if err != nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	client := s.client(ctx)
+	current, err := s.readArmedCurrent(client, write.Table, write.Address)
+	if err != nil {
+		return nil, err
+	}
+	if current != write.Current {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf(
+			"%s address %d changed from %d to %d since the write was prepared",
+			write.Table, write.Address, write.Current, current,
+		))
+	}
+
+	if write.Table == config.Coils {
+		var value uint16
+		if write.Value == 1 {
+			value = 0xFF00
+		}
+		_, err = client.WriteSingleCoil(uint16(write.Address), value)
+	} else {
+		_, err = client.WriteSingleRegister(uint16(write.Address), uint16(write.Value))
+	}
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     write.Table,
+		Address:   write.Address,
+		OldValues: []uint32{current},
+		NewValues: []uint32{write.Value},
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.ExecuteWriteResponse{}), nil
+}
@@ -0,0 +1,102 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/arm"
+	"modbustohttp/internal/auth"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestService_ExecuteWrite(t *testing.T) {
+	const armTimeout = 100 * time.Millisecond
+	modbusHandler, simulator := newSimulator(t)
+	modbusConfig := &config.Modbus{
+		FunctionsSupported: []config.ModbusFunction{config.ReadHoldingRegisters, config.WriteSingleRegister},
+	}
+	service := NewService(modbusHandler, modbusConfig, metrics.New(prometheus.NewRegistry()), nil, nil, nil, nil,
+		arm.NewStore(armTimeout), slog.New(slog.DiscardHandler),
+	)
+	alice := auth.NewContext(context.Background(), auth.Principal{Name: "alice"})
+	bob := auth.NewContext(context.Background(), auth.Principal{Name: "bob"})
+
+	prepare := func(t *testing.T, address uint32) string {
+		t.Helper()
+		response, err := service.PrepareWrite(alice, connect.NewRequest(&modbusv1alpha1.PrepareWriteRequest{
+			Write: &modbusv1alpha1.PrepareWriteRequest_Register{
+				Register: &modbusv1alpha1.Register{Address: address, Value: 7},
+			},
+		}))
+		if err != nil {
+			t.Fatalf("PrepareWrite() error = %v", err)
+		}
+		return response.Msg.GetToken()
+	}
+	execute := func(ctx context.Context, token string) error {
+		_, err := service.ExecuteWrite(ctx, connect.NewRequest(&modbusv1alpha1.ExecuteWriteRequest{Token: token}))
+		return err
+	}
+	wantError := func(t *testing.T, err error, code connect.Code, target error) {
+		t.Helper()
+		if connect.CodeOf(err) != code || (target != nil && !errors.Is(err, target)) {
+			t.Fatalf("ExecuteWrite() error = %v, want code %v wrapping %v", err, code, target)
+		}
+	}
+
+	t.Run("Expired token", func(t *testing.T) {
+		token := prepare(t, 1)
+		time.Sleep(armTimeout)
+		wantError(t, execute(alice, token), connect.CodeFailedPrecondition, arm.ErrInvalidToken)
+		if got := simulator.HoldingRegisters(1, 1); got[0] != 0 {
+			t.Errorf("HoldingRegisters() = %d, want 0", got[0])
+		}
+	})
+
+	t.Run("Token of another principal", func(t *testing.T) {
+		token := prepare(t, 2)
+		wantError(t, execute(bob, token), connect.CodeFailedPrecondition, arm.ErrPrincipalMismatch)
+		if got := simulator.HoldingRegisters(2, 1); got[0] != 0 {
+			t.Errorf("HoldingRegisters() = %d, want 0", got[0])
+		}
+		// The token is kept, so it can still be executed by the principal which prepared it.
+		if err := execute(alice, token); err != nil {
+			t.Fatalf("ExecuteWrite() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(2, 1); got[0] != 7 {
+			t.Errorf("HoldingRegisters() = %d, want 7", got[0])
+		}
+	})
+
+	t.Run("Changed value", func(t *testing.T) {
+		token := prepare(t, 3)
+		simulator.SetHoldingRegisters(3, 5)
+		wantError(t, execute(alice, token), connect.CodeFailedPrecondition, nil)
+		if got := simulator.HoldingRegisters(3, 1); got[0] != 5 {
+			t.Errorf("HoldingRegisters() = %d, want 5", got[0])
+		}
+		// The token is used up, so the write must be prepared again against the new value.
+		wantError(t, execute(alice, token), connect.CodeFailedPrecondition, arm.ErrInvalidToken)
+	})
+
+	t.Run("Write not supported", func(t *testing.T) {
+		token := prepare(t, 4)
+		modbusConfig.FunctionsSupported = []config.ModbusFunction{config.ReadHoldingRegisters}
+		wantError(t, execute(alice, token), connect.CodeUnimplemented, nil)
+		// The token is only taken once the write can be sent, so it can be executed once the write is supported.
+		modbusConfig.FunctionsSupported = append(modbusConfig.FunctionsSupported, config.WriteSingleRegister)
+		if err := execute(alice, token); err != nil {
+			t.Fatalf("ExecuteWrite() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(4, 1); got[0] != 7 {
+			t.Errorf("HoldingRegisters() = %d, want 7", got[0])
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/auth"
+	"modbustohttp/pkg/config"
+	"slices"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+)
+
+// errReadNotSupported is returned when the current values of a table cannot be read because the read function is not
+// supported by the modbus server.
+var errReadNotSupported = errors.New("reading the current values is not supported")
+
+// readBeforeWrite reads the current values of the given range so that they can be recorded in the audit event for a
+// write. Coils are returned as 0 or 1.
+// It returns nil if auditing is disabled, reading before writes is not configured, the read function is not supported
+// or the read fails, as the values are only informational and must not prevent the write.
+func (s Service) readBeforeWrite(client modbus.Client, table config.Table, address uint32, quantity uint32) []uint32 {
+	if s.auditLogger == nil || !s.auditLogger.ReadBeforeWrite() {
+		return nil
+	}
+	values, err := s.readCurrent(client, table, address, quantity)
+	if err != nil {
+		return nil
+	}
+	return values
+}
+
+// readCurrent reads the current values of the given range of coils or holding registers. Coils are returned as 0 or 1.
+// It returns an error if the read function for the table is not supported.
+func (s Service) readCurrent(
+	client modbus.Client,
+	table config.Table,
+	address uint32,
+	quantity uint32,
+) ([]uint32, error) {
+	switch table {
+	case config.Coils:
+		if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadCoils) == -1 {
+			return nil, errReadNotSupported
+		}
+		data, err := client.ReadCoils(uint16(address), uint16(quantity))
+		if err != nil {
+			return nil, err
+		}
+		values := make([]uint32, quantity)
+		for i, coil := range MapByteArrayToBooleanAddress(data, address, quantity) {
+			if coil != nil && coil.Value {
+				values[i] = 1
+			}
+		}
+		return values, nil
+	case config.HoldingRegisters:
+		if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadHoldingRegisters) == -1 {
+			return nil, errReadNotSupported
+		}
+		data, err := client.ReadHoldingRegisters(uint16(address), uint16(quantity))
+		if err != nil {
+			return nil, err
+		}
+		values := make([]uint32, 0, quantity)
+		for i := 0; i+1 < len(data); i += 2 {
+			values = append(values, uint32(binary.BigEndian.Uint16(data[i:])))
+		}
+		return values, nil
+	default:
+		return nil, errReadNotSupported
+	}
+}
+
+// principalName returns the name of the principal carried by ctx, or "anonymous" if the request was not authenticated.
+func principalName(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Name
+	}
+	return "anonymous"
+}
+
+// recordWrite records an audit event for a write made on behalf of the request, if auditing is enabled. The time,
+// principal, peer, procedure and outcome of the event are filled in from the request and the error returned by the
+// write.
+func (s Service) recordWrite(ctx context.Context, req connect.AnyRequest, event audit.Event, err error) {
+	if s.auditLogger == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.Principal = principalName(ctx)
+	event.Peer = req.Peer().Addr
+	event.Procedure = req.Spec().Procedure
+	event.Success = err == nil
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if recordErr := s.auditLogger.Record(event); recordErr != nil {
+		// The write has already been made, so failing the request would misreport its outcome to the caller.
+		s.logger.Error("error recording audit event",
+			slog.String("procedure", event.Procedure),
+			slog.String("error", recordErr.Error()),
+		)
+	}
+}
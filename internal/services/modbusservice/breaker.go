@@ -0,0 +1,45 @@
+package modbusservice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"modbustohttp/internal/breaker"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// allowRequest checks the request against the circuit breaker. If the breaker is open, it returns a Connect error with
+// the Unavailable code which tells the client when to retry.
+func (s Service) allowRequest() error {
+	if s.breaker == nil {
+		return nil
+	}
+	var openErr *breaker.OpenError
+	if err := s.breaker.Allow(); !errors.As(err, &openErr) {
+		return err
+	}
+	return retryAfterError(
+		connect.CodeUnavailable,
+		fmt.Errorf("modbus server is unreachable: %w", openErr),
+		openErr.RetryAfter,
+	)
+}
+
+// retryAfterError returns a Connect error with the code which tells the client to retry after the given time, both as
+// the Retry-After header and as error details.
+func retryAfterError(code connect.Code, err error, retryAfter time.Duration) *connect.Error {
+	connectErr := connect.NewError(code, err)
+	// Retry-After is in whole seconds, so it is rounded up to avoid clients retrying too soon.
+	seconds := max(int64(math.Ceil(retryAfter.Seconds())), 1)
+	connectErr.Meta().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}
+	if detail, detailErr := connect.NewErrorDetail(retryInfo); detailErr == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
@@ -0,0 +1,119 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbuspool"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestService_CircuitBreaker(t *testing.T) {
+	fake := &fakeConnectionHandler{
+		TCPClientHandler: modbus.NewTCPClientHandler(""),
+		connectErr:       errors.New("connection refused"),
+	}
+	circuitBreaker := breaker.New(&config.CircuitBreaker{FailureThreshold: 3, CoolDown: time.Minute}, nil)
+	m := metrics.New(prometheus.NewRegistry())
+	service := NewService(fake, &config.Modbus{}, m, circuitBreaker, nil, nil, nil, nil, slog.New(slog.DiscardHandler))
+
+	// The connection is retried until the breaker opens, rather than until the retry strategy times out.
+	start := time.Now()
+	err := service.connectModbus(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("connectModbus() took %v, want it to stop once the breaker opens", elapsed)
+	}
+	if circuitBreaker.State() != breaker.Open {
+		t.Fatalf("State() = %v, want open", circuitBreaker.State())
+	}
+	assertRetryAfter(t, err)
+
+	fake.connectErr = nil
+	assertRetryAfter(t, service.connectModbus(context.Background()))
+}
+
+func TestService_CircuitBreaker_Unresponsive(t *testing.T) {
+	// The device accepts connections but never answers a transaction.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	modbusConfig := &config.Modbus{
+		Host:               host,
+		Port:               portNumber,
+		SlaveID:            1,
+		ConnectionTimeout:  20 * time.Millisecond,
+		FunctionsSupported: []config.ModbusFunction{config.ReadHoldingRegisters},
+		Retry:              config.Retry{Read: config.RetryPolicy{MaxAttempts: 1}},
+	}
+	pool, err := modbuspool.New(modbusConfig, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+	circuitBreaker := breaker.New(&config.CircuitBreaker{FailureThreshold: 3, CoolDown: time.Minute}, nil)
+	service := NewService(pool, modbusConfig, metrics.New(prometheus.NewRegistry()), circuitBreaker, nil, nil, nil,
+		nil, slog.New(slog.DiscardHandler),
+	)
+
+	// Connecting succeeds every time, but must not reset the failures of the transactions.
+	for i := range 3 {
+		_, err := service.ReadHoldingRegisters(context.Background(),
+			connect.NewRequest(&modbusv1alpha1.ReadHoldingRegistersRequest{Address: 0, Quantity: proto.Uint32(1)}),
+		)
+		if err == nil {
+			t.Fatalf("ReadHoldingRegisters() %d error = nil, want timeout", i)
+		}
+	}
+	if circuitBreaker.State() != breaker.Open {
+		t.Fatalf("State() = %v, want open", circuitBreaker.State())
+	}
+	assertRetryAfter(t, service.connectModbus(context.Background()))
+}
+
+// assertRetryAfter checks that err is an Unavailable error telling the client to retry after the cool-down.
+func assertRetryAfter(t *testing.T, err error) {
+	t.Helper()
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeUnavailable {
+		t.Fatalf("connectModbus() error = %v, want unavailable", err)
+	}
+	if got := connectErr.Meta().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if retryInfo, ok := value.(*errdetails.RetryInfo); err == nil && ok {
+			if got := retryInfo.GetRetryDelay().AsDuration(); got <= 59*time.Second || got > time.Minute {
+				t.Errorf("RetryDelay = %v, want about 1m", got)
+			}
+			return
+		}
+	}
+	t.Error("connectModbus() error has no RetryInfo detail")
+}
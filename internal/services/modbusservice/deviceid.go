@@ -0,0 +1,134 @@
+package modbusservice
+
+import (
+	"context"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"slices"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+const (
+	// funcCodeEncapsulatedInterface is function code 43, the Modbus Encapsulated Interface (MEI) transport
+	funcCodeEncapsulatedInterface = 0x2B
+	// meiTypeReadDeviceIdentification is the MEI type of Read Device Identification
+	meiTypeReadDeviceIdentification = 0x0E
+	// moreFollows is the value of the more follows byte when the objects do not fit in one response
+	moreFollows = 0xFF
+)
+
+const (
+	objectIDVendorName  = 0x00
+	objectIDProductCode = 0x01
+	objectIDRevision    = 0x02
+)
+
+// deviceIdentification is a single Read Device Identification response.
+type deviceIdentification struct {
+	conformityLevel byte
+	moreFollows     bool
+	nextObjectID    byte
+	objects         map[byte][]byte
+}
+
+// parseDeviceIdentification parses the data of a Read Device Identification response PDU.
+func parseDeviceIdentification(data []byte) (*deviceIdentification, error) {
+	if len(data) < 6 {
+		return nil, fmt.Errorf("modbus: device identification response length '%v' is too short", len(data))
+	}
+	if data[0] != meiTypeReadDeviceIdentification {
+		return nil, fmt.Errorf("modbus: response MEI type '%v' does not match request '%v'",
+			data[0], meiTypeReadDeviceIdentification)
+	}
+	identification := &deviceIdentification{
+		conformityLevel: data[2],
+		moreFollows:     data[3] == moreFollows,
+		nextObjectID:    data[4],
+		objects:         make(map[byte][]byte, data[5]),
+	}
+	objects := data[6:]
+	for range data[5] {
+		if len(objects) < 2 || len(objects) < 2+int(objects[1]) {
+			return nil, fmt.Errorf("modbus: device identification response is truncated")
+		}
+		identification.objects[objects[0]] = objects[2 : 2+int(objects[1])]
+		objects = objects[2+int(objects[1]):]
+	}
+	return identification, nil
+}
+
+// readDeviceIdentification reads the device identification objects of the given category, starting from the given
+// object ID. Further requests are sent while the modbus server reports that more objects follow.
+func readDeviceIdentification(
+	handler modbus.ClientHandler,
+	category byte,
+	objectID byte,
+) (conformityLevel byte, objects map[byte][]byte, err error) {
+	objects = make(map[byte][]byte)
+	// Each response contains at least one object, so a well-behaved server needs at most 256 requests
+	for range 256 {
+		response, err := sendPDU(handler, &modbus.ProtocolDataUnit{
+			FunctionCode: funcCodeEncapsulatedInterface,
+			Data:         []byte{meiTypeReadDeviceIdentification, category, objectID},
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+		identification, err := parseDeviceIdentification(response.Data)
+		if err != nil {
+			return 0, nil, err
+		}
+		conformityLevel = identification.conformityLevel
+		for id, value := range identification.objects {
+			objects[id] = value
+		}
+		if !identification.moreFollows {
+			return conformityLevel, objects, nil
+		}
+		if identification.nextObjectID <= objectID {
+			return 0, nil, fmt.Errorf("modbus: device identification next object ID '%v' does not follow '%v'",
+				identification.nextObjectID, objectID)
+		}
+		objectID = identification.nextObjectID
+	}
+	return 0, nil, fmt.Errorf("modbus: device identification did not complete")
+}
+
+func (s Service) ReadDeviceIdentification(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ReadDeviceIdentificationRequest],
+) (*connect.Response[modbusv1alpha1.ReadDeviceIdentificationResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadDeviceIdentification) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conformityLevel, objects, err := readDeviceIdentification(
+		s.handler(ctx),
+		byte(req.Msg.GetCategory()),
+		byte(req.Msg.GetObjectId()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &modbusv1alpha1.ReadDeviceIdentificationResponse{
+		ConformityLevel: uint32(conformityLevel),
+		Objects:         make(map[uint32]string, len(objects)),
+	}
+	for id, value := range objects {
+		// Objects are usually ASCII strings, but extended objects may contain any bytes
+		response.Objects[uint32(id)] = strings.ToValidUTF8(string(value), "�")
+	}
+	response.VendorName = response.Objects[objectIDVendorName]
+	response.ProductCode = response.Objects[objectIDProductCode]
+	response.Revision = response.Objects[objectIDRevision]
+	return connect.NewResponse(response), nil
+}
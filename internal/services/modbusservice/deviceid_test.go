@@ -0,0 +1,59 @@
+package modbusservice
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/goburrow/modbus"
+)
+
+// deviceIDResponder answers Read Device Identification requests from the given objects, returning at most perResponse
+// objects in each response.
+func deviceIDResponder(objects [][]byte, perResponse int) func(request []byte) []byte {
+	return func(request []byte) []byte {
+		objectID := int(request[3])
+		pdu := []byte{funcCodeEncapsulatedInterface, meiTypeReadDeviceIdentification, request[2], 0x83, 0, 0, 0}
+		count := 0
+		for id := objectID; id < len(objects) && count < perResponse; id++ {
+			pdu = append(pdu, byte(id), byte(len(objects[id])))
+			pdu = append(pdu, objects[id]...)
+			count++
+		}
+		pdu[6] = byte(count)
+		if next := objectID + count; next < len(objects) {
+			pdu[4] = moreFollows
+			pdu[5] = byte(next)
+		}
+		return pdu
+	}
+}
+
+func TestReadDeviceIdentification(t *testing.T) {
+	objects := [][]byte{[]byte("Vendor"), []byte("Product"), []byte("v1.2"), []byte("https://example.com")}
+
+	conformityLevel, got, err := readDeviceIdentification(newPDUHandler(deviceIDResponder(objects, 1)), 2, 0)
+	if err != nil {
+		t.Fatalf("readDeviceIdentification() error = %v", err)
+	}
+	want := map[byte][]byte{0: objects[0], 1: objects[1], 2: objects[2], 3: objects[3]}
+	if conformityLevel != 0x83 || !maps.EqualFunc(got, want, func(a, b []byte) bool { return string(a) == string(b) }) {
+		t.Errorf("readDeviceIdentification() = %v, %q, want %v, %q", conformityLevel, got, 0x83, want)
+	}
+
+	handler := newPDUHandler(func([]byte) []byte {
+		return []byte{funcCodeEncapsulatedInterface | 0x80, modbus.ExceptionCodeIllegalDataAddress}
+	})
+	_, _, err = readDeviceIdentification(handler, 4, 0x80)
+	var modbusErr *modbus.ModbusError
+	if !errors.As(err, &modbusErr) || modbusErr.ExceptionCode != modbus.ExceptionCodeIllegalDataAddress {
+		t.Errorf("readDeviceIdentification() error = %v, want illegal data address exception", err)
+	}
+}
+
+func TestParseDeviceIdentification_Truncated(t *testing.T) {
+	_, err := parseDeviceIdentification([]byte{meiTypeReadDeviceIdentification, 1, 0x81, 0, 0, 1, 0, 5, 'a'})
+	if err == nil {
+		t.Error("parseDeviceIdentification() error = nil, want error")
+	}
+}
@@ -0,0 +1,242 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+const (
+	funcCodeDiagnostics         = 0x08
+	funcCodeGetCommEventCounter = 0x0B
+	funcCodeGetCommEventLog     = 0x0C
+)
+
+const (
+	subfunctionReturnQueryData            = 0x00
+	subfunctionClearCounters              = 0x0A
+	subfunctionBusMessageCount            = 0x0B
+	subfunctionBusCommunicationErrorCount = 0x0C
+	subfunctionBusExceptionErrorCount     = 0x0D
+	subfunctionServerMessageCount         = 0x0E
+	subfunctionServerNoResponseCount      = 0x0F
+)
+
+// commEventStatusBusy is the status returned by Get Comm Event Counter and Log while a previous command is processed
+const commEventStatusBusy = 0xFFFF
+
+// diagnosticCounter is a counter read by a Diagnostics subfunction.
+type diagnosticCounter struct {
+	function    config.ModbusFunction
+	subfunction uint16
+	// set sets the counter in the response
+	set func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32)
+}
+
+// diagnosticCounters are the counters read by GetDiagnosticCounters, each of which is only read if its function is
+// supported.
+var diagnosticCounters = []diagnosticCounter{
+	{
+		function:    config.DiagnosticsBusMessageCount,
+		subfunction: subfunctionBusMessageCount,
+		set: func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32) {
+			response.BusMessageCount = &count
+		},
+	},
+	{
+		function:    config.DiagnosticsBusCommunicationErrorCount,
+		subfunction: subfunctionBusCommunicationErrorCount,
+		set: func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32) {
+			response.BusCommunicationErrorCount = &count
+		},
+	},
+	{
+		function:    config.DiagnosticsBusExceptionErrorCount,
+		subfunction: subfunctionBusExceptionErrorCount,
+		set: func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32) {
+			response.BusExceptionErrorCount = &count
+		},
+	},
+	{
+		function:    config.DiagnosticsServerMessageCount,
+		subfunction: subfunctionServerMessageCount,
+		set: func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32) {
+			response.ServerMessageCount = &count
+		},
+	},
+	{
+		function:    config.DiagnosticsServerNoResponseCount,
+		subfunction: subfunctionServerNoResponseCount,
+		set: func(response *modbusv1alpha1.GetDiagnosticCountersResponse, count uint32) {
+			response.ServerNoResponseCount = &count
+		},
+	},
+}
+
+// diagnostics sends a Diagnostics request with the given subfunction and data, and returns the data of the response
+// following the echoed subfunction.
+func diagnostics(handler modbus.ClientHandler, subfunction uint16, data []byte) ([]byte, error) {
+	request := binary.BigEndian.AppendUint16(nil, subfunction)
+	response, err := sendPDU(handler, &modbus.ProtocolDataUnit{
+		FunctionCode: funcCodeDiagnostics,
+		Data:         append(request, data...),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) < 2 {
+		return nil, fmt.Errorf("modbus: diagnostics response length '%v' is too short", len(response.Data))
+	}
+	if responseSubfunction := binary.BigEndian.Uint16(response.Data); responseSubfunction != subfunction {
+		return nil, fmt.Errorf("modbus: response subfunction '%v' does not match request '%v'",
+			responseSubfunction, subfunction)
+	}
+	return response.Data[2:], nil
+}
+
+// readDiagnosticCounter reads the counter returned by the given Diagnostics subfunction.
+func readDiagnosticCounter(handler modbus.ClientHandler, subfunction uint16) (uint32, error) {
+	data, err := diagnostics(handler, subfunction, []byte{0, 0})
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 2 {
+		return 0, fmt.Errorf("modbus: diagnostics counter length '%v' does not match expected '%v'", len(data), 2)
+	}
+	return uint32(binary.BigEndian.Uint16(data)), nil
+}
+
+// parseCommEventLog parses the data of a Get Comm Event Log response PDU.
+func parseCommEventLog(data []byte) (*modbusv1alpha1.GetCommEventLogResponse, error) {
+	if len(data) < 7 || int(data[0]) != len(data)-1 {
+		return nil, fmt.Errorf("modbus: comm event log response length '%v' is invalid", len(data))
+	}
+	response := &modbusv1alpha1.GetCommEventLogResponse{
+		Busy:         binary.BigEndian.Uint16(data[1:]) == commEventStatusBusy,
+		EventCount:   uint32(binary.BigEndian.Uint16(data[3:])),
+		MessageCount: uint32(binary.BigEndian.Uint16(data[5:])),
+		Events:       make([]uint32, 0, len(data)-7),
+	}
+	for _, event := range data[7:] {
+		response.Events = append(response.Events, uint32(event))
+	}
+	return response, nil
+}
+
+func (s Service) ReturnQueryData(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ReturnQueryDataRequest],
+) (*connect.Response[modbusv1alpha1.ReturnQueryDataResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.DiagnosticsReturnQueryData) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := diagnostics(s.handler(ctx), subfunctionReturnQueryData, req.Msg.GetData())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.ReturnQueryDataResponse{Data: data}), nil
+}
+
+func (s Service) ClearDiagnosticCounters(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.ClearDiagnosticCountersRequest],
+) (*connect.Response[modbusv1alpha1.ClearDiagnosticCountersResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.DiagnosticsClearCounters) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = diagnostics(s.handler(ctx), subfunctionClearCounters, []byte{0, 0})
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.ClearDiagnosticCountersResponse{}), nil
+}
+
+func (s Service) GetDiagnosticCounters(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.GetDiagnosticCountersRequest],
+) (*connect.Response[modbusv1alpha1.GetDiagnosticCountersResponse], error) {
+	if !slices.ContainsFunc(diagnosticCounters, func(counter diagnosticCounter) bool {
+		return slices.Contains(s.modbusConfig.FunctionsSupported, counter.function)
+	}) {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	handler := s.handler(ctx)
+	response := &modbusv1alpha1.GetDiagnosticCountersResponse{}
+	for _, counter := range diagnosticCounters {
+		if !slices.Contains(s.modbusConfig.FunctionsSupported, counter.function) {
+			continue
+		}
+		count, err := readDiagnosticCounter(handler, counter.subfunction)
+		if err != nil {
+			return nil, err
+		}
+		counter.set(response, count)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (s Service) GetCommEventCounter(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.GetCommEventCounterRequest],
+) (*connect.Response[modbusv1alpha1.GetCommEventCounterResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.GetCommEventCounter) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response, err := sendPDU(s.handler(ctx), &modbus.ProtocolDataUnit{FunctionCode: funcCodeGetCommEventCounter})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) != 4 {
+		return nil, fmt.Errorf("modbus: comm event counter response length '%v' does not match expected '%v'",
+			len(response.Data), 4)
+	}
+	return connect.NewResponse(&modbusv1alpha1.GetCommEventCounterResponse{
+		Busy:       binary.BigEndian.Uint16(response.Data) == commEventStatusBusy,
+		EventCount: uint32(binary.BigEndian.Uint16(response.Data[2:])),
+	}), nil
+}
+
+func (s Service) GetCommEventLog(
+	ctx context.Context,
+	_ *connect.Request[modbusv1alpha1.GetCommEventLogRequest],
+) (*connect.Response[modbusv1alpha1.GetCommEventLogResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.GetCommEventLog) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response, err := sendPDU(s.handler(ctx), &modbus.ProtocolDataUnit{FunctionCode: funcCodeGetCommEventLog})
+	if err != nil {
+		return nil, err
+	}
+	eventLog, err := parseCommEventLog(response.Data)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(eventLog), nil
+}
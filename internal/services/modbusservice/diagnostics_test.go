@@ -0,0 +1,35 @@
+package modbusservice
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	handler := newPDUHandler(func(request []byte) []byte { return request })
+	data, err := diagnostics(handler, subfunctionReturnQueryData, []byte{0xA5, 0x37})
+	if err != nil || !bytes.Equal(data, []byte{0xA5, 0x37}) {
+		t.Errorf("diagnostics() = %v, %v, want [165 55], nil", data, err)
+	}
+
+	handler = newPDUHandler(func([]byte) []byte { return []byte{funcCodeDiagnostics, 0, subfunctionBusMessageCount, 0x01} })
+	if _, err := readDiagnosticCounter(handler, subfunctionBusCommunicationErrorCount); err == nil {
+		t.Error("readDiagnosticCounter() with a mismatched subfunction error = nil, want error")
+	}
+}
+
+func TestParseCommEventLog(t *testing.T) {
+	eventLog, err := parseCommEventLog([]byte{8, 0xFF, 0xFF, 0x01, 0x08, 0x01, 0x21, 0x20, 0x00})
+	if err != nil {
+		t.Fatalf("parseCommEventLog() error = %v", err)
+	}
+	if !eventLog.GetBusy() || eventLog.GetEventCount() != 0x0108 || eventLog.GetMessageCount() != 0x0121 ||
+		!slices.Equal(eventLog.GetEvents(), []uint32{0x20, 0x00}) {
+		t.Errorf("parseCommEventLog() = %v", eventLog)
+	}
+
+	if _, err := parseCommEventLog([]byte{9, 0, 0, 0, 0, 0, 0, 0x20}); err == nil {
+		t.Error("parseCommEventLog() with an invalid byte count error = nil, want error")
+	}
+}
@@ -0,0 +1,67 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"modbustohttp/pkg/config"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// maxFIFOCount is the largest number of values in a FIFO queue
+const maxFIFOCount = 31
+
+// readFIFOQueue reads the values in the FIFO queue with the pointer register at the given address. It does not use
+// modbus.Client.ReadFIFOQueue, which compares the byte count with the wrong length and so rejects valid responses.
+func readFIFOQueue(handler modbus.ClientHandler, address uint16) ([]uint32, error) {
+	response, err := sendPDU(handler, &modbus.ProtocolDataUnit{
+		FunctionCode: modbus.FuncCodeReadFIFOQueue,
+		Data:         binary.BigEndian.AppendUint16(nil, address),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) < 4 {
+		return nil, fmt.Errorf("modbus: response data size '%v' is less than expected '%v'", len(response.Data), 4)
+	}
+	if byteCount := int(binary.BigEndian.Uint16(response.Data)); byteCount != len(response.Data)-2 {
+		return nil, fmt.Errorf("modbus: response data size '%v' does not match count '%v'",
+			len(response.Data)-2, byteCount)
+	}
+	count := int(binary.BigEndian.Uint16(response.Data[2:]))
+	if count > maxFIFOCount {
+		return nil, fmt.Errorf("modbus: fifo count '%v' is greater than expected '%v'", count, maxFIFOCount)
+	}
+	if len(response.Data) != 4+2*count {
+		return nil, fmt.Errorf("modbus: response data size '%v' does not match fifo count '%v'",
+			len(response.Data)-4, count)
+	}
+	values := make([]uint32, 0, count)
+	for i := 4; i < len(response.Data); i += 2 {
+		values = append(values, uint32(binary.BigEndian.Uint16(response.Data[i:])))
+	}
+	return values, nil
+}
+
+func (s Service) ReadFIFOQueue(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ReadFIFOQueueRequest],
+) (*connect.Response[modbusv1alpha1.ReadFIFOQueueResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadFIFOQueue) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values, err := readFIFOQueue(s.handler(ctx), uint16(req.Msg.GetAddress()))
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.ReadFIFOQueueResponse{Values: values}), nil
+}
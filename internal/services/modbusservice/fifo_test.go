@@ -0,0 +1,23 @@
+package modbusservice
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReadFIFOQueue(t *testing.T) {
+	handler := newPDUHandler(func(request []byte) []byte {
+		return []byte{request[0], 0x00, 0x06, 0x00, 0x02, 0x01, 0xB8, 0x12, 0x84}
+	})
+	values, err := readFIFOQueue(handler, 0x04DE)
+	if err != nil || !slices.Equal(values, []uint32{0x01B8, 0x1284}) {
+		t.Errorf("readFIFOQueue() = %v, %v, want [440 4740], nil", values, err)
+	}
+
+	handler = newPDUHandler(func(request []byte) []byte {
+		return []byte{request[0], 0x00, 0x06, 0x00, 0x03, 0x01, 0xB8, 0x12, 0x84}
+	})
+	if _, err := readFIFOQueue(handler, 0x04DE); err == nil {
+		t.Error("readFIFOQueue() with a mismatched fifo count error = nil, want error")
+	}
+}
@@ -0,0 +1,204 @@
+package modbusservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"modbustohttp/internal/audit"
+	"modbustohttp/pkg/config"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+const (
+	funcCodeReadFileRecord  = 0x14
+	funcCodeWriteFileRecord = 0x15
+	// fileRecordReferenceType is the reference type of every file record sub-request
+	fileRecordReferenceType = 0x06
+	// maxFileRecordByteCount is the largest byte count of a file record request or response
+	maxFileRecordByteCount = 0xF5
+)
+
+// readFileRecordResponseLength returns the byte count of the response to reading the given references.
+func readFileRecordResponseLength(references []*modbusv1alpha1.FileRecordReference) int {
+	length := 0
+	for _, reference := range references {
+		length += 2 + 2*int(reference.GetLength())
+	}
+	return length
+}
+
+// writeFileRecordRequestLength returns the byte count of the request to write the given records.
+func writeFileRecordRequestLength(records []*modbusv1alpha1.FileRecord) int {
+	length := 0
+	for _, record := range records {
+		length += 7 + 2*len(record.GetValues())
+	}
+	return length
+}
+
+// readFileRecords reads the given references in a single Read File Record transaction.
+func readFileRecords(
+	handler modbus.ClientHandler,
+	references []*modbusv1alpha1.FileRecordReference,
+) ([]*modbusv1alpha1.FileRecord, error) {
+	data := []byte{byte(7 * len(references))}
+	for _, reference := range references {
+		data = append(data, fileRecordReferenceType)
+		data = binary.BigEndian.AppendUint16(data, uint16(reference.GetFileNumber()))
+		data = binary.BigEndian.AppendUint16(data, uint16(reference.GetRecordNumber()))
+		data = binary.BigEndian.AppendUint16(data, uint16(reference.GetLength()))
+	}
+	response, err := sendPDU(handler, &modbus.ProtocolDataUnit{FunctionCode: funcCodeReadFileRecord, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if int(response.Data[0]) != len(response.Data)-1 {
+		return nil, fmt.Errorf("modbus: response data size '%v' does not match count '%v'",
+			len(response.Data)-1, response.Data[0])
+	}
+	subResponses := response.Data[1:]
+	records := make([]*modbusv1alpha1.FileRecord, 0, len(references))
+	for _, reference := range references {
+		length := 1 + 2*int(reference.GetLength())
+		if len(subResponses) < 1+length || int(subResponses[0]) != length ||
+			subResponses[1] != fileRecordReferenceType {
+			return nil, fmt.Errorf("modbus: file record response does not match request")
+		}
+		record := &modbusv1alpha1.FileRecord{
+			FileNumber:   reference.GetFileNumber(),
+			RecordNumber: reference.GetRecordNumber(),
+			Values:       make([]uint32, 0, reference.GetLength()),
+		}
+		for i := 2; i < 1+length; i += 2 {
+			record.Values = append(record.Values, uint32(binary.BigEndian.Uint16(subResponses[i:])))
+		}
+		records = append(records, record)
+		subResponses = subResponses[1+length:]
+	}
+	if len(subResponses) > 0 {
+		return nil, fmt.Errorf("modbus: file record response does not match request")
+	}
+	return records, nil
+}
+
+// writeFileRecords writes the given records in a single Write File Record transaction.
+func writeFileRecords(handler modbus.ClientHandler, records []*modbusv1alpha1.FileRecord) error {
+	data := []byte{byte(writeFileRecordRequestLength(records))}
+	for _, record := range records {
+		data = append(data, fileRecordReferenceType)
+		data = binary.BigEndian.AppendUint16(data, uint16(record.GetFileNumber()))
+		data = binary.BigEndian.AppendUint16(data, uint16(record.GetRecordNumber()))
+		data = binary.BigEndian.AppendUint16(data, uint16(len(record.GetValues())))
+		for _, value := range record.GetValues() {
+			data = binary.BigEndian.AppendUint16(data, uint16(value))
+		}
+	}
+	response, err := sendPDU(handler, &modbus.ProtocolDataUnit{FunctionCode: funcCodeWriteFileRecord, Data: data})
+	if err != nil {
+		return err
+	}
+	// The response is an echo of the request
+	if !bytes.Equal(response.Data, data) {
+		return fmt.Errorf("modbus: file record response does not match request")
+	}
+	return nil
+}
+
+func (s Service) ReadFileRecord(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ReadFileRecordRequest],
+) (*connect.Response[modbusv1alpha1.ReadFileRecordResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadFileRecord) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	if length := readFileRecordResponseLength(req.Msg.GetReferences()); length > maxFileRecordByteCount {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf(
+			"the records read need %d bytes, which is more than the %d bytes allowed in a response",
+			length, maxFileRecordByteCount,
+		))
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := readFileRecords(s.handler(ctx), req.Msg.GetReferences())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.ReadFileRecordResponse{Records: records}), nil
+}
+
+func (s Service) WriteFileRecord(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.WriteFileRecordRequest],
+) (*connect.Response[modbusv1alpha1.WriteFileRecordResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.WriteFileRecord) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	if length := writeFileRecordRequestLength(req.Msg.GetRecords()); length > maxFileRecordByteCount {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf(
+			"the records written need %d bytes, which is more than the %d bytes allowed in a request",
+			length, maxFileRecordByteCount,
+		))
+	}
+	// Write rules cannot cover file records, so these writes are only allowed if unlisted addresses are.
+	for _, record := range req.Msg.GetRecords() {
+		if err := s.checkWrite(config.FileRecords, record.GetRecordNumber(), record.GetValues()); err != nil {
+			return nil, err
+		}
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	handler := s.handler(ctx)
+	oldRecords := s.readFileRecordsBeforeWrite(handler, req.Msg.GetRecords())
+	err = writeFileRecords(handler, req.Msg.GetRecords())
+	for i, record := range req.Msg.GetRecords() {
+		event := audit.Event{
+			Table:      config.FileRecords,
+			FileNumber: record.GetFileNumber(),
+			Address:    record.GetRecordNumber(),
+			NewValues:  record.GetValues(),
+		}
+		if oldRecords != nil {
+			event.OldValues = oldRecords[i].GetValues()
+		}
+		s.recordWrite(ctx, req, event, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.WriteFileRecordResponse{}), nil
+}
+
+// readFileRecordsBeforeWrite reads the current values of the given records so that they can be recorded in the audit
+// events for writing them, as readBeforeWrite does for the other tables.
+func (s Service) readFileRecordsBeforeWrite(
+	handler modbus.ClientHandler,
+	records []*modbusv1alpha1.FileRecord,
+) []*modbusv1alpha1.FileRecord {
+	if s.auditLogger == nil || !s.auditLogger.ReadBeforeWrite() ||
+		slices.Index(s.modbusConfig.FunctionsSupported, config.ReadFileRecord) == -1 {
+		return nil
+	}
+	references := make([]*modbusv1alpha1.FileRecordReference, 0, len(records))
+	for _, record := range records {
+		references = append(references, &modbusv1alpha1.FileRecordReference{
+			FileNumber:   record.GetFileNumber(),
+			RecordNumber: record.GetRecordNumber(),
+			Length:       uint32(len(record.GetValues())),
+		})
+	}
+	oldRecords, err := readFileRecords(handler, references)
+	if err != nil {
+		return nil
+	}
+	return oldRecords
+}
@@ -0,0 +1,78 @@
+package modbusservice
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// fileResponder answers Read and Write File Record requests from files, which map file numbers to their records.
+func fileResponder(files map[uint16][]uint16) func(request []byte) []byte {
+	return func(request []byte) []byte {
+		data := request[2:]
+		switch request[0] {
+		case funcCodeWriteFileRecord:
+			for len(data) > 0 {
+				file := binary.BigEndian.Uint16(data[1:])
+				record := binary.BigEndian.Uint16(data[3:])
+				length := int(binary.BigEndian.Uint16(data[5:]))
+				for i := range length {
+					files[file][int(record)+i] = binary.BigEndian.Uint16(data[7+2*i:])
+				}
+				data = data[7+2*length:]
+			}
+			return request
+		case funcCodeReadFileRecord:
+			response := []byte{funcCodeReadFileRecord, 0}
+			for ; len(data) > 0; data = data[7:] {
+				file := binary.BigEndian.Uint16(data[1:])
+				record := binary.BigEndian.Uint16(data[3:])
+				length := int(binary.BigEndian.Uint16(data[5:]))
+				response = append(response, byte(1+2*length), fileRecordReferenceType)
+				for _, value := range files[file][record : int(record)+length] {
+					response = binary.BigEndian.AppendUint16(response, value)
+				}
+			}
+			response[1] = byte(len(response) - 2)
+			return response
+		}
+		return []byte{request[0] | 0x80, 1}
+	}
+}
+
+func TestFileRecords(t *testing.T) {
+	handler := newPDUHandler(fileResponder(map[uint16][]uint16{1: make([]uint16, 10), 4: make([]uint16, 10)}))
+	err := writeFileRecords(handler, []*modbusv1alpha1.FileRecord{
+		{FileNumber: 1, RecordNumber: 2, Values: []uint32{0x0102, 0x0304}},
+		{FileNumber: 4, RecordNumber: 7, Values: []uint32{0xFFFF}},
+	})
+	if err != nil {
+		t.Fatalf("writeFileRecords() error = %v", err)
+	}
+
+	records, err := readFileRecords(handler, []*modbusv1alpha1.FileRecordReference{
+		{FileNumber: 4, RecordNumber: 6, Length: 2},
+		{FileNumber: 1, RecordNumber: 1, Length: 3},
+	})
+	if err != nil {
+		t.Fatalf("readFileRecords() error = %v", err)
+	}
+	want := [][]uint32{{0, 0xFFFF}, {0, 0x0102, 0x0304}}
+	if len(records) != len(want) {
+		t.Fatalf("readFileRecords() returned %d records, want %d", len(records), len(want))
+	}
+	for i, record := range records {
+		if !slices.Equal(record.GetValues(), want[i]) {
+			t.Errorf("readFileRecords() record %d = %v, want %v", i, record.GetValues(), want[i])
+		}
+	}
+}
+
+func TestReadFileRecordResponseLength(t *testing.T) {
+	references := []*modbusv1alpha1.FileRecordReference{{Length: 100}, {Length: 21}}
+	if got := readFileRecordResponseLength(references); got != 246 {
+		t.Errorf("readFileRecordResponseLength() = %d, want %d", got, 246)
+	}
+}
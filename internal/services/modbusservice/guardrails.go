@@ -0,0 +1,63 @@
+package modbusservice
+
+import (
+	"errors"
+	"fmt"
+	"modbustohttp/internal/guardrails"
+	"modbustohttp/pkg/config"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// checkWrite checks a direct write of values starting at address against the write rules, without reading the
+// current values. Addresses which must be armed cannot be written directly. It is made before connecting to the modbus
+// server so that writes which are not allowed send no traffic.
+func (s Service) checkWrite(table config.Table, address uint32, values []uint32) error {
+	if s.guard == nil {
+		return nil
+	}
+	if err := s.guard.CheckArm(table, address, uint32(len(values))); err != nil {
+		return guardrailError(err)
+	}
+	return guardrailError(s.guard.Check(table, address, values, nil))
+}
+
+// checkWriteStep checks a write of values starting at address against the max steps of the write rules, reading the
+// current values first. It does nothing if no rule with a max step applies to the addresses written.
+func (s Service) checkWriteStep(client modbus.Client, table config.Table, address uint32, values []uint32) error {
+	if s.guard == nil || !s.guard.NeedsCurrent(table, address, uint32(len(values))) {
+		return nil
+	}
+	current, err := s.readCurrent(client, table, address, uint32(len(values)))
+	if err != nil {
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("reading current values to check write rules: %w", err),
+		)
+	}
+	return guardrailError(s.guard.Check(table, address, values, current))
+}
+
+// guardrailError converts a *guardrails.ViolationError into a Connect error with the FailedPrecondition code, which
+// lists each rule that was not followed as error details.
+func guardrailError(err error) error {
+	var violationErr *guardrails.ViolationError
+	if !errors.As(err, &violationErr) {
+		return err
+	}
+	failure := &errdetails.PreconditionFailure{}
+	for _, violation := range violationErr.Violations {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        string(violation.Type),
+			Subject:     fmt.Sprintf("%s/%d", violation.Table, violation.Address),
+			Description: violation.Description,
+		})
+	}
+	connectErr := connect.NewError(connect.CodeFailedPrecondition, err)
+	if detail, detailErr := connect.NewErrorDetail(failure); detailErr == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
@@ -0,0 +1,152 @@
+package modbusservice
+
+import (
+	"errors"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbuspool"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+// connectionHandler is a modbus.ClientHandler which manages long-lived connections to the Modbus server, such as
+// modbus.TCPClientHandler or modbuspool.Pool.
+type connectionHandler interface {
+	modbus.ClientHandler
+	Connect() error
+	Close() error
+}
+
+// failedConnectionCloser is implemented by connectionHandlers which close the connection a transaction failed on
+// themselves, such as modbuspool.Pool, so that their other connections need not be closed with it.
+type failedConnectionCloser interface {
+	ClosesFailedConnections() bool
+}
+
+// instrumentedHandler wraps a connectionHandler to record metrics for the connection and every Modbus transaction
+// sent through it, and to record their outcomes in the circuit breaker.
+type instrumentedHandler struct {
+	connectionHandler
+	metrics *metrics.Metrics
+	// breaker records whether the connection and transactions succeed. It is nil if the circuit breaker is disabled.
+	breaker *breaker.Breaker
+	// connected tracks whether the connection is believed to be established.
+	connected atomic.Bool
+	// hasConnected is set once the first connection has been established, so that later connections are counted as
+	// reconnects.
+	hasConnected atomic.Bool
+}
+
+func newInstrumentedHandler(
+	handler connectionHandler,
+	m *metrics.Metrics,
+	circuitBreaker *breaker.Breaker,
+) *instrumentedHandler {
+	return &instrumentedHandler{connectionHandler: handler, metrics: m, breaker: circuitBreaker}
+}
+
+// Connect establishes the connection to the Modbus server, recording the connection state and any reconnects.
+// Failures to connect are recorded in the circuit breaker, but successes are not: Connect does nothing while a
+// connection is open, and a device may accept connections without answering, so only a transaction shows that the
+// modbus server is reachable.
+func (h *instrumentedHandler) Connect() error {
+	err := h.connectionHandler.Connect()
+	if err != nil {
+		h.recordOutcome(err)
+		h.setConnected(false)
+		return err
+	}
+	if !h.connected.Load() && h.hasConnected.Swap(true) {
+		h.metrics.ModbusReconnects.Inc()
+	}
+	h.setConnected(true)
+	return nil
+}
+
+// Send sends a single Modbus transaction, recording its latency, result and any exception code returned.
+// If the transaction fails at the transport level the connection is closed so that it is re-established by the next
+// call to Connect, rather than reusing a connection which may be broken or have a late response pending.
+func (h *instrumentedHandler) Send(aduRequest []byte) ([]byte, error) {
+	functionCode := "unknown"
+	if request, err := h.Decode(aduRequest); err == nil {
+		functionCode = strconv.Itoa(int(request.FunctionCode))
+	}
+
+	h.metrics.ModbusQueueDepth.Inc()
+	start := time.Now()
+	aduResponse, err := h.connectionHandler.Send(aduRequest)
+	h.metrics.ModbusQueueDepth.Dec()
+	h.metrics.ModbusDuration.WithLabelValues(functionCode).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		h.metrics.ModbusRequests.WithLabelValues(functionCode, "error").Inc()
+		// No connection of a pool was free in time, which shows the modbus server is busy rather than unreachable, and
+		// the pool's connections are still open.
+		if errors.Is(err, modbuspool.ErrTimeout) {
+			return nil, err
+		}
+		h.recordOutcome(err)
+		h.setConnected(false)
+		// A pool closes the connection which failed itself, and closing the pool would also close its other
+		// connections, which are still working.
+		if closer, ok := h.connectionHandler.(failedConnectionCloser); !ok || !closer.ClosesFailedConnections() {
+			// The original error is more useful to the caller than any error closing the connection.
+			_ = h.connectionHandler.Close()
+		}
+		return nil, err
+	}
+
+	// Exception responses still show the modbus server is reachable, so only transport errors are failures.
+	h.recordOutcome(nil)
+	result := "ok"
+	if exception := exceptionFromResponse(h, aduResponse); exception != nil {
+		result = "exception"
+		h.metrics.ModbusExceptions.WithLabelValues(functionCode, strconv.Itoa(int(exception.ExceptionCode))).Inc()
+	}
+	h.metrics.ModbusRequests.WithLabelValues(functionCode, result).Inc()
+	return aduResponse, nil
+}
+
+// Close closes the connection to the Modbus server.
+func (h *instrumentedHandler) Close() error {
+	h.setConnected(false)
+	return h.connectionHandler.Close()
+}
+
+// recordOutcome records in the circuit breaker whether the modbus server was reached.
+func (h *instrumentedHandler) recordOutcome(err error) {
+	if h.breaker == nil {
+		return
+	}
+	if err != nil {
+		h.breaker.Failure()
+	} else {
+		h.breaker.Success()
+	}
+}
+
+func (h *instrumentedHandler) setConnected(connected bool) {
+	h.connected.Store(connected)
+	if connected {
+		h.metrics.ModbusConnected.Set(1)
+	} else {
+		h.metrics.ModbusConnected.Set(0)
+	}
+}
+
+// exceptionFromResponse returns the exception if the given response ADU is a Modbus exception response, and nil
+// otherwise. Exception responses have the high bit of the function code set and carry the exception code as the
+// only data byte.
+func exceptionFromResponse(packager modbus.Packager, aduResponse []byte) *modbus.ModbusError {
+	response, err := packager.Decode(aduResponse)
+	if err != nil || response.FunctionCode&0x80 == 0 || len(response.Data) == 0 {
+		return nil
+	}
+	return &modbus.ModbusError{
+		FunctionCode:  response.FunctionCode,
+		ExceptionCode: response.Data[0],
+	}
+}
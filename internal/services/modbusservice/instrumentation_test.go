@@ -0,0 +1,300 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbuspool"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeConnectionHandler is a connectionHandler which returns a canned response for every transaction.
+type fakeConnectionHandler struct {
+	*modbus.TCPClientHandler
+	response   []byte
+	sendErr    error
+	connectErr error
+	closed     int
+}
+
+func (f *fakeConnectionHandler) Connect() error { return f.connectErr }
+
+func (f *fakeConnectionHandler) Close() error {
+	f.closed++
+	return nil
+}
+
+func (f *fakeConnectionHandler) Send(_ []byte) ([]byte, error) { return f.response, f.sendErr }
+
+// tcpADU builds a Modbus TCP ADU for the given function code and data.
+func tcpADU(functionCode byte, data ...byte) []byte {
+	adu, _ := modbus.NewTCPClientHandler("").Encode(&modbus.ProtocolDataUnit{FunctionCode: functionCode, Data: data})
+	return adu
+}
+
+func TestInstrumentedHandler_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      []byte
+		sendErr       error
+		wantResult    string
+		wantException string
+		wantClosed    int
+	}{
+		{
+			name:       "Successful transaction",
+			response:   tcpADU(0x03, 0x02, 0x00, 0x01),
+			wantResult: "ok",
+		},
+		{
+			name:          "Exception response",
+			response:      tcpADU(0x83, 0x02),
+			wantResult:    "exception",
+			wantException: "2",
+		},
+		{
+			name:       "Transport error",
+			sendErr:    errors.New("connection reset"),
+			wantResult: "error",
+			wantClosed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New(prometheus.NewRegistry())
+			fake := &fakeConnectionHandler{
+				TCPClientHandler: modbus.NewTCPClientHandler(""),
+				response:         tt.response,
+				sendErr:          tt.sendErr,
+			}
+			handler := newInstrumentedHandler(fake, m, nil)
+
+			_, err := handler.Send(tcpADU(0x03, 0x00, 0x00, 0x00, 0x01))
+			if !errors.Is(err, tt.sendErr) {
+				t.Errorf("Send() error = %v, want %v", err, tt.sendErr)
+			}
+			if got := testutil.ToFloat64(m.ModbusRequests.WithLabelValues("3", tt.wantResult)); got != 1 {
+				t.Errorf("requests with result %q = %v, want 1", tt.wantResult, got)
+			}
+			if tt.wantException != "" {
+				if got := testutil.ToFloat64(m.ModbusExceptions.WithLabelValues("3", tt.wantException)); got != 1 {
+					t.Errorf("exceptions with code %q = %v, want 1", tt.wantException, got)
+				}
+			}
+			if fake.closed != tt.wantClosed {
+				t.Errorf("connection closed %d times, want %d", fake.closed, tt.wantClosed)
+			}
+			if got := testutil.ToFloat64(m.ModbusQueueDepth); got != 0 {
+				t.Errorf("queue depth = %v, want 0", got)
+			}
+		})
+	}
+}
+
+// closingHandler is a fakeConnectionHandler which closes failed connections itself, as a pool does.
+type closingHandler struct {
+	*fakeConnectionHandler
+}
+
+func (closingHandler) ClosesFailedConnections() bool { return true }
+
+func TestInstrumentedHandler_SendClosesFailed(t *testing.T) {
+	fake := &fakeConnectionHandler{TCPClientHandler: modbus.NewTCPClientHandler(""), sendErr: errors.New("reset")}
+	handler := newInstrumentedHandler(closingHandler{fake}, metrics.New(prometheus.NewRegistry()), nil)
+
+	if _, err := handler.Send(tcpADU(0x03, 0x00, 0x00, 0x00, 0x01)); err == nil {
+		t.Fatal("Send() error = nil, want error")
+	}
+	if fake.closed != 0 {
+		t.Errorf("connection closed %d times, want 0 as the handler closes failed connections itself", fake.closed)
+	}
+}
+
+// countingListener counts the connections accepted.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestInstrumentedHandler_SendPool(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+	simulator := modbusserver.NewSimulator()
+	// Function code 65 never responds in time, without holding up the transactions on other connections.
+	server := modbusserver.NewServer(modbusserver.HandlerFunc(func(
+		ctx context.Context,
+		unitID byte,
+		request *modbus.ProtocolDataUnit,
+	) (*modbus.ProtocolDataUnit, error) {
+		if request.FunctionCode == 65 {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return simulator.HandlePDU(ctx, unitID, request)
+	}))
+	go func() { _ = server.Serve(counting) }()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	pool, err := modbuspool.New(&config.Modbus{
+		Host:              host,
+		Port:              portNumber,
+		SlaveID:           1,
+		ConnectionTimeout: 100 * time.Millisecond,
+		Pool:              config.ConnectionPool{MinConnections: 2, MaxConnections: 2},
+	}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = pool.Close()
+		_ = server.Close()
+	})
+	// Run opens the minimum connections before it sees that the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pool.Run(ctx)
+	handler := newInstrumentedHandler(pool, metrics.New(prometheus.NewRegistry()), nil)
+
+	aduRequest, err := handler.Encode(&modbus.ProtocolDataUnit{FunctionCode: 65})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handler.Send(aduRequest); err == nil {
+		t.Fatal("Send() error = nil, want timeout")
+	}
+	// Only the connection which timed out is closed, so the other connection is used without opening a new one.
+	if _, err := modbus.NewClient(handler).ReadHoldingRegisters(0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	if got := counting.accepted.Load(); got != 2 {
+		t.Errorf("accepted %d connections, want 2", got)
+	}
+}
+
+func TestInstrumentedHandler_SendPoolBusy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulator := modbusserver.NewSimulator()
+	// Function code 65 is slow to respond, but responds in time.
+	server := modbusserver.NewServer(modbusserver.HandlerFunc(func(
+		ctx context.Context,
+		unitID byte,
+		request *modbus.ProtocolDataUnit,
+	) (*modbus.ProtocolDataUnit, error) {
+		if request.FunctionCode == 65 {
+			time.Sleep(150 * time.Millisecond)
+			return &modbus.ProtocolDataUnit{FunctionCode: 65}, nil
+		}
+		return simulator.HandlePDU(ctx, unitID, request)
+	}))
+	go func() { _ = server.Serve(listener) }()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	pool, err := modbuspool.New(&config.Modbus{
+		Host:              host,
+		Port:              portNumber,
+		SlaveID:           1,
+		ConnectionTimeout: 200 * time.Millisecond,
+		Pool:              config.ConnectionPool{MaxConnections: 1},
+	}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = pool.Close()
+		_ = server.Close()
+	})
+	m := metrics.New(prometheus.NewRegistry())
+	circuitBreaker := breaker.New(&config.CircuitBreaker{FailureThreshold: 1, CoolDown: time.Minute}, nil)
+	handler := newInstrumentedHandler(pool, m, circuitBreaker)
+	if err := handler.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// Two slow transactions hold the only connection for longer than the third waits for it.
+	var wg sync.WaitGroup
+	for range 2 {
+		aduRequest, err := handler.Encode(&modbus.ProtocolDataUnit{FunctionCode: 65})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Go(func() {
+			if _, err := handler.Send(aduRequest); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		})
+		time.Sleep(20 * time.Millisecond)
+	}
+	_, err = modbus.NewClient(handler).ReadHoldingRegisters(0, 1)
+	wg.Wait()
+	if !errors.Is(err, modbuspool.ErrTimeout) {
+		t.Fatalf("ReadHoldingRegisters() error = %v, want %v", err, modbuspool.ErrTimeout)
+	}
+	if circuitBreaker.State() != breaker.Closed {
+		t.Errorf("State() = %v, want closed", circuitBreaker.State())
+	}
+	if got := testutil.ToFloat64(m.ModbusConnected); got != 1 {
+		t.Errorf("connected = %v, want 1", got)
+	}
+}
+
+func TestInstrumentedHandler_Connect(t *testing.T) {
+	m := metrics.New(prometheus.NewRegistry())
+	fake := &fakeConnectionHandler{TCPClientHandler: modbus.NewTCPClientHandler("")}
+	handler := newInstrumentedHandler(fake, m, nil)
+
+	// The first connection is not a reconnect, and connecting while connected is a no-op.
+	for range 2 {
+		if err := handler.Connect(); err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(m.ModbusReconnects); got != 0 {
+		t.Errorf("reconnects after first connection = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(m.ModbusConnected); got != 1 {
+		t.Errorf("connected = %v, want 1", got)
+	}
+
+	fake.connectErr = errors.New("connection refused")
+	if err := handler.Connect(); err == nil {
+		t.Fatal("Connect() error = nil, want error")
+	}
+	if got := testutil.ToFloat64(m.ModbusConnected); got != 0 {
+		t.Errorf("connected after failure = %v, want 0", got)
+	}
+
+	fake.connectErr = nil
+	if err := handler.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if got := testutil.ToFloat64(m.ModbusReconnects); got != 1 {
+		t.Errorf("reconnects = %v, want 1", got)
+	}
+}
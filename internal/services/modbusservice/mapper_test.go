@@ -204,3 +204,43 @@ func TestMapByteArrayToRegisters_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestMapBitsToMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		bits    []*modbusv1alpha1.BooleanAddress
+		current uint16
+		want    uint16
+	}{
+		{
+			name:    "Set a bit",
+			bits:    []*modbusv1alpha1.BooleanAddress{{Address: 3, Value: true}},
+			current: 0x0000,
+			want:    0x0008,
+		},
+		{
+			name:    "Clear a bit",
+			bits:    []*modbusv1alpha1.BooleanAddress{{Address: 0, Value: false}},
+			current: 0xFFFF,
+			want:    0xFFFE,
+		},
+		{
+			name: "Set and clear several bits",
+			bits: []*modbusv1alpha1.BooleanAddress{
+				{Address: 0, Value: true},
+				{Address: 4, Value: false},
+				{Address: 15, Value: true},
+			},
+			current: 0x0010,
+			want:    0x8001,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MapBitsToMask(tt.bits).Apply(tt.current); got != tt.want {
+				t.Errorf("MapBitsToMask().Apply(%#04x) = %#04x, want %#04x", tt.current, got, tt.want)
+			}
+		})
+	}
+}
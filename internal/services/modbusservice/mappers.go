@@ -2,6 +2,7 @@ package modbusservice
 
 import (
 	"encoding/binary"
+	"modbustohttp/internal/audit"
 	"modbustohttp/internal/utils"
 	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
 )
@@ -35,3 +36,16 @@ func MapByteArrayToRegisters(data []byte, startAddress uint32) []*modbusv1alpha1
 	}
 	return registers
 }
+
+// MapBitsToMask maps bits of a holding register, where the address of each bit is its position (0-15), to the mask
+// which sets each bit to its value and leaves the other bits unchanged.
+func MapBitsToMask(bits []*modbusv1alpha1.BooleanAddress) audit.Mask {
+	mask := audit.Mask{AndMask: 0xFFFF}
+	for _, bit := range bits {
+		mask.AndMask &^= 1 << bit.GetAddress()
+		if bit.GetValue() {
+			mask.OrMask |= 1 << bit.GetAddress()
+		}
+	}
+	return mask
+}
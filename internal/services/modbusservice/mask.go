@@ -0,0 +1,141 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"modbustohttp/internal/audit"
+	"modbustohttp/pkg/config"
+	"slices"
+
+	"connectrpc.com/connect"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// maskWriteRegister applies the mask to a holding register on behalf of the request. MaskWriteSingleRegister is used if
+// it is supported, as it is atomic. Otherwise, the register is read, modified and written back using
+// ReadHoldingRegisters and WriteSingleRegister, which is not atomic and can lead to race conditions if multiple clients
+// are writing to the same register.
+func (s Service) maskWriteRegister(
+	ctx context.Context,
+	req connect.AnyRequest,
+	address uint32,
+	mask audit.Mask,
+) error {
+	primaryEnabled := slices.Index(s.modbusConfig.FunctionsSupported, config.MaskWriteSingleRegister) >= 0
+	// Fallback is only possible if both WriteSingleRegister and ReadHoldingRegisters are supported
+	// as we need to read the current value of the register, modify the bits and write it back.
+	// If either of these functions is not supported, we cannot use the fallback method.
+	fallbackEnabled := slices.Index(s.modbusConfig.FunctionsSupported, config.WriteSingleRegister) >= 0 &&
+		slices.Index(s.modbusConfig.FunctionsSupported, config.ReadHoldingRegisters) >= 0
+	// If neither primary nor fallback method is possible, return unimplemented error.
+	if !primaryEnabled && !fallbackEnabled {
+		return connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	// The resulting value of the register depends on its current value, so only unlisted addresses can be rejected
+	// before connecting. The value is checked once the current value has been read.
+	guarded := s.guard != nil && s.guard.Covers(config.HoldingRegisters, address, 1)
+	if guarded {
+		if err := s.guard.CheckArm(config.HoldingRegisters, address, 1); err != nil {
+			return guardrailError(err)
+		}
+	} else if err := s.checkWrite(config.HoldingRegisters, address, []uint32{0}); err != nil {
+		return err
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return err
+	}
+	client := s.client(ctx)
+
+	if primaryEnabled {
+		// Reading the current value of the register is not necessary as MaskWriteSingleRegister modifies the bits
+		// directly, unless it is needed for the audit log or to check the write rules.
+		oldValues := s.readBeforeWrite(client, config.HoldingRegisters, address, 1)
+		if guarded {
+			if len(oldValues) != 1 {
+				oldValues, err = s.readCurrent(client, config.HoldingRegisters, address, 1)
+				if err != nil {
+					return connect.NewError(
+						connect.CodeFailedPrecondition,
+						fmt.Errorf("reading current value to check write rules: %w", err),
+					)
+				}
+			}
+			newValue := uint32(mask.Apply(uint16(oldValues[0])))
+			err = s.guard.Check(config.HoldingRegisters, address, []uint32{newValue}, oldValues)
+			if err != nil {
+				return guardrailError(err)
+			}
+		}
+		_, err = client.MaskWriteRegister(uint16(address), mask.AndMask, mask.OrMask)
+		event := audit.Event{
+			Table:     config.HoldingRegisters,
+			Address:   address,
+			OldValues: oldValues,
+			Mask:      &mask,
+		}
+		if len(oldValues) == 1 {
+			event.NewValues = []uint32{uint32(mask.Apply(uint16(oldValues[0])))}
+		}
+		s.recordWrite(ctx, req, event, err)
+		return err
+	}
+
+	// Read the current value of the register
+	currentData, err := client.ReadHoldingRegisters(uint16(address), 1)
+	if err != nil {
+		s.recordWrite(ctx, req, audit.Event{
+			Table:   config.HoldingRegisters,
+			Address: address,
+			Mask:    &mask,
+		}, err)
+		return err
+	}
+	currentValue := binary.BigEndian.Uint16(currentData)
+	newValue := mask.Apply(currentValue)
+	if guarded {
+		err = s.guard.Check(
+			config.HoldingRegisters,
+			address,
+			[]uint32{uint32(newValue)},
+			[]uint32{uint32(currentValue)},
+		)
+		if err != nil {
+			return guardrailError(err)
+		}
+	}
+
+	// Write the new value back to the register
+	_, err = client.WriteSingleRegister(uint16(address), newValue)
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.HoldingRegisters,
+		Address:   address,
+		OldValues: []uint32{uint32(currentValue)},
+		NewValues: []uint32{uint32(newValue)},
+		Mask:      &mask,
+	}, err)
+	return err
+}
+
+func (s Service) MaskWriteRegister(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.MaskWriteRegisterRequest],
+) (*connect.Response[modbusv1alpha1.MaskWriteRegisterResponse], error) {
+	mask := audit.Mask{AndMask: uint16(req.Msg.GetAndMask()), OrMask: uint16(req.Msg.GetOrMask())}
+	if err := s.maskWriteRegister(ctx, req, req.Msg.GetAddress(), mask); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.MaskWriteRegisterResponse{}), nil
+}
+
+func (s Service) WriteBitsInRegister(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.WriteBitsInRegisterRequest],
+) (*connect.Response[modbusv1alpha1.WriteBitsInRegisterResponse], error) {
+	if err := s.maskWriteRegister(ctx, req, req.Msg.GetAddress(), MapBitsToMask(req.Msg.GetBits())); err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&modbusv1alpha1.WriteBitsInRegisterResponse{}), nil
+}
@@ -3,56 +3,141 @@ package modbusservice
 import (
 	"context"
 	"encoding/binary"
+	"log/slog"
+	"modbustohttp/internal/arm"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/guardrails"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/ratelimit"
 	"modbustohttp/internal/utils"
 	"modbustohttp/pkg/config"
 	"slices"
-	"time"
 
 	"connectrpc.com/connect"
 	"github.com/goburrow/modbus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/retry.v1"
 
 	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
 )
 
 type Service struct {
-	modbusHandler *modbus.TCPClientHandler
+	modbusHandler *instrumentedHandler
 	modbusConfig  *config.Modbus
+	// breaker fails requests fast while the modbus server is unreachable. It is nil if the circuit breaker is disabled.
+	breaker *breaker.Breaker
+	// limiter limits the rate of the transactions sent to the modbus server. It is nil if the rate is not limited.
+	limiter *ratelimit.Limiter
+	// auditLogger records the writes made to the modbus server. It is nil if auditing is disabled.
+	auditLogger *audit.Logger
+	// guard checks writes against the write rules before they are sent. It is nil if guardrails are disabled.
+	guard *guardrails.Guard
+	// armStore holds the writes prepared by PrepareWrite until they are executed
+	armStore *arm.Store
+	// connectRetry, readRetry and writeRetry are the retry strategies of connecting, and of the transactions which
+	// read and which may write
+	connectRetry retry.Strategy
+	readRetry    retry.Strategy
+	writeRetry   retry.Strategy
+	logger       *slog.Logger
 }
 
-// connectModbus tries to connect to the Modbus server with a retry strategy.
-// It will keep trying to connect until the connection is successful or the timeout is reached.
-// If the connection is successful, it returns nil. If the timeout is reached, it returns the last error.
+// connectModbus tries to connect to the Modbus server with the connect retry strategy, logging each retry.
+// It will keep trying to connect until the connection is successful, the attempts run out, ctx is done or the circuit
+// breaker opens. If the connection is successful, it returns nil. Otherwise it returns a Connect error with the
+// Unavailable code, wrapping the last error or telling the client when to retry if the breaker is open.
 // If the connection is already established, it does nothing and returns nil.
-func (s Service) connectModbus() error {
-	strategy := retry.LimitTime(30*time.Second,
-		retry.Exponential{
-			Initial: 10 * time.Millisecond,
-			Factor:  1.5,
-		},
-	)
+// The connection and each attempt are recorded as spans which are children of the span in ctx.
+func (s Service) connectModbus(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "modbus.connect")
+	defer span.End()
+	if err := s.allowRequest(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 	var err error
-	for a := retry.Start(strategy, nil); a.Next(); {
+	for a := retry.StartWithCancel(s.connectRetry, nil, ctx.Done()); a.Next(); {
+		attempt := a.Count()
+		if attempt > 1 {
+			s.logger.Warn("retrying modbus connection",
+				slog.Int("attempt", attempt),
+				slog.String("error", err.Error()),
+			)
+		}
+		_, attemptSpan := tracer.Start(ctx, "modbus.connect.attempt",
+			trace.WithAttributes(attributeAttempt.Int(attempt)),
+		)
 		err = s.modbusHandler.Connect()
 		if err == nil {
+			attemptSpan.End()
 			return nil
 		}
+		attemptSpan.RecordError(err)
+		attemptSpan.SetStatus(codes.Error, err.Error())
+		attemptSpan.End()
+		// The failure may have opened the breaker, in which case retrying would only delay the response.
+		if breakerErr := s.allowRequest(); breakerErr != nil {
+			span.RecordError(breakerErr)
+			span.SetStatus(codes.Error, breakerErr.Error())
+			return breakerErr
+		}
+	}
+	if err == nil {
+		// No attempt was made, as ctx was already done.
+		err = ctx.Err()
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return connect.NewError(connect.CodeUnavailable, err)
+}
+
+// client returns a modbus.Client for making transactions on behalf of the request with the given context.
+func (s Service) client(ctx context.Context) modbus.Client {
+	return modbus.NewClient(s.handler(ctx))
+}
+
+// handler returns the modbus.ClientHandler used by client, for sending PDUs which modbus.Client does not support.
+// Failed transactions are retried, each attempt waits for the rate limit, and each attempt is recorded as a span.
+func (s Service) handler(ctx context.Context) modbus.ClientHandler {
+	return s.handlerForUnit(ctx, s.modbusConfig.SlaveID)
+}
+
+// handlerForUnit returns a handler like the one returned by handler, which sends the PDUs to the given unit ID.
+func (s Service) handlerForUnit(ctx context.Context, unitID byte) modbus.ClientHandler {
+	var handler modbus.ClientHandler = s.modbusHandler
+	if unitID != s.modbusConfig.SlaveID {
+		handler = unitHandler{ClientHandler: handler, unitID: unitID}
+	}
+	handler = tracedHandler{ClientHandler: handler, ctx: ctx, unitID: unitID}
+	// The limiter is inside the retries, so that every attempt sent to the modbus server takes a token.
+	if s.limiter != nil {
+		handler = limitedHandler{ClientHandler: handler, ctx: ctx, limiter: s.limiter}
+	}
+	return retryingHandler{
+		ClientHandler: handler,
+		ctx:           ctx,
+		read:          s.readRetry,
+		write:         s.writeRetry,
+		breaker:       s.breaker,
+		logger:        s.logger,
 	}
-	return err
 }
 
 func (s Service) ReadHoldingRegisters(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.ReadHoldingRegistersRequest],
 ) (*connect.Response[modbusv1alpha1.ReadHoldingRegistersResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadHoldingRegisters) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
 	modbusData, err := client.ReadHoldingRegisters(
 		uint16(req.Msg.GetAddress()),
 		uint16(req.Msg.GetQuantity()),
@@ -66,19 +151,34 @@ func (s Service) ReadHoldingRegisters(
 }
 
 func (s Service) WriteSingleRegister(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.WriteSingleRegisterRequest],
 ) (*connect.Response[modbusv1alpha1.WriteSingleRegisterResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.WriteSingleRegister) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	newValues := []uint32{req.Msg.GetRegister().Value}
+	if err := s.checkWrite(config.HoldingRegisters, req.Msg.GetRegister().Address, newValues); err != nil {
+		return nil, err
+	}
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
+	err = s.checkWriteStep(client, config.HoldingRegisters, req.Msg.GetRegister().Address, newValues)
+	if err != nil {
+		return nil, err
+	}
+	oldValues := s.readBeforeWrite(client, config.HoldingRegisters, req.Msg.GetRegister().Address, 1)
 
 	_, err = client.WriteSingleRegister(uint16(req.Msg.GetRegister().Address), uint16(req.Msg.GetRegister().Value))
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.HoldingRegisters,
+		Address:   req.Msg.GetRegister().Address,
+		OldValues: oldValues,
+		NewValues: newValues,
+	}, err)
 	if err != nil {
 		return nil, err
 	}
@@ -86,17 +186,17 @@ func (s Service) WriteSingleRegister(
 }
 
 func (s Service) ReadCoils(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.ReadCoilsRequest],
 ) (*connect.Response[modbusv1alpha1.ReadCoilsResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadCoils) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
 
 	data, err := client.ReadCoils(uint16(req.Msg.GetAddress()), uint16(req.Msg.GetQuantity()))
 	if err != nil {
@@ -108,17 +208,17 @@ func (s Service) ReadCoils(
 }
 
 func (s Service) ReadDiscreteInputs(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.ReadDiscreteInputsRequest],
 ) (*connect.Response[modbusv1alpha1.ReadDiscreteInputsResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadDiscreteInputs) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
 
 	data, err := client.ReadDiscreteInputs(uint16(req.Msg.GetAddress()), uint16(req.Msg.GetQuantity()))
 	if err != nil {
@@ -133,29 +233,44 @@ func (s Service) ReadDiscreteInputs(
 }
 
 func (s Service) WriteSingleCoil(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.WriteSingleCoilRequest],
 ) (*connect.Response[modbusv1alpha1.WriteSingleCoilResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.WriteSingleCoil) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
-	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
-	}
-	client := modbus.NewClient(s.modbusHandler)
 	var value uint16
+	var auditValue uint32
 	switch req.Msg.GetCoil().Value {
 	case true:
 		value = 0xFF00
+		auditValue = 1
 	case false:
 		value = 0x0000
 	}
+	if err := s.checkWrite(config.Coils, req.Msg.GetCoil().Address, []uint32{auditValue}); err != nil {
+		return nil, err
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := s.client(ctx)
+	if err := s.checkWriteStep(client, config.Coils, req.Msg.GetCoil().Address, []uint32{auditValue}); err != nil {
+		return nil, err
+	}
+	oldValues := s.readBeforeWrite(client, config.Coils, req.Msg.GetCoil().Address, 1)
 
 	_, err = client.WriteSingleCoil(
 		uint16(req.Msg.GetCoil().Address),
 		value,
 	)
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.Coils,
+		Address:   req.Msg.GetCoil().Address,
+		OldValues: oldValues,
+		NewValues: []uint32{auditValue},
+	}, err)
 	if err != nil {
 		return nil, err
 	}
@@ -163,19 +278,38 @@ func (s Service) WriteSingleCoil(
 }
 
 func (s Service) WriteMultipleCoils(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.WriteMultipleCoilsRequest],
 ) (*connect.Response[modbusv1alpha1.WriteMultipleCoilsResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.WriteMultipleCoils) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	newValues := make([]uint32, len(req.Msg.GetValues()))
+	for i, value := range req.Msg.GetValues() {
+		if value {
+			newValues[i] = 1
+		}
+	}
+	if err := s.checkWrite(config.Coils, req.Msg.GetAddress(), newValues); err != nil {
+		return nil, err
+	}
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
+	}
+	client := s.client(ctx)
+	if err := s.checkWriteStep(client, config.Coils, req.Msg.GetAddress(), newValues); err != nil {
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	oldValues := s.readBeforeWrite(client, config.Coils, req.Msg.GetAddress(), uint32(len(req.Msg.GetValues())))
 	data := utils.BoolSliceToByteSlice(req.Msg.GetValues())
 	_, err = client.WriteMultipleCoils(uint16(req.Msg.GetAddress()), uint16(len(req.Msg.GetValues())), data)
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.Coils,
+		Address:   req.Msg.GetAddress(),
+		OldValues: oldValues,
+		NewValues: newValues,
+	}, err)
 	if err != nil {
 		return nil, err
 	}
@@ -183,17 +317,17 @@ func (s Service) WriteMultipleCoils(
 }
 
 func (s Service) ReadInputRegisters(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.ReadInputRegistersRequest],
 ) (*connect.Response[modbusv1alpha1.ReadInputRegistersResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadInputRegisters) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
 	modbusData, err := client.ReadInputRegisters(
 		uint16(req.Msg.GetAddress()),
 		uint16(req.Msg.GetQuantity()),
@@ -207,22 +341,40 @@ func (s Service) ReadInputRegisters(
 }
 
 func (s Service) WriteMultipleRegisters(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.WriteMultipleRegistersRequest],
 ) (*connect.Response[modbusv1alpha1.WriteMultipleRegistersResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.WriteMultipleRegisters) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	if err := s.checkWrite(config.HoldingRegisters, req.Msg.GetAddress(), req.Msg.GetValues()); err != nil {
+		return nil, err
+	}
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
+	if err := s.checkWriteStep(client, config.HoldingRegisters, req.Msg.GetAddress(), req.Msg.GetValues()); err != nil {
+		return nil, err
+	}
+	oldValues := s.readBeforeWrite(
+		client,
+		config.HoldingRegisters,
+		req.Msg.GetAddress(),
+		uint32(len(req.Msg.GetValues())),
+	)
 	data := make([]byte, len(req.Msg.GetValues())*2)
 	for i, value := range req.Msg.GetValues() {
 		binary.BigEndian.PutUint16(data[i*2:i*2+2], uint16(value))
 	}
 	_, err = client.WriteMultipleRegisters(uint16(req.Msg.GetAddress()), uint16(len(req.Msg.GetValues())), data)
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.HoldingRegisters,
+		Address:   req.Msg.GetAddress(),
+		OldValues: oldValues,
+		NewValues: req.Msg.GetValues(),
+	}, err)
 	if err != nil {
 		return nil, err
 	}
@@ -230,86 +382,28 @@ func (s Service) WriteMultipleRegisters(
 }
 
 func (s Service) WriteBitInRegister(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.WriteBitInRegisterRequest],
 ) (*connect.Response[modbusv1alpha1.WriteBitInRegisterResponse], error) {
-	primaryEnabled := slices.Index(s.modbusConfig.FunctionsSupported, config.MaskWriteSingleRegister) >= 0
-	// Fallback is only possible if both WriteSingleRegister and ReadHoldingRegisters are supported
-	// as we need to read the current value of the register, modify the specific bit and write it back.
-	// If either of these functions is not supported, we cannot use the fallback method.
-	fallbackEnabled := slices.Index(s.modbusConfig.FunctionsSupported, config.WriteSingleRegister) >= 0 &&
-		slices.Index(s.modbusConfig.FunctionsSupported, config.ReadHoldingRegisters) >= 0
-	// If neither primary nor fallback method is possible, return unimplemented error.
-	if !primaryEnabled && !fallbackEnabled {
-		return nil, connect.NewError(connect.CodeUnimplemented, nil)
-	}
-	err := s.connectModbus()
-	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
-	}
-	client := modbus.NewClient(s.modbusHandler)
-
-	if primaryEnabled {
-		// Use MaskWriteSingleRegister if supported as it is atomic and therefore will not lead to race conditions.
-		// Read the current value of the register is not necessary as MaskWriteSingleRegister allows to modify
-		// specific bits directly.
-
-		var orMask uint16
-		var andMask uint16
-		if req.Msg.GetValue() {
-			andMask = ^uint16(0)           // All bits set to 1
-			orMask = 1 << req.Msg.GetBit() // Only the target bit set to 1
-		} else {
-			andMask = ^(1 << req.Msg.GetBit()) // Only the target bit set to 0
-			// orMask has all bits set to 0 by default
-		}
-
-		_, err = client.MaskWriteRegister(uint16(req.Msg.GetAddress()), andMask, orMask)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// Fallback if MaskWriteSingleRegister is not supported. Will read the register, modify the bit and write it
-		// back. Warning: This is not atomic and can lead to race conditions if multiple clients are writing to the same
-		// register.
-
-		// Read the current value of the register
-		currentData, err := client.ReadHoldingRegisters(uint16(req.Msg.GetAddress()), 1)
-		if err != nil {
-			return nil, err
-		}
-		currentValue := binary.BigEndian.Uint16(currentData)
-
-		// Modify the specific bit
-		var newValue uint16
-		if req.Msg.GetValue() {
-			newValue = currentValue | (1 << req.Msg.GetBit())
-		} else {
-			newValue = currentValue & ^(1 << req.Msg.GetBit())
-		}
-
-		// Write the new value back to the register
-		_, err = client.WriteSingleRegister(uint16(req.Msg.GetAddress()), newValue)
-		if err != nil {
-			return nil, err
-		}
+	mask := MapBitsToMask([]*modbusv1alpha1.BooleanAddress{{Address: req.Msg.GetBit(), Value: req.Msg.GetValue()}})
+	if err := s.maskWriteRegister(ctx, req, req.Msg.GetAddress(), mask); err != nil {
+		return nil, err
 	}
-
 	return connect.NewResponse(&modbusv1alpha1.WriteBitInRegisterResponse{}), nil
 }
 
 func (s Service) ReadRegisterAsBits(
-	_ context.Context,
+	ctx context.Context,
 	req *connect.Request[modbusv1alpha1.ReadRegisterAsBitsRequest],
 ) (*connect.Response[modbusv1alpha1.ReadRegisterAsBitsResponse], error) {
 	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadHoldingRegisters) == -1 {
 		return nil, connect.NewError(connect.CodeUnimplemented, nil)
 	}
-	err := s.connectModbus()
+	err := s.connectModbus(ctx)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeUnavailable, err)
+		return nil, err
 	}
-	client := modbus.NewClient(s.modbusHandler)
+	client := s.client(ctx)
 
 	data, err := client.ReadHoldingRegisters(uint16(req.Msg.GetAddress()), 1)
 	if err != nil {
@@ -332,9 +426,79 @@ func (s Service) ReadRegisterAsBits(
 	return connect.NewResponse(&response), nil
 }
 
-func NewService(modbusHandler *modbus.TCPClientHandler, modbusConfig *config.Modbus) *Service {
+func (s Service) ReadWriteMultipleRegisters(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.ReadWriteMultipleRegistersRequest],
+) (*connect.Response[modbusv1alpha1.ReadWriteMultipleRegistersResponse], error) {
+	if slices.Index(s.modbusConfig.FunctionsSupported, config.ReadWriteMultipleRegisters) == -1 {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	if err := s.checkWrite(config.HoldingRegisters, req.Msg.GetWriteAddress(), req.Msg.GetValues()); err != nil {
+		return nil, err
+	}
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := s.client(ctx)
+	err = s.checkWriteStep(client, config.HoldingRegisters, req.Msg.GetWriteAddress(), req.Msg.GetValues())
+	if err != nil {
+		return nil, err
+	}
+	oldValues := s.readBeforeWrite(
+		client,
+		config.HoldingRegisters,
+		req.Msg.GetWriteAddress(),
+		uint32(len(req.Msg.GetValues())),
+	)
+	data := make([]byte, len(req.Msg.GetValues())*2)
+	for i, value := range req.Msg.GetValues() {
+		binary.BigEndian.PutUint16(data[i*2:i*2+2], uint16(value))
+	}
+	// The write is performed before the read, so the registers read include the values written if the ranges overlap.
+	modbusData, err := client.ReadWriteMultipleRegisters(
+		uint16(req.Msg.GetReadAddress()),
+		uint16(req.Msg.GetReadQuantity()),
+		uint16(req.Msg.GetWriteAddress()),
+		uint16(len(req.Msg.GetValues())),
+		data,
+	)
+	s.recordWrite(ctx, req, audit.Event{
+		Table:     config.HoldingRegisters,
+		Address:   req.Msg.GetWriteAddress(),
+		OldValues: oldValues,
+		NewValues: req.Msg.GetValues(),
+	}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	registers := MapByteArrayToRegisters(modbusData, req.Msg.GetReadAddress())
+	return connect.NewResponse(&modbusv1alpha1.ReadWriteMultipleRegistersResponse{Registers: registers}), nil
+}
+
+func NewService(
+	modbusHandler connectionHandler,
+	modbusConfig *config.Modbus,
+	serviceMetrics *metrics.Metrics,
+	circuitBreaker *breaker.Breaker,
+	limiter *ratelimit.Limiter,
+	auditLogger *audit.Logger,
+	guard *guardrails.Guard,
+	armStore *arm.Store,
+	logger *slog.Logger,
+) *Service {
 	return &Service{
-		modbusHandler,
+		newInstrumentedHandler(modbusHandler, serviceMetrics, circuitBreaker),
 		modbusConfig,
+		circuitBreaker,
+		limiter,
+		auditLogger,
+		guard,
+		armStore,
+		retryStrategy(modbusConfig.Retry.Connect, defaultConnectRetry),
+		retryStrategy(modbusConfig.Retry.Read, defaultReadRetry),
+		retryStrategy(modbusConfig.Retry.Write, defaultWriteRetry),
+		logger,
 	}
 }
@@ -0,0 +1,60 @@
+package modbusservice
+
+import (
+	"fmt"
+
+	"github.com/goburrow/modbus"
+)
+
+// sendPDU sends a request PDU through the handler and returns the response PDU, in the same way as modbus.Client does
+// for the function codes it supports. It returns a *modbus.ModbusError if the modbus server returns an exception.
+func sendPDU(handler modbus.ClientHandler, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error) {
+	response, err := sendRawPDU(handler, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.FunctionCode != request.FunctionCode {
+		if response.FunctionCode == request.FunctionCode|0x80 && len(response.Data) > 0 {
+			return nil, &modbus.ModbusError{FunctionCode: response.FunctionCode, ExceptionCode: response.Data[0]}
+		}
+		return nil, fmt.Errorf("modbus: response function code '%v' does not match request '%v'",
+			response.FunctionCode, request.FunctionCode)
+	}
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("modbus: response data is empty")
+	}
+	return response, nil
+}
+
+// sendRawPDU sends a request PDU through the handler and returns the response PDU, which may be an exception response.
+func sendRawPDU(handler modbus.ClientHandler, request *modbus.ProtocolDataUnit) (*modbus.ProtocolDataUnit, error) {
+	aduRequest, err := handler.Encode(request)
+	if err != nil {
+		return nil, err
+	}
+	aduResponse, err := handler.Send(aduRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := handler.Verify(aduRequest, aduResponse); err != nil {
+		return nil, err
+	}
+	return handler.Decode(aduResponse)
+}
+
+// unitHandler wraps the Modbus TCP handler to address requests to the given unit ID instead of the configured slave
+// ID, without changing the handler shared by other requests.
+type unitHandler struct {
+	modbus.ClientHandler
+	unitID byte
+}
+
+// Encode encodes the request PDU, replacing the unit identifier in the MBAP header.
+func (h unitHandler) Encode(pdu *modbus.ProtocolDataUnit) ([]byte, error) {
+	adu, err := h.ClientHandler.Encode(pdu)
+	if err != nil {
+		return nil, err
+	}
+	adu[6] = h.unitID
+	return adu, nil
+}
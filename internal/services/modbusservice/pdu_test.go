@@ -0,0 +1,25 @@
+package modbusservice
+
+import (
+	"encoding/binary"
+
+	"github.com/goburrow/modbus"
+)
+
+// pduHandler is a modbus.ClientHandler which answers each request PDU with the PDU returned by respond, using the
+// Modbus TCP framing.
+type pduHandler struct {
+	*modbus.TCPClientHandler
+	respond func(request []byte) []byte
+}
+
+func newPDUHandler(respond func(request []byte) []byte) pduHandler {
+	return pduHandler{TCPClientHandler: modbus.NewTCPClientHandler("localhost:502"), respond: respond}
+}
+
+func (h pduHandler) Send(aduRequest []byte) ([]byte, error) {
+	pdu := h.respond(aduRequest[7:])
+	aduResponse := append(append([]byte{}, aduRequest[:7]...), pdu...)
+	binary.BigEndian.PutUint16(aduResponse[4:], uint16(len(pdu)+1))
+	return aduResponse, nil
+}
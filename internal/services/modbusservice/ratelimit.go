@@ -0,0 +1,36 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"modbustohttp/internal/ratelimit"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+)
+
+// limitedHandler wraps a modbus.ClientHandler to limit the rate of the transactions sent to the modbus server on
+// behalf of a single request. Transactions over the limit queue for their turn, and fail with the ResourceExhausted
+// code if it would not come in time.
+type limitedHandler struct {
+	modbus.ClientHandler
+	ctx     context.Context
+	limiter *ratelimit.Limiter
+}
+
+// Send sends a single Modbus transaction once the rate limit allows it.
+func (h limitedHandler) Send(aduRequest []byte) ([]byte, error) {
+	if err := h.limiter.Wait(h.ctx); err != nil {
+		var exceededErr *ratelimit.ExceededError
+		if errors.As(err, &exceededErr) {
+			return nil, retryAfterError(
+				connect.CodeResourceExhausted,
+				fmt.Errorf("modbus server: %w", exceededErr),
+				exceededErr.RetryAfter,
+			)
+		}
+		return nil, err
+	}
+	return h.ClientHandler.Send(aduRequest)
+}
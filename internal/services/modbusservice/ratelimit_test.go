@@ -0,0 +1,105 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/internal/proxy"
+	"modbustohttp/internal/ratelimit"
+	"modbustohttp/pkg/config"
+	"net"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLimitedHandler(t *testing.T) {
+	flaky := &flakyHandler{TCPClientHandler: modbus.NewTCPClientHandler("")}
+	client := modbus.NewClient(limitedHandler{
+		ClientHandler: flaky,
+		ctx:           context.Background(),
+		limiter:       ratelimit.New(&config.RateLimit{Rate: 1, Burst: 1}),
+	})
+
+	if _, err := client.ReadHoldingRegisters(0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	_, err := client.ReadHoldingRegisters(0, 1)
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("ReadHoldingRegisters() over the limit error = %v, want resource exhausted", err)
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) && connectErr.Meta().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", connectErr.Meta().Get("Retry-After"))
+	}
+	if flaky.sent != 1 {
+		t.Errorf("sent %d times, want 1", flaky.sent)
+	}
+}
+
+func TestService_RateLimitRetries(t *testing.T) {
+	flaky := &flakyHandler{TCPClientHandler: modbus.NewTCPClientHandler(""), failures: 2, err: errors.New("reset")}
+	service := NewService(flaky,
+		&config.Modbus{Retry: config.Retry{Read: config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}}},
+		metrics.New(prometheus.NewRegistry()), nil, ratelimit.New(&config.RateLimit{Rate: 1, Burst: 1}), nil, nil, nil,
+		slog.New(slog.DiscardHandler),
+	)
+
+	// Each attempt takes a token, so the retry after the first attempt fails is over the limit and not sent.
+	_, err := modbus.NewClient(service.handler(context.Background())).ReadHoldingRegisters(0, 1)
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Errorf("ReadHoldingRegisters() error = %v, want resource exhausted", err)
+	}
+	if flaky.sent != 1 {
+		t.Errorf("sent %d times, want 1", flaky.sent)
+	}
+}
+
+func TestService_RateLimitProxy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modbusServer := modbusserver.NewServer(modbusserver.NewSimulator())
+	go func() { _ = modbusServer.Serve(listener) }()
+	t.Cleanup(func() { _ = modbusServer.Close() })
+	modbusHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+	modbusHandler.Timeout = time.Second
+	modbusHandler.SlaveId = 1
+	t.Cleanup(func() { _ = modbusHandler.Close() })
+	modbusConfig := &config.Modbus{SlaveID: 1, FunctionsSupported: []config.ModbusFunction{config.ReadHoldingRegisters}}
+	service := NewService(modbusHandler, modbusConfig, metrics.New(prometheus.NewRegistry()), nil,
+		ratelimit.New(&config.RateLimit{Rate: 1, Burst: 1}), nil, nil, nil, slog.New(slog.DiscardHandler),
+	)
+
+	modbusProxy, err := proxy.New(&config.Proxy{}, modbusConfig, service, nil, nil, nil, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyServer := modbusserver.NewServer(modbusProxy)
+	go func() { _ = proxyServer.Serve(proxyListener) }()
+	t.Cleanup(func() { _ = proxyServer.Close() })
+	clientHandler := modbus.NewTCPClientHandler(proxyListener.Addr().String())
+	clientHandler.Timeout = time.Second
+	t.Cleanup(func() { _ = clientHandler.Close() })
+	client := modbus.NewClient(clientHandler)
+
+	// The proxied transactions share the device's rate limit with the RPCs.
+	if _, err := client.ReadHoldingRegisters(0, 1); err != nil {
+		t.Fatalf("ReadHoldingRegisters() error = %v", err)
+	}
+	_, err = client.ReadHoldingRegisters(1, 1)
+	var modbusErr *modbus.ModbusError
+	if !errors.As(err, &modbusErr) || modbusErr.ExceptionCode != modbus.ExceptionCodeServerDeviceBusy {
+		t.Errorf("ReadHoldingRegisters() over the limit error = %v, want server device busy", err)
+	}
+}
@@ -0,0 +1,101 @@
+package modbusservice
+
+import (
+	"context"
+	"fmt"
+	"modbustohttp/internal/audit"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+)
+
+// publicFunctionCodes are the function codes defined by the Modbus specification. Their requests must be sent with the
+// RPCs for them, so that writes are checked against the write rules and recorded with their addresses and values.
+var publicFunctionCodes = []byte{1, 2, 3, 4, 5, 6, 7, 8, 11, 12, 15, 16, 17, 20, 21, 22, 23, 24, 43}
+
+// CheckRawPDUFunctionCodes returns an error if a function code which can be configured to be sent by SendRawPDU is
+// defined by the Modbus specification or is not a valid function code.
+func CheckRawPDUFunctionCodes(functionCodes []byte) error {
+	for _, functionCode := range functionCodes {
+		if functionCode == 0 || functionCode >= 0x80 {
+			return fmt.Errorf("raw PDU function code %d is not a valid function code", functionCode)
+		}
+		if slices.Contains(publicFunctionCodes, functionCode) {
+			return fmt.Errorf("raw PDU function code %d is defined by the modbus specification", functionCode)
+		}
+	}
+	return nil
+}
+
+// rawPDUFunctionCodeAllowed returns whether the function code can be sent by SendRawPDU. The user-defined function
+// codes are allowed if no function codes are configured. The function codes defined by the Modbus specification are
+// never allowed.
+func (s Service) rawPDUFunctionCodeAllowed(functionCode byte) bool {
+	if slices.Contains(publicFunctionCodes, functionCode) {
+		return false
+	}
+	if len(s.modbusConfig.RawPDU.FunctionCodes) > 0 {
+		return slices.Contains(s.modbusConfig.RawPDU.FunctionCodes, functionCode)
+	}
+	return (functionCode >= 65 && functionCode <= 72) || (functionCode >= 100 && functionCode <= 110)
+}
+
+func (s Service) SendRawPDU(
+	ctx context.Context,
+	req *connect.Request[modbusv1alpha1.SendRawPDURequest],
+) (*connect.Response[modbusv1alpha1.SendRawPDUResponse], error) {
+	if !s.modbusConfig.RawPDU.Enabled {
+		return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	}
+	functionCode := byte(req.Msg.GetFunctionCode())
+	if !s.rawPDUFunctionCodeAllowed(functionCode) {
+		return nil, connect.NewError(connect.CodePermissionDenied,
+			fmt.Errorf("function code %d is not allowed to be sent as a raw PDU", functionCode))
+	}
+	unitID := s.modbusConfig.SlaveID
+	if req.Msg.UnitId != nil {
+		unitID = byte(req.Msg.GetUnitId())
+	}
+	response, err := s.Forward(ctx, unitID, &modbus.ProtocolDataUnit{
+		FunctionCode: functionCode,
+		Data:         req.Msg.GetData(),
+	})
+	// What a raw PDU writes is not known, so every PDU sent is recorded.
+	recordErr := err
+	if err == nil && response.FunctionCode == functionCode|0x80 && len(response.Data) > 0 {
+		recordErr = &modbus.ModbusError{FunctionCode: functionCode, ExceptionCode: response.Data[0]}
+	}
+	s.recordWrite(ctx, req, audit.Event{FunctionCode: functionCode, Data: req.Msg.GetData(), UnitID: unitID}, recordErr)
+	if err != nil {
+		return nil, err
+	}
+
+	rawResponse := &modbusv1alpha1.SendRawPDUResponse{
+		FunctionCode: uint32(response.FunctionCode),
+		Data:         response.Data,
+	}
+	if response.FunctionCode == functionCode|0x80 && len(response.Data) > 0 {
+		exceptionCode := uint32(response.Data[0])
+		rawResponse.ExceptionCode = &exceptionCode
+	}
+	return connect.NewResponse(rawResponse), nil
+}
+
+// Forward sends a request PDU to the given unit ID over the connection used by the RPCs, and returns the response PDU,
+// which may be an exception response. The request is rate limited and retried as the RPCs' transactions are. It returns
+// a *connect.Error with the Unavailable code if the modbus server cannot be connected to, or with the ResourceExhausted
+// code if the request is over the rate limit.
+func (s Service) Forward(
+	ctx context.Context,
+	unitID byte,
+	request *modbus.ProtocolDataUnit,
+) (*modbus.ProtocolDataUnit, error) {
+	err := s.connectModbus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sendRawPDU(s.handlerForUnit(ctx, unitID), request)
+}
@@ -0,0 +1,81 @@
+package modbusservice
+
+import (
+	"modbustohttp/pkg/config"
+	"testing"
+
+	"github.com/goburrow/modbus"
+)
+
+func TestService_rawPDUFunctionCodeAllowed(t *testing.T) {
+	userDefined := Service{modbusConfig: &config.Modbus{}}
+	configured := Service{modbusConfig: &config.Modbus{RawPDU: config.RawPDU{FunctionCodes: []byte{66, 16}}}}
+	tests := []struct {
+		name         string
+		service      Service
+		functionCode byte
+		want         bool
+	}{
+		{name: "User-defined", service: userDefined, functionCode: 65, want: true},
+		{name: "Standard", service: userDefined, functionCode: modbus.FuncCodeWriteSingleRegister, want: false},
+		{name: "Configured", service: configured, functionCode: 66, want: true},
+		{name: "Not configured", service: configured, functionCode: 65, want: false},
+		{name: "Public", service: configured, functionCode: modbus.FuncCodeWriteMultipleRegisters, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.service.rawPDUFunctionCodeAllowed(tt.functionCode); got != tt.want {
+				t.Errorf("rawPDUFunctionCodeAllowed(%d) = %v, want %v", tt.functionCode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRawPDUFunctionCodes(t *testing.T) {
+	tests := []struct {
+		name          string
+		functionCodes []byte
+		wantErr       bool
+	}{
+		{name: "User-defined", functionCodes: []byte{65, 110}},
+		{name: "Reserved", functionCodes: []byte{90}},
+		{name: "Public", functionCodes: []byte{65, modbus.FuncCodeWriteSingleCoil}, wantErr: true},
+		{name: "Exception", functionCodes: []byte{65 | 0x80}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRawPDUFunctionCodes(tt.functionCodes); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRawPDUFunctionCodes(%v) error = %v, want error %v", tt.functionCodes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendRawPDU_Exception(t *testing.T) {
+	var unitID byte
+	handler := newPDUHandler(func([]byte) []byte { return []byte{65 | 0x80, modbus.ExceptionCodeIllegalFunction} })
+	handler.TCPClientHandler.SlaveId = 1
+	response, err := sendRawPDU(unitHandler{ClientHandler: recordUnitID{handler, &unitID}, unitID: 7},
+		&modbus.ProtocolDataUnit{FunctionCode: 65, Data: []byte{1, 2}},
+	)
+	if err != nil {
+		t.Fatalf("sendRawPDU() error = %v", err)
+	}
+	if response.FunctionCode != 65|0x80 || response.Data[0] != modbus.ExceptionCodeIllegalFunction {
+		t.Errorf("sendRawPDU() = %v, want illegal function exception", response)
+	}
+	if unitID != 7 {
+		t.Errorf("sendRawPDU() sent to unit ID %d, want %d", unitID, 7)
+	}
+}
+
+// recordUnitID records the unit ID of the requests sent through the handler.
+type recordUnitID struct {
+	pduHandler
+	unitID *byte
+}
+
+func (h recordUnitID) Send(aduRequest []byte) ([]byte, error) {
+	*h.unitID = aduRequest[6]
+	return h.pduHandler.Send(aduRequest)
+}
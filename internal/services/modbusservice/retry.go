@@ -0,0 +1,139 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/modbuspool"
+	"modbustohttp/pkg/config"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/goburrow/modbus"
+	"gopkg.in/retry.v1"
+)
+
+var (
+	// defaultConnectRetry is the policy for connecting, used for the fields of the configured policy which are zero.
+	defaultConnectRetry = config.RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+	}
+	// defaultReadRetry is the policy for reads, used for the fields of the configured policy which are zero.
+	defaultReadRetry = config.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+	// defaultWriteRetry is the policy for writes, used for the fields of the configured policy which are zero.
+	defaultWriteRetry = config.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+)
+
+// retryStrategy returns the exponential backoff strategy of the policy, using the defaults for the fields which are
+// zero.
+func retryStrategy(policy config.RetryPolicy, defaults config.RetryPolicy) retry.Strategy {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = defaults.InitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = defaults.MaxBackoff
+	}
+	return retry.LimitCount(policy.MaxAttempts, retry.Exponential{
+		Initial:  policy.InitialBackoff,
+		Factor:   policy.Multiplier,
+		MaxDelay: policy.MaxBackoff,
+		Jitter:   policy.Jitter,
+	})
+}
+
+// retryingHandler wraps a modbus.ClientHandler to retry the failed transactions made on behalf of a single request.
+// Reads are retried after any transport error, while writes are only retried if the request was never sent, as a
+// write which may have been delivered could otherwise be applied twice. Exception responses and transactions over the
+// rate limit are never retried.
+type retryingHandler struct {
+	modbus.ClientHandler
+	ctx   context.Context
+	read  retry.Strategy
+	write retry.Strategy
+	// breaker stops the retries once it opens. It is nil if the circuit breaker is disabled.
+	breaker *breaker.Breaker
+	logger  *slog.Logger
+}
+
+// Send sends a single Modbus transaction, retrying it according to the policy for reads or writes until it succeeds,
+// the attempts run out or the context is done.
+func (h retryingHandler) Send(aduRequest []byte) ([]byte, error) {
+	kind, strategy := "write", h.write
+	functionCode := -1
+	if request, err := h.Decode(aduRequest); err == nil {
+		functionCode = int(request.FunctionCode)
+		if isRead(request) {
+			kind, strategy = "read", h.read
+		}
+	}
+
+	var aduResponse []byte
+	var err error
+	for attempt := retry.StartWithCancel(strategy, nil, h.ctx.Done()); attempt.Next(); {
+		if attempt.Count() > 1 {
+			h.logger.Warn("retrying modbus transaction",
+				slog.String("kind", kind),
+				slog.Int("function_code", functionCode),
+				slog.Int("attempt", attempt.Count()),
+				slog.String("error", err.Error()),
+			)
+		}
+		aduResponse, err = h.ClientHandler.Send(aduRequest)
+		// A transaction over the rate limit has already waited as long as it can for its turn.
+		if err == nil || connect.CodeOf(err) == connect.CodeResourceExhausted ||
+			(kind == "write" && !errors.Is(err, modbuspool.ErrNotSent)) {
+			return aduResponse, err
+		}
+		if h.breaker != nil && h.breaker.State() == breaker.Open {
+			break
+		}
+	}
+	if err == nil {
+		// No attempt was made, as the context was already done.
+		return nil, h.ctx.Err()
+	}
+	return aduResponse, err
+}
+
+// isRead returns whether the request only reads, so that sending it more than once has no effect on the device.
+// Requests with unknown function codes, such as raw PDUs, are assumed to write.
+func isRead(request *modbus.ProtocolDataUnit) bool {
+	switch request.FunctionCode {
+	case modbus.FuncCodeReadCoils,
+		modbus.FuncCodeReadDiscreteInputs,
+		modbus.FuncCodeReadHoldingRegisters,
+		modbus.FuncCodeReadInputRegisters,
+		modbus.FuncCodeReadFIFOQueue,
+		funcCodeGetCommEventCounter,
+		funcCodeGetCommEventLog,
+		funcCodeReadFileRecord,
+		funcCodeEncapsulatedInterface:
+		return true
+	case funcCodeDiagnostics:
+		// Only the subfunctions which return data or read a counter are reads, as others clear the counters or restart
+		// communications.
+		if len(request.Data) < 2 {
+			return false
+		}
+		subfunction := binary.BigEndian.Uint16(request.Data)
+		return subfunction == subfunctionReturnQueryData ||
+			(subfunction >= subfunctionBusMessageCount && subfunction <= subfunctionServerNoResponseCount)
+	default:
+		return false
+	}
+}
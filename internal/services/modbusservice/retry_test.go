@@ -0,0 +1,114 @@
+package modbusservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"modbustohttp/internal/modbuspool"
+	"modbustohttp/pkg/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goburrow/modbus"
+)
+
+// flakyHandler is a modbus.ClientHandler whose transactions fail until failures run out, and then return a canned
+// response.
+type flakyHandler struct {
+	*modbus.TCPClientHandler
+	failures int
+	err      error
+	sent     int
+}
+
+func (f *flakyHandler) Send(_ []byte) ([]byte, error) {
+	f.sent++
+	if f.failures > 0 {
+		f.failures--
+		return nil, f.err
+	}
+	return tcpADU(0x03, 0x02, 0x00, 0x01), nil
+}
+
+func TestRetryingHandler(t *testing.T) {
+	errReset := errors.New("connection reset")
+	errNotSent := fmt.Errorf("%w: connection refused", modbuspool.ErrNotSent)
+	tests := []struct {
+		name     string
+		request  []byte
+		failures int
+		err      error
+		wantSent int
+		wantErr  bool
+	}{
+		{name: "Read", request: tcpADU(0x03, 0, 0, 0, 1), failures: 2, err: errReset, wantSent: 3},
+		{name: "Read attempts run out", request: tcpADU(0x03, 0, 0, 0, 1), failures: 3, err: errReset, wantSent: 3,
+			wantErr: true},
+		{name: "Write possibly delivered", request: tcpADU(0x06, 0, 0, 0, 1), failures: 1, err: errReset, wantSent: 1,
+			wantErr: true},
+		{name: "Write not sent", request: tcpADU(0x06, 0, 0, 0, 1), failures: 2, err: errNotSent, wantSent: 3},
+		{name: "Diagnostics clear counters", request: tcpADU(0x08, 0, subfunctionClearCounters, 0, 0), failures: 1,
+			err: errReset, wantSent: 1, wantErr: true},
+		{name: "Diagnostics counter", request: tcpADU(0x08, 0, subfunctionBusMessageCount, 0, 0), failures: 1,
+			err: errReset, wantSent: 2},
+	}
+	policy := config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyHandler{TCPClientHandler: modbus.NewTCPClientHandler(""), failures: tt.failures, err: tt.err}
+			handler := retryingHandler{
+				ClientHandler: flaky,
+				ctx:           context.Background(),
+				read:          retryStrategy(policy, defaultReadRetry),
+				write:         retryStrategy(policy, defaultWriteRetry),
+				logger:        slog.New(slog.DiscardHandler),
+			}
+			_, err := handler.Send(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if flaky.sent != tt.wantSent {
+				t.Errorf("sent %d times, want %d", flaky.sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryingHandler_Logger(t *testing.T) {
+	var logs bytes.Buffer
+	flaky := &flakyHandler{TCPClientHandler: modbus.NewTCPClientHandler(""), failures: 1, err: errors.New("reset")}
+	handler := retryingHandler{
+		ClientHandler: flaky,
+		ctx:           context.Background(),
+		read:          retryStrategy(config.RetryPolicy{InitialBackoff: time.Millisecond}, defaultReadRetry),
+		write:         retryStrategy(config.RetryPolicy{}, defaultWriteRetry),
+		logger:        slog.New(slog.NewJSONHandler(&logs, nil)),
+	}
+	if _, err := handler.Send(tcpADU(0x03, 0, 0, 0, 1)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), `"msg":"retrying modbus transaction"`) {
+		t.Errorf("logs = %q, want the retry logged by the handler's logger", logs.String())
+	}
+}
+
+func TestRetryingHandler_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	flaky := &flakyHandler{TCPClientHandler: modbus.NewTCPClientHandler("")}
+	handler := retryingHandler{
+		ClientHandler: flaky,
+		ctx:           ctx,
+		read:          retryStrategy(config.RetryPolicy{}, defaultReadRetry),
+		write:         retryStrategy(config.RetryPolicy{}, defaultWriteRetry),
+	}
+	if _, err := handler.Send(tcpADU(0x03, 0, 0, 0, 1)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() error = %v, want %v", err, context.Canceled)
+	}
+	if flaky.sent != 0 {
+		t.Errorf("sent %d times, want 0", flaky.sent)
+	}
+}
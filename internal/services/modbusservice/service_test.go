@@ -0,0 +1,510 @@
+package modbusservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"modbustohttp/internal/arm"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/guardrails"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	modbusv1alpha1 "modbustohttp/service/modbustohttp/v1alpha1"
+	"modbustohttp/service/modbustohttp/v1alpha1/v1alpha1connect"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/validate"
+	"github.com/goburrow/modbus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+)
+
+// newSimulator serves a simulated modbus device until the end of the test, and returns a handler connecting to it and
+// the simulator.
+func newSimulator(t *testing.T) (*modbus.TCPClientHandler, *modbusserver.Simulator) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulator := modbusserver.NewSimulator()
+	modbusServer := modbusserver.NewServer(simulator)
+	go func() { _ = modbusServer.Serve(listener) }()
+
+	modbusHandler := modbus.NewTCPClientHandler(listener.Addr().String())
+	modbusHandler.Timeout = time.Second
+	modbusHandler.SlaveId = 1
+	t.Cleanup(func() {
+		_ = modbusHandler.Close()
+		_ = modbusServer.Close()
+	})
+	return modbusHandler, simulator
+}
+
+// newSimulatedService serves a Service connected to a simulated modbus device until the end of the test, and returns
+// a client for the service and the simulator. Writes are recorded by the audit logger and checked by the guard if they
+// are not nil.
+func newSimulatedService(
+	t *testing.T,
+	modbusConfig *config.Modbus,
+	auditLogger *audit.Logger,
+	guard *guardrails.Guard,
+) (v1alpha1connect.ModbusServiceClient, *modbusserver.Simulator) {
+	t.Helper()
+	modbusHandler, simulator := newSimulator(t)
+	service := NewService(modbusHandler, modbusConfig, metrics.New(prometheus.NewRegistry()), nil, nil, auditLogger, guard,
+		arm.NewStore(time.Minute), slog.New(slog.DiscardHandler),
+	)
+	validateInterceptor, err := validate.NewInterceptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(v1alpha1connect.NewModbusServiceHandler(service, connect.WithInterceptors(validateInterceptor)))
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+	return v1alpha1connect.NewModbusServiceClient(httpServer.Client(), httpServer.URL), simulator
+}
+
+// allFunctions are every function which can be supported, including those which are not supported by default.
+var allFunctions = []config.ModbusFunction{
+	config.ReadCoils, config.ReadDiscreteInputs, config.ReadHoldingRegisters, config.ReadInputRegisters,
+	config.WriteSingleCoil, config.WriteMultipleCoils, config.WriteMultipleRegisters, config.WriteSingleRegister,
+	config.MaskWriteSingleRegister, config.ReadWriteMultipleRegisters, config.ReadDeviceIdentification,
+	config.DiagnosticsReturnQueryData, config.DiagnosticsClearCounters, config.DiagnosticsBusMessageCount,
+	config.DiagnosticsBusCommunicationErrorCount, config.DiagnosticsBusExceptionErrorCount,
+	config.DiagnosticsServerMessageCount, config.DiagnosticsServerNoResponseCount, config.GetCommEventCounter,
+	config.GetCommEventLog, config.ReadFIFOQueue, config.ReadFileRecord, config.WriteFileRecord,
+}
+
+func TestService_Simulated(t *testing.T) {
+	ctx := context.Background()
+	auditLogger := audit.NewLogger(&config.Audit{
+		Path:            filepath.Join(t.TempDir(), "audit.jsonl"),
+		ReadBeforeWrite: true,
+	})
+	t.Cleanup(func() { _ = auditLogger.Close() })
+	client, simulator := newSimulatedService(t, &config.Modbus{
+		FunctionsSupported: allFunctions,
+		RawPDU:             config.RawPDU{Enabled: true},
+	}, auditLogger, nil)
+
+	t.Run("Coils", func(t *testing.T) {
+		_, err := client.WriteSingleCoil(ctx, connect.NewRequest(&modbusv1alpha1.WriteSingleCoilRequest{
+			Coil: &modbusv1alpha1.BooleanAddress{Address: 1, Value: true},
+		}))
+		if err != nil {
+			t.Fatalf("WriteSingleCoil() error = %v", err)
+		}
+		_, err = client.WriteMultipleCoils(ctx, connect.NewRequest(&modbusv1alpha1.WriteMultipleCoilsRequest{
+			Address: 2,
+			Values:  []bool{false, true},
+		}))
+		if err != nil {
+			t.Fatalf("WriteMultipleCoils() error = %v", err)
+		}
+		if got := simulator.Coils(0, 4); !slices.Equal(got, []bool{false, true, false, true}) {
+			t.Errorf("Coils() = %v, want [false true false true]", got)
+		}
+		response, err := client.ReadCoils(ctx, connect.NewRequest(&modbusv1alpha1.ReadCoilsRequest{
+			Address:  0,
+			Quantity: 4,
+		}))
+		if err != nil {
+			t.Fatalf("ReadCoils() error = %v", err)
+		}
+		if got := booleanValues(response.Msg.GetCoils()); !slices.Equal(got, []bool{false, true, false, true}) {
+			t.Errorf("ReadCoils() = %v, want [false true false true]", got)
+		}
+	})
+
+	t.Run("Discrete inputs", func(t *testing.T) {
+		simulator.SetDiscreteInputs(10, true, true)
+		response, err := client.ReadDiscreteInputs(ctx, connect.NewRequest(&modbusv1alpha1.ReadDiscreteInputsRequest{
+			Address:  9,
+			Quantity: 3,
+		}))
+		if err != nil {
+			t.Fatalf("ReadDiscreteInputs() error = %v", err)
+		}
+		if got := booleanValues(response.Msg.GetInputs()); !slices.Equal(got, []bool{false, true, true}) {
+			t.Errorf("ReadDiscreteInputs() = %v, want [false true true]", got)
+		}
+	})
+
+	t.Run("Input registers", func(t *testing.T) {
+		simulator.SetInputRegisters(20, 100, 200)
+		response, err := client.ReadInputRegisters(ctx, connect.NewRequest(&modbusv1alpha1.ReadInputRegistersRequest{
+			Address:  20,
+			Quantity: proto.Uint32(2),
+		}))
+		if err != nil {
+			t.Fatalf("ReadInputRegisters() error = %v", err)
+		}
+		if got := registerValues(response.Msg.GetRegisters()); !slices.Equal(got, []uint32{100, 200}) {
+			t.Errorf("ReadInputRegisters() = %v, want [100 200]", got)
+		}
+	})
+
+	t.Run("Holding registers", func(t *testing.T) {
+		_, err := client.WriteSingleRegister(ctx, connect.NewRequest(&modbusv1alpha1.WriteSingleRegisterRequest{
+			Register: &modbusv1alpha1.Register{Address: 30, Value: 1},
+		}))
+		if err != nil {
+			t.Fatalf("WriteSingleRegister() error = %v", err)
+		}
+		_, err = client.WriteMultipleRegisters(ctx, connect.NewRequest(&modbusv1alpha1.WriteMultipleRegistersRequest{
+			Address: 31,
+			Values:  []uint32{2, 3},
+		}))
+		if err != nil {
+			t.Fatalf("WriteMultipleRegisters() error = %v", err)
+		}
+		response, err := client.ReadHoldingRegisters(ctx,
+			connect.NewRequest(&modbusv1alpha1.ReadHoldingRegistersRequest{Address: 30, Quantity: proto.Uint32(3)}),
+		)
+		if err != nil {
+			t.Fatalf("ReadHoldingRegisters() error = %v", err)
+		}
+		if got := registerValues(response.Msg.GetRegisters()); !slices.Equal(got, []uint32{1, 2, 3}) {
+			t.Errorf("ReadHoldingRegisters() = %v, want [1 2 3]", got)
+		}
+		readWrite, err := client.ReadWriteMultipleRegisters(ctx,
+			connect.NewRequest(&modbusv1alpha1.ReadWriteMultipleRegistersRequest{
+				ReadAddress:  30,
+				ReadQuantity: 2,
+				WriteAddress: 30,
+				Values:       []uint32{4},
+			}),
+		)
+		if err != nil {
+			t.Fatalf("ReadWriteMultipleRegisters() error = %v", err)
+		}
+		// The write is performed before the read.
+		if got := registerValues(readWrite.Msg.GetRegisters()); !slices.Equal(got, []uint32{4, 2}) {
+			t.Errorf("ReadWriteMultipleRegisters() = %v, want [4 2]", got)
+		}
+	})
+
+	t.Run("Bits in registers", func(t *testing.T) {
+		simulator.SetHoldingRegisters(40, 0x00F0)
+		_, err := client.WriteBitInRegister(ctx, connect.NewRequest(&modbusv1alpha1.WriteBitInRegisterRequest{
+			Address: 40,
+			Bit:     0,
+			Value:   true,
+		}))
+		if err != nil {
+			t.Fatalf("WriteBitInRegister() error = %v", err)
+		}
+		_, err = client.WriteBitsInRegister(ctx, connect.NewRequest(&modbusv1alpha1.WriteBitsInRegisterRequest{
+			Address: 40,
+			Bits:    []*modbusv1alpha1.BooleanAddress{{Address: 4, Value: false}, {Address: 15, Value: true}},
+		}))
+		if err != nil {
+			t.Fatalf("WriteBitsInRegister() error = %v", err)
+		}
+		_, err = client.MaskWriteRegister(ctx, connect.NewRequest(&modbusv1alpha1.MaskWriteRegisterRequest{
+			Address: 40,
+			AndMask: 0xFEFF,
+			OrMask:  0x0100,
+		}))
+		if err != nil {
+			t.Fatalf("MaskWriteRegister() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(40, 1); got[0] != 0x81E1 {
+			t.Errorf("HoldingRegisters() = %#04x, want 0x81e1", got[0])
+		}
+		response, err := client.ReadRegisterAsBits(ctx, connect.NewRequest(&modbusv1alpha1.ReadRegisterAsBitsRequest{
+			Address: 40,
+		}))
+		if err != nil {
+			t.Fatalf("ReadRegisterAsBits() error = %v", err)
+		}
+		bits := booleanValues(response.Msg.GetBits())
+		if !bits[0] || bits[1] || !bits[8] || !bits[15] {
+			t.Errorf("ReadRegisterAsBits() = %v, want bits 0, 8 and 15 set and bit 1 clear", bits)
+		}
+	})
+
+	t.Run("Arm and execute", func(t *testing.T) {
+		prepared, err := client.PrepareWrite(ctx, connect.NewRequest(&modbusv1alpha1.PrepareWriteRequest{
+			Write: &modbusv1alpha1.PrepareWriteRequest_Register{
+				Register: &modbusv1alpha1.Register{Address: 50, Value: 7},
+			},
+		}))
+		if err != nil {
+			t.Fatalf("PrepareWrite() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(50, 1); got[0] != 0 {
+			t.Errorf("HoldingRegisters() after PrepareWrite() = %d, want 0", got[0])
+		}
+		_, err = client.ExecuteWrite(ctx, connect.NewRequest(&modbusv1alpha1.ExecuteWriteRequest{
+			Token: prepared.Msg.GetToken(),
+		}))
+		if err != nil {
+			t.Fatalf("ExecuteWrite() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(50, 1); got[0] != 7 {
+			t.Errorf("HoldingRegisters() after ExecuteWrite() = %d, want 7", got[0])
+		}
+	})
+
+	t.Run("Guardrails", func(t *testing.T) {
+		maxSetpoint := uint16(100)
+		guard, err := guardrails.New(&config.Guardrails{
+			Enabled:      true,
+			DenyUnlisted: true,
+			Rules: []config.WriteRule{
+				{Name: "setpoints", Table: config.HoldingRegisters, Start: 70, End: 79, Max: &maxSetpoint},
+			},
+		})
+		if err != nil {
+			t.Fatalf("guardrails.New() error = %v", err)
+		}
+		client, simulator := newSimulatedService(t, &config.Modbus{FunctionsSupported: allFunctions}, nil, guard)
+
+		tests := []struct {
+			name        string
+			address     uint32
+			value       uint32
+			wantType    guardrails.ViolationType
+			wantSubject string
+		}{
+			{
+				name:        "Above max",
+				address:     70,
+				value:       101,
+				wantType:    guardrails.AboveMax,
+				wantSubject: fmt.Sprintf("%s/70", config.HoldingRegisters),
+			},
+			{
+				name:        "Unlisted address",
+				address:     80,
+				value:       1,
+				wantType:    guardrails.AddressNotAllowed,
+				wantSubject: fmt.Sprintf("%s/80", config.HoldingRegisters),
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				request := connect.NewRequest(&modbusv1alpha1.WriteSingleRegisterRequest{
+					Register: &modbusv1alpha1.Register{Address: tt.address, Value: tt.value},
+				})
+				_, err := client.WriteSingleRegister(ctx, request)
+				var connectErr *connect.Error
+				if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeFailedPrecondition {
+					t.Fatalf("WriteSingleRegister() error = %v, want code %v", err, connect.CodeFailedPrecondition)
+				}
+				var violations []*errdetails.PreconditionFailure_Violation
+				for _, detail := range connectErr.Details() {
+					if value, err := detail.Value(); err == nil {
+						if failure, ok := value.(*errdetails.PreconditionFailure); ok {
+							violations = append(violations, failure.GetViolations()...)
+						}
+					}
+				}
+				if len(violations) != 1 || violations[0].GetType() != string(tt.wantType) ||
+					violations[0].GetSubject() != tt.wantSubject {
+					t.Errorf("PreconditionFailure violations = %v, want one %s violation of %s",
+						violations, tt.wantType, tt.wantSubject)
+				}
+				if got := simulator.HoldingRegisters(uint16(tt.address), 1); got[0] != 0 {
+					t.Errorf("HoldingRegisters() = %d, want 0", got[0])
+				}
+			})
+		}
+		_, err = client.WriteSingleRegister(ctx, connect.NewRequest(&modbusv1alpha1.WriteSingleRegisterRequest{
+			Register: &modbusv1alpha1.Register{Address: 70, Value: 100},
+		}))
+		if err != nil {
+			t.Fatalf("WriteSingleRegister() error = %v", err)
+		}
+		if got := simulator.HoldingRegisters(70, 1); got[0] != 100 {
+			t.Errorf("HoldingRegisters() = %d, want 100", got[0])
+		}
+	})
+
+	t.Run("Device identification", func(t *testing.T) {
+		simulator.SetDeviceIdentification(0x80, "serial")
+		response, err := client.ReadDeviceIdentification(ctx,
+			connect.NewRequest(&modbusv1alpha1.ReadDeviceIdentificationRequest{
+				Category: modbusv1alpha1.DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_EXTENDED,
+			}),
+		)
+		if err != nil {
+			t.Fatalf("ReadDeviceIdentification() error = %v", err)
+		}
+		if response.Msg.GetVendorName() != "modbustohttp" || response.Msg.GetObjects()[0x80] != "serial" {
+			t.Errorf("ReadDeviceIdentification() = %v, want vendor modbustohttp and object 128 serial", response.Msg)
+		}
+	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		echo, err := client.ReturnQueryData(ctx, connect.NewRequest(&modbusv1alpha1.ReturnQueryDataRequest{
+			Data: []byte{0xA5, 0x37},
+		}))
+		if err != nil {
+			t.Fatalf("ReturnQueryData() error = %v", err)
+		}
+		if !slices.Equal(echo.Msg.GetData(), []byte{0xA5, 0x37}) {
+			t.Errorf("ReturnQueryData() = %v, want [165 55]", echo.Msg.GetData())
+		}
+		_, err = client.ClearDiagnosticCounters(ctx, connect.NewRequest(&modbusv1alpha1.ClearDiagnosticCountersRequest{}))
+		if err != nil {
+			t.Fatalf("ClearDiagnosticCounters() error = %v", err)
+		}
+		counters, err := client.GetDiagnosticCounters(ctx,
+			connect.NewRequest(&modbusv1alpha1.GetDiagnosticCountersRequest{}),
+		)
+		if err != nil {
+			t.Fatalf("GetDiagnosticCounters() error = %v", err)
+		}
+		if counters.Msg.BusMessageCount == nil || counters.Msg.GetServerNoResponseCount() != 0 {
+			t.Errorf("GetDiagnosticCounters() = %v, want bus message count set and no server no responses",
+				counters.Msg,
+			)
+		}
+		eventCounter, err := client.GetCommEventCounter(ctx,
+			connect.NewRequest(&modbusv1alpha1.GetCommEventCounterRequest{}),
+		)
+		if err != nil {
+			t.Fatalf("GetCommEventCounter() error = %v", err)
+		}
+		if eventCounter.Msg.GetBusy() {
+			t.Error("GetCommEventCounter() busy = true, want false")
+		}
+		eventLog, err := client.GetCommEventLog(ctx, connect.NewRequest(&modbusv1alpha1.GetCommEventLogRequest{}))
+		if err != nil {
+			t.Fatalf("GetCommEventLog() error = %v", err)
+		}
+		if len(eventLog.Msg.GetEvents()) == 0 {
+			t.Error("GetCommEventLog() events = [], want events")
+		}
+	})
+
+	t.Run("FIFO queue", func(t *testing.T) {
+		simulator.SetFIFO(60, 1, 2, 3)
+		response, err := client.ReadFIFOQueue(ctx, connect.NewRequest(&modbusv1alpha1.ReadFIFOQueueRequest{
+			Address: 60,
+		}))
+		if err != nil {
+			t.Fatalf("ReadFIFOQueue() error = %v", err)
+		}
+		if !slices.Equal(response.Msg.GetValues(), []uint32{1, 2, 3}) {
+			t.Errorf("ReadFIFOQueue() = %v, want [1 2 3]", response.Msg.GetValues())
+		}
+	})
+
+	t.Run("File records", func(t *testing.T) {
+		_, err := client.WriteFileRecord(ctx, connect.NewRequest(&modbusv1alpha1.WriteFileRecordRequest{
+			Records: []*modbusv1alpha1.FileRecord{{FileNumber: 4, RecordNumber: 7, Values: []uint32{0x06AF, 0x04BE}}},
+		}))
+		if err != nil {
+			t.Fatalf("WriteFileRecord() error = %v", err)
+		}
+		if got := simulator.FileRecords(4, 7, 2); !slices.Equal(got, []uint16{0x06AF, 0x04BE}) {
+			t.Errorf("FileRecords() = %v, want [1711 1214]", got)
+		}
+		events, err := auditLogger.List(audit.Filter{Table: config.FileRecords})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(events) != 1 || events[0].FileNumber != 4 || events[0].Address != 7 || !events[0].Success ||
+			!slices.Equal(events[0].OldValues, []uint32{0, 0}) ||
+			!slices.Equal(events[0].NewValues, []uint32{0x06AF, 0x04BE}) {
+			t.Errorf("List() = %+v, want one event writing [1711 1214] over [0 0] to file 4 record 7", events)
+		}
+		response, err := client.ReadFileRecord(ctx, connect.NewRequest(&modbusv1alpha1.ReadFileRecordRequest{
+			References: []*modbusv1alpha1.FileRecordReference{{FileNumber: 4, RecordNumber: 8, Length: 1}},
+		}))
+		if err != nil {
+			t.Fatalf("ReadFileRecord() error = %v", err)
+		}
+		if records := response.Msg.GetRecords(); len(records) != 1 ||
+			!slices.Equal(records[0].GetValues(), []uint32{0x04BE}) {
+			t.Errorf("ReadFileRecord() = %v, want one record with the value 1214", records)
+		}
+	})
+
+	t.Run("Raw PDU", func(t *testing.T) {
+		simulator.HandleFunction(65, func(request *modbus.ProtocolDataUnit) *modbus.ProtocolDataUnit {
+			return &modbus.ProtocolDataUnit{FunctionCode: request.FunctionCode, Data: []byte{byte(len(request.Data))}}
+		})
+		response, err := client.SendRawPDU(ctx, connect.NewRequest(&modbusv1alpha1.SendRawPDURequest{
+			FunctionCode: 65,
+			Data:         []byte{1, 2, 3},
+		}))
+		if err != nil {
+			t.Fatalf("SendRawPDU() error = %v", err)
+		}
+		if response.Msg.GetFunctionCode() != 65 || !slices.Equal(response.Msg.GetData(), []byte{3}) {
+			t.Errorf("SendRawPDU() = %v, want function code 65 and data [3]", response.Msg)
+		}
+		response, err = client.SendRawPDU(ctx, connect.NewRequest(&modbusv1alpha1.SendRawPDURequest{
+			FunctionCode: 66,
+		}))
+		if err != nil {
+			t.Fatalf("SendRawPDU() error = %v", err)
+		}
+		if response.Msg.ExceptionCode == nil || response.Msg.GetExceptionCode() != modbus.ExceptionCodeIllegalFunction {
+			t.Errorf("SendRawPDU() = %v, want an illegal function exception", response.Msg)
+		}
+		events, err := auditLogger.List(audit.Filter{})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		events = slices.DeleteFunc(events, func(event audit.Event) bool { return event.FunctionCode == 0 })
+		// Events are listed newest first.
+		if len(events) != 2 || events[0].FunctionCode != 66 || events[0].Success ||
+			events[1].FunctionCode != 65 || !events[1].Success || !slices.Equal(events[1].Data, []byte{1, 2, 3}) {
+			t.Errorf("List() = %+v, want a failed event for function code 66 and one for 65 with data [1 2 3]", events)
+		}
+	})
+
+	t.Run("Exception", func(t *testing.T) {
+		simulator.SetException(modbus.FuncCodeReadCoils, modbus.ExceptionCodeServerDeviceBusy)
+		defer simulator.SetException(modbus.FuncCodeReadCoils, 0)
+		_, err := client.ReadCoils(ctx, connect.NewRequest(&modbusv1alpha1.ReadCoilsRequest{Quantity: 1}))
+		if err == nil {
+			t.Fatal("ReadCoils() error = nil, want error")
+		}
+	})
+}
+
+func TestService_Unimplemented(t *testing.T) {
+	modbusConfig := &config.Modbus{FunctionsSupported: []config.ModbusFunction{config.ReadCoils}}
+	client, _ := newSimulatedService(t, modbusConfig, nil, nil)
+	_, err := client.ReadHoldingRegisters(context.Background(),
+		connect.NewRequest(&modbusv1alpha1.ReadHoldingRegistersRequest{Address: 0}),
+	)
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeUnimplemented {
+		t.Errorf("ReadHoldingRegisters() error = %v, want %v", err, connect.CodeUnimplemented)
+	}
+}
+
+func booleanValues(addresses []*modbusv1alpha1.BooleanAddress) []bool {
+	values := make([]bool, len(addresses))
+	for i, address := range addresses {
+		values[i] = address.GetValue()
+	}
+	return values
+}
+
+func registerValues(registers []*modbusv1alpha1.Register) []uint32 {
+	values := make([]uint32, len(registers))
+	for i, register := range registers {
+		values[i] = register.GetValue()
+	}
+	return values
+}
@@ -0,0 +1,91 @@
+package modbusservice
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/goburrow/modbus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("modbustohttp/internal/services/modbusservice")
+
+const (
+	attributeFunctionCode  = attribute.Key("modbus.function_code")
+	attributeUnitID        = attribute.Key("modbus.unit_id")
+	attributeAddress       = attribute.Key("modbus.address")
+	attributeQuantity      = attribute.Key("modbus.quantity")
+	attributeExceptionCode = attribute.Key("modbus.exception_code")
+	attributeAttempt       = attribute.Key("modbus.attempt")
+)
+
+// tracedHandler wraps a modbus.ClientHandler to record a span for each Modbus transaction made on behalf of a single
+// request. The spans are children of the span in the request context.
+type tracedHandler struct {
+	modbus.ClientHandler
+	ctx    context.Context
+	unitID byte
+}
+
+// Send sends a single Modbus transaction within a client span describing the request and any exception returned.
+func (h tracedHandler) Send(aduRequest []byte) ([]byte, error) {
+	attributes := []attribute.KeyValue{attributeUnitID.Int(int(h.unitID))}
+	if request, err := h.Decode(aduRequest); err == nil {
+		attributes = append(attributes, pduAttributes(request)...)
+	}
+	_, span := tracer.Start(h.ctx, "modbus.transaction",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	defer span.End()
+
+	aduResponse, err := h.ClientHandler.Send(aduRequest)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	if exception := exceptionFromResponse(h, aduResponse); exception != nil {
+		span.SetAttributes(attributeExceptionCode.Int(int(exception.ExceptionCode)))
+		span.SetStatus(codes.Error, exception.Error())
+	}
+	return aduResponse, nil
+}
+
+// pduAttributes returns the span attributes describing the given request PDU. The address and quantity are included
+// for the function codes whose requests start with them.
+func pduAttributes(pdu *modbus.ProtocolDataUnit) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{attributeFunctionCode.Int(int(pdu.FunctionCode))}
+	switch pdu.FunctionCode {
+	case modbus.FuncCodeReadCoils,
+		modbus.FuncCodeReadDiscreteInputs,
+		modbus.FuncCodeReadHoldingRegisters,
+		modbus.FuncCodeReadInputRegisters,
+		modbus.FuncCodeWriteMultipleCoils,
+		modbus.FuncCodeWriteMultipleRegisters,
+		modbus.FuncCodeReadWriteMultipleRegisters:
+		if len(pdu.Data) >= 4 {
+			attributes = append(attributes,
+				attributeAddress.Int(int(binary.BigEndian.Uint16(pdu.Data))),
+				attributeQuantity.Int(int(binary.BigEndian.Uint16(pdu.Data[2:]))),
+			)
+		}
+	case modbus.FuncCodeWriteSingleCoil,
+		modbus.FuncCodeWriteSingleRegister,
+		modbus.FuncCodeMaskWriteRegister:
+		if len(pdu.Data) >= 2 {
+			attributes = append(attributes,
+				attributeAddress.Int(int(binary.BigEndian.Uint16(pdu.Data))),
+				attributeQuantity.Int(1),
+			)
+		}
+	case modbus.FuncCodeReadFIFOQueue:
+		if len(pdu.Data) >= 2 {
+			attributes = append(attributes, attributeAddress.Int(int(binary.BigEndian.Uint16(pdu.Data))))
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,52 @@
+package modbusservice
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goburrow/modbus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPDUAttributes(t *testing.T) {
+	tests := []struct {
+		name string
+		pdu  modbus.ProtocolDataUnit
+		want []attribute.KeyValue
+	}{
+		{
+			name: "Read holding registers",
+			pdu:  modbus.ProtocolDataUnit{FunctionCode: 0x03, Data: []byte{0x00, 0x64, 0x00, 0x0A}},
+			want: []attribute.KeyValue{
+				attributeFunctionCode.Int(3),
+				attributeAddress.Int(100),
+				attributeQuantity.Int(10),
+			},
+		},
+		{
+			name: "Write single coil",
+			pdu:  modbus.ProtocolDataUnit{FunctionCode: 0x05, Data: []byte{0x00, 0x01, 0xFF, 0x00}},
+			want: []attribute.KeyValue{
+				attributeFunctionCode.Int(5),
+				attributeAddress.Int(1),
+				attributeQuantity.Int(1),
+			},
+		},
+		{
+			name: "Truncated request",
+			pdu:  modbus.ProtocolDataUnit{FunctionCode: 0x01, Data: []byte{0x00}},
+			want: []attribute.KeyValue{
+				attributeFunctionCode.Int(1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pduAttributes(&tt.pdu)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pduAttributes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package tlsconfig builds the TLS config of the HTTP server, reloading its certificate when the files are replaced so
+// that renewed certificates are served without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"modbustohttp/pkg/config"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkInterval is how often the files are checked for changes, so that handshakes do not stat them every time.
+const checkInterval = 10 * time.Second
+
+// CertificateReloader serves a certificate and key loaded from files, reloading them when either file is modified.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	// checkedAt is when the files were last checked for changes
+	checkedAt time.Time
+}
+
+// NewCertificateReloader returns a CertificateReloader for the given files, which logs errors reloading them to the
+// given logger. It returns an error if the certificate cannot be loaded.
+func NewCertificateReloader(certFile string, keyFile string, logger *slog.Logger) (*CertificateReloader, error) {
+	reloader := &CertificateReloader{certFile: certFile, keyFile: keyFile, logger: logger, now: time.Now}
+	reloader.checkedAt = reloader.now()
+	certModTime, keyModTime, err := reloader.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := reloader.load(certModTime, keyModTime); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if either file has been modified since it was
+// last loaded. The files are checked at most once per check interval. It can be used as tls.Config.GetCertificate. If
+// reloading fails, for example because only one of the files has been replaced so far, the previously loaded
+// certificate is returned.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if now.Sub(r.checkedAt) < checkInterval {
+		return r.certificate, nil
+	}
+	r.checkedAt = now
+	certModTime, keyModTime, err := r.modTimes()
+	if err == nil && (!certModTime.Equal(r.certModTime) || !keyModTime.Equal(r.keyModTime)) {
+		err = r.load(certModTime, keyModTime)
+	}
+	if err != nil {
+		r.logger.Error("error reloading tls certificate",
+			slog.String("cert_file", r.certFile),
+			slog.String("error", err.Error()),
+		)
+	}
+	return r.certificate, nil
+}
+
+func (r *CertificateReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+func (r *CertificateReloader) load(certModTime time.Time, keyModTime time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.certificate = &certificate
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	return nil
+}
+
+// New returns the tls.Config for the HTTP server described by the given config, or nil if TLS is disabled. Errors
+// reloading the certificate are logged to the given logger.
+func New(tlsConfig *config.TLS, logger *slog.Logger) (*tls.Config, error) {
+	if tlsConfig.CertFile == "" {
+		if tlsConfig.ClientCAFile != "" || tlsConfig.RequireClientCert {
+			return nil, errors.New("client certificates require a server certificate to be configured")
+		}
+		return nil, nil
+	}
+	reloader, err := NewCertificateReloader(tlsConfig.CertFile, tlsConfig.KeyFile, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	serverConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if tlsConfig.ClientCAFile != "" {
+		data, err := os.ReadFile(tlsConfig.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.New("client CA file does not contain any PEM encoded certificates")
+		}
+		serverConfig.ClientCAs = pool
+		serverConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	if tlsConfig.RequireClientCert {
+		if serverConfig.ClientCAs == nil {
+			return nil, errors.New("requiring client certificates requires a client CA file to be configured")
+		}
+		serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return serverConfig, nil
+}
@@ -0,0 +1,118 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"modbustohttp/pkg/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate with the given common name and its key to the given files, with
+// the given modification time.
+func writeCertificate(t *testing.T, certFile string, keyFile string, commonName string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commonName(t *testing.T, reloader *CertificateReloader) string {
+	t.Helper()
+	certificate, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestCertificateReloader_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	start := time.Now().Add(-time.Hour)
+	writeCertificate(t, certFile, keyFile, "first", start)
+
+	var logs bytes.Buffer
+	reloader, err := NewCertificateReloader(certFile, keyFile, slog.New(slog.NewTextHandler(&logs, nil)))
+	if err != nil {
+		t.Fatalf("NewCertificateReloader() error = %v", err)
+	}
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+	if got := commonName(t, reloader); got != "first" {
+		t.Errorf("GetCertificate() common name = %q, want %q", got, "first")
+	}
+
+	// Changes are only noticed once the check interval has passed.
+	writeCertificate(t, certFile, keyFile, "second", start.Add(time.Minute))
+	if got := commonName(t, reloader); got != "first" {
+		t.Errorf("GetCertificate() within the check interval common name = %q, want %q", got, "first")
+	}
+	now = now.Add(checkInterval)
+	if got := commonName(t, reloader); got != "second" {
+		t.Errorf("GetCertificate() after change common name = %q, want %q", got, "second")
+	}
+
+	// A key which does not match the certificate keeps the previous certificate.
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(checkInterval)
+	if got := commonName(t, reloader); got != "second" {
+		t.Errorf("GetCertificate() after invalid change common name = %q, want %q", got, "second")
+	}
+	if !strings.Contains(logs.String(), "error reloading tls certificate") {
+		t.Errorf("logs = %q, want the reload error", logs.String())
+	}
+}
+
+func TestNew(t *testing.T) {
+	serverConfig, err := New(&config.TLS{}, slog.New(slog.DiscardHandler))
+	if err != nil || serverConfig != nil {
+		t.Errorf("New() without a certificate = %v, %v, want nil, nil", serverConfig, err)
+	}
+	if _, err := New(&config.TLS{ClientCAFile: "ca.pem"}, slog.New(slog.DiscardHandler)); err == nil {
+		t.Error("New() with a client CA but no certificate error = nil, want error")
+	}
+}
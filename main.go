@@ -1,15 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"modbustohttp/internal/arm"
+	"modbustohttp/internal/audit"
+	"modbustohttp/internal/auth"
+	"modbustohttp/internal/authz"
+	"modbustohttp/internal/breaker"
+	"modbustohttp/internal/guardrails"
 	"modbustohttp/internal/interceptors"
+	"modbustohttp/internal/metrics"
+	"modbustohttp/internal/modbuspool"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/internal/proxy"
+	"modbustohttp/internal/ratelimit"
+	"modbustohttp/internal/services/auditservice"
 	"modbustohttp/internal/services/health"
 	"modbustohttp/internal/services/modbusservice"
+	"modbustohttp/internal/tlsconfig"
+	"modbustohttp/internal/tracing"
 	"modbustohttp/pkg/config"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,13 +35,16 @@ import (
 	"connectrpc.com/connect"
 	"connectrpc.com/grpchealth"
 	"connectrpc.com/grpcreflect"
+	"connectrpc.com/otelconnect"
 	"connectrpc.com/validate"
-	"github.com/goburrow/modbus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
 
-func setupModbusHandler(modbusConfig *config.Modbus, logger *slog.Logger) *modbus.TCPClientHandler {
+func setupModbusHandler(modbusConfig *config.Modbus, logger *slog.Logger) (*modbuspool.Pool, error) {
 	logger.Info("setting up modbus handler",
 		slog.String("host", modbusConfig.Host),
 		slog.Int("port", modbusConfig.Port),
@@ -37,15 +57,16 @@ func setupModbusHandler(modbusConfig *config.Modbus, logger *slog.Logger) *modbu
 			}
 			return funcs
 		}(), ", ")),
+		slog.Int("min_connections", modbusConfig.Pool.MinConnections),
+		slog.Int("max_connections", modbusConfig.Pool.MaxConnections),
+		slog.Duration("idle_timeout", modbusConfig.Pool.IdleTimeout),
+		slog.Duration("health_check_interval", modbusConfig.Pool.HealthCheckInterval),
 	)
-	handler := modbus.NewTCPClientHandler(fmt.Sprintf("%s:%d", modbusConfig.Host, modbusConfig.Port))
-	handler.Timeout = modbusConfig.ConnectionTimeout
-	handler.SlaveId = modbusConfig.SlaveID
-	return handler
+	return modbuspool.New(modbusConfig, logger)
 }
 
 func setupReflector(mux *http.ServeMux, logger *slog.Logger) {
-	names := []string{v1alpha1connect.ModbusServiceName, "grpc.health.v1.Health"}
+	names := []string{v1alpha1connect.ModbusServiceName, v1alpha1connect.AuditServiceName, "grpc.health.v1.Health"}
 	logger.Info("setting up reflector",
 		slog.String("services", strings.Join(names, ",")),
 	)
@@ -59,15 +80,72 @@ func setupReflector(mux *http.ServeMux, logger *slog.Logger) {
 	mux.Handle(grpcreflect.NewHandlerV1Alpha(reflector))
 }
 
-func setupHealthCheck(mux *http.ServeMux, logger *slog.Logger, handler *modbus.TCPClientHandler) {
+func setupHealthCheck(
+	mux *http.ServeMux,
+	logger *slog.Logger,
+	handler *modbuspool.Pool,
+	circuitBreaker *breaker.Breaker,
+) {
 	logger.Info("setting up health check")
-	mux.Handle(grpchealth.NewHandler(health.NewModbusChecker(handler)))
+	mux.Handle(grpchealth.NewHandler(health.NewModbusChecker(handler, circuitBreaker)))
+}
+
+func setupMetrics(mux *http.ServeMux, logger *slog.Logger) *metrics.Metrics {
+	logger.Info("setting up metrics", slog.String("path", "/metrics"))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return metrics.New(registry)
 }
 
-func setupInterceptors(logger *slog.Logger) ([]connect.Interceptor, error) {
+func setupTracing(tracingConfig *config.Tracing, logger *slog.Logger) (func(context.Context) error, error) {
+	logger.Info("setting up tracing",
+		slog.String("exporter", string(tracingConfig.Exporter)),
+		slog.String("endpoint", tracingConfig.Endpoint),
+		slog.String("service_name", tracingConfig.ServiceName),
+	)
+	return tracing.Setup(context.Background(), tracingConfig)
+}
+
+func setupInterceptors(
+	appConfig *config.App,
+	policy *authz.Policy,
+	logger *slog.Logger,
+	appMetrics *metrics.Metrics,
+) ([]connect.Interceptor, error) {
+	rateLimitConfig := &appConfig.RateLimit
+	if rateLimitConfig.Rate > 0 && rateLimitConfig.Key != config.RateLimitByIP &&
+		rateLimitConfig.Key != config.RateLimitByPrincipal {
+		return nil, fmt.Errorf("unknown rate limit key %q", rateLimitConfig.Key)
+	}
+	names := []string{"tracing", "metrics", "validation", "logging"}
+	if rateLimitConfig.Rate > 0 {
+		names = slices.Insert(names, 2, "rate limit")
+	}
+	if appConfig.Auth.Enabled {
+		names = slices.Insert(names, 2, "auth")
+	}
+	if policy != nil {
+		names = slices.Insert(names, len(names)-1, "authorization")
+	}
 	logger.Info("setting up interceptors",
-		slog.String("interceptors", "validation, logging"),
+		slog.String("interceptors", strings.Join(names, ", ")),
+	)
+	// Create the tracing interceptor, trusting the trace context propagated in the incoming request headers so that
+	// the server spans join the caller's trace.
+	tracingInterceptor, err := otelconnect.NewInterceptor(
+		otelconnect.WithTrustRemote(),
+		otelconnect.WithoutMetrics(),
 	)
+	if err != nil {
+		logger.Error("error creating interceptor",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
 	// Create the validation interceptor provided by connectrpc.com/validate.
 	validateInterceptor, err := validate.NewInterceptor()
 	if err != nil {
@@ -77,9 +155,75 @@ func setupInterceptors(logger *slog.Logger) ([]connect.Interceptor, error) {
 		return nil, err
 	}
 
+	metricsInterceptor := interceptors.NewMetricsInterceptor(appMetrics)
 	loggingInterceptor := interceptors.NewLoggingInterceptor(logger)
-	return []connect.Interceptor{validateInterceptor, loggingInterceptor}, nil
+	serviceInterceptors := []connect.Interceptor{tracingInterceptor, metricsInterceptor}
+	if appConfig.Auth.Enabled {
+		// Authenticate before validating so that unauthenticated callers cannot probe the request validation rules.
+		authenticator, err := auth.NewAuthenticator(&appConfig.Auth)
+		if err != nil {
+			logger.Error("error creating interceptor",
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		serviceInterceptors = append(serviceInterceptors, interceptors.NewAuthInterceptor(authenticator))
+	}
+	if rateLimitConfig.Rate > 0 {
+		// Limit after authenticating so that the requests of each principal can be limited.
+		logger.Info("setting up client rate limit",
+			slog.String("key", string(rateLimitConfig.Key)),
+			slog.Float64("rate", rateLimitConfig.Rate),
+			slog.Int("burst", rateLimitConfig.Burst),
+			slog.Duration("max_wait", rateLimitConfig.MaxWait),
+		)
+		serviceInterceptors = append(serviceInterceptors,
+			interceptors.NewRateLimitInterceptor(ratelimit.NewKeyed(&rateLimitConfig.RateLimit), rateLimitConfig.Key),
+		)
+	}
+	serviceInterceptors = append(serviceInterceptors, validateInterceptor)
+	if policy != nil {
+		// Authorize after validating so that the address ranges checked are known to be valid.
+		serviceInterceptors = append(serviceInterceptors,
+			interceptors.NewAuthorizationInterceptor(policy, appConfig.Modbus.SlaveID),
+		)
+	}
+	return append(serviceInterceptors, loggingInterceptor), nil
+
+}
+
+func setupPolicy(authorizationConfig *config.Authorization, logger *slog.Logger) (*authz.Policy, error) {
+	logger.Info("setting up authorization",
+		slog.Bool("enabled", authorizationConfig.Enabled),
+	)
+	if !authorizationConfig.Enabled {
+		return nil, nil
+	}
+	return authz.NewPolicy(authorizationConfig)
+}
 
+func setupProxy(
+	appConfig *config.App,
+	upstream proxy.Upstream,
+	policy *authz.Policy,
+	guard *guardrails.Guard,
+	auditLogger *audit.Logger,
+	logger *slog.Logger,
+) (*modbusserver.Server, error) {
+	proxyConfig := &appConfig.Proxy
+	logger.Info("setting up modbus proxy",
+		slog.Bool("enabled", proxyConfig.Enabled),
+		slog.Duration("cache_max_age", proxyConfig.CacheMaxAge),
+		slog.Int("num_principals", len(proxyConfig.Principals)),
+	)
+	if !proxyConfig.Enabled {
+		return nil, nil
+	}
+	modbusProxy, err := proxy.New(proxyConfig, &appConfig.Modbus, upstream, policy, guard, auditLogger, logger)
+	if err != nil {
+		return nil, err
+	}
+	return modbusserver.NewServer(modbusProxy), nil
 }
 
 func setupServiceHandler(
@@ -97,23 +241,137 @@ func setupServiceHandler(
 	))
 }
 
-func setupServer(addr string, mux *http.ServeMux, logger *slog.Logger) *http.Server {
+func setupAuditLogger(auditConfig *config.Audit, logger *slog.Logger) *audit.Logger {
+	logger.Info("setting up audit log",
+		slog.Bool("enabled", auditConfig.Enabled),
+		slog.String("path", auditConfig.Path),
+		slog.Bool("read_before_write", auditConfig.ReadBeforeWrite),
+	)
+	if !auditConfig.Enabled {
+		return nil
+	}
+	return audit.NewLogger(auditConfig)
+}
+
+func setupCircuitBreaker(
+	breakerConfig *config.CircuitBreaker,
+	logger *slog.Logger,
+	appMetrics *metrics.Metrics,
+) *breaker.Breaker {
+	logger.Info("setting up circuit breaker",
+		slog.Int("failure_threshold", breakerConfig.FailureThreshold),
+		slog.Duration("cool_down", breakerConfig.CoolDown),
+	)
+	if breakerConfig.FailureThreshold == 0 {
+		return nil
+	}
+	return breaker.New(breakerConfig, func(state breaker.State) {
+		logger.Warn("modbus circuit breaker changed state", slog.String("state", state.String()))
+		appMetrics.ModbusCircuitBreakerState.Set(float64(state))
+	})
+}
+
+func setupRateLimiter(rateLimitConfig *config.RateLimit, logger *slog.Logger) *ratelimit.Limiter {
+	logger.Info("setting up modbus rate limit",
+		slog.Float64("rate", rateLimitConfig.Rate),
+		slog.Int("burst", rateLimitConfig.Burst),
+		slog.Duration("max_wait", rateLimitConfig.MaxWait),
+	)
+	if rateLimitConfig.Rate <= 0 {
+		return nil
+	}
+	return ratelimit.New(rateLimitConfig)
+}
+
+func setupGuardrails(guardrailsConfig *config.Guardrails, logger *slog.Logger) (*guardrails.Guard, error) {
+	logger.Info("setting up write guardrails",
+		slog.Bool("enabled", guardrailsConfig.Enabled),
+		slog.Bool("deny_unlisted", guardrailsConfig.DenyUnlisted),
+		slog.Int("num_rules", len(guardrailsConfig.Rules)),
+	)
+	if !guardrailsConfig.Enabled {
+		return nil, nil
+	}
+	return guardrails.New(guardrailsConfig)
+}
+
+func setupAuditServiceHandler(
+	auditServer *auditservice.Service,
+	mux *http.ServeMux,
+	logger *slog.Logger,
+	serviceInterceptors ...connect.Interceptor,
+) {
+	logger.Info("setting up audit service handler",
+		slog.Int("num_interceptors", len(serviceInterceptors)),
+	)
+	mux.Handle(v1alpha1connect.NewAuditServiceHandler(
+		auditServer,
+		connect.WithInterceptors(serviceInterceptors...),
+	))
+}
+
+func setupServer(addr string, mux *http.ServeMux, tlsConfig *config.TLS, logger *slog.Logger) (*http.Server, error) {
 	logger.Info("setting up http server",
 		slog.String("addr", addr),
+		slog.Bool("tls", tlsConfig.CertFile != ""),
+		slog.Bool("client_certificates", tlsConfig.ClientCAFile != ""),
 	)
-	server := &http.Server{
+	serverTLSConfig, err := tlsconfig.New(tlsConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	if serverTLSConfig == nil {
+		return &http.Server{
+			Addr:              addr,
+			ReadHeaderTimeout: 3 * time.Second,
+			// Use h2c so we can serve HTTP/2 without TLS.
+			Handler: h2c.NewHandler(mux, &http2.Server{}),
+		}, nil
+	}
+	return &http.Server{
 		Addr:              addr,
 		ReadHeaderTimeout: 3 * time.Second,
-		// Use h2c so we can serve HTTP/2 without TLS.
-		Handler: h2c.NewHandler(mux, &http2.Server{}),
-	}
-	return server
+		TLSConfig:         serverTLSConfig,
+		// Map verified client certificates to principals so that they can be used for authorization and auditing.
+		Handler: auth.NewCertificateMiddleware(mux),
+	}, nil
 }
 
 func main() {
 
 	structuredLogger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
+	// The simulate subcommand runs a simulated modbus device instead of the HTTP server, for development.
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := runSimulator(os.Args[2:], structuredLogger); err != nil {
+			structuredLogger.Error("error running modbus simulator",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+		return
+	}
+	// The slave subcommand serves HTTP endpoints to Modbus TCP masters instead of serving a modbus device over HTTP.
+	if len(os.Args) > 1 && os.Args[1] == "slave" {
+		if err := runSlave(os.Args[2:], structuredLogger); err != nil {
+			structuredLogger.Error("error running modbus slave",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+		return
+	}
+	// The gateway subcommand forwards Modbus TCP to the slaves on an RTU serial bus.
+	if len(os.Args) > 1 && os.Args[1] == "gateway" {
+		if err := runGateway(os.Args[2:], structuredLogger); err != nil {
+			structuredLogger.Error("error running modbus gateway",
+				slog.String("error", err.Error()),
+			)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Get the config file location from the command line flag or environment variable.
 	// If neither is set, the default is an empty string. The command line flag takes precedence over the environment
 	// variable.
@@ -134,11 +392,95 @@ func main() {
 	)
 	addr := fmt.Sprintf("%s:%d", appConfig.HTTP.Host, appConfig.HTTP.Port)
 
-	handler := setupModbusHandler(&appConfig.Modbus, structuredLogger)
-	modbusServer := modbusservice.NewService(handler, &appConfig.Modbus)
+	shutdownTracing, err := setupTracing(&appConfig.Tracing, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up tracing",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	defer func() {
+		// Flush any spans which have not yet been exported.
+		if err := shutdownTracing(context.Background()); err != nil {
+			structuredLogger.Error("error shutting down tracing",
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
 	mux := http.NewServeMux()
+	appMetrics := setupMetrics(mux, structuredLogger)
 
-	serviceInterceptors, err := setupInterceptors(structuredLogger)
+	auditLogger := setupAuditLogger(&appConfig.Audit, structuredLogger)
+	if auditLogger != nil {
+		defer func() {
+			if err := auditLogger.Close(); err != nil {
+				structuredLogger.Error("error closing audit log",
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+	}
+
+	guard, err := setupGuardrails(&appConfig.Guardrails, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up write guardrails",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if appConfig.Modbus.RawPDU.Enabled && !appConfig.Authorization.Enabled {
+		// Raw PDUs bypass the write guardrails, so they must be limited to administrators.
+		structuredLogger.Error("error setting up raw PDU passthrough",
+			slog.String("error", "authorization must be enabled to send raw PDUs"),
+		)
+		return
+	}
+	if err := modbusservice.CheckRawPDUFunctionCodes(appConfig.Modbus.RawPDU.FunctionCodes); err != nil {
+		structuredLogger.Error("error setting up raw PDU passthrough",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	handler, err := setupModbusHandler(&appConfig.Modbus, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up modbus handler",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	poolCtx, stopPool := context.WithCancel(context.Background())
+	defer stopPool()
+	go handler.Run(poolCtx)
+	armTimeout := appConfig.Guardrails.ArmTimeout
+	if armTimeout == 0 {
+		armTimeout = 30 * time.Second
+	}
+	circuitBreaker := setupCircuitBreaker(&appConfig.Modbus.CircuitBreaker, structuredLogger, appMetrics)
+	modbusServer := modbusservice.NewService(
+		handler,
+		&appConfig.Modbus,
+		appMetrics,
+		circuitBreaker,
+		setupRateLimiter(&appConfig.Modbus.RateLimit, structuredLogger),
+		auditLogger,
+		guard,
+		arm.NewStore(armTimeout),
+		structuredLogger,
+	)
+	auditServer := auditservice.NewService(auditLogger)
+
+	policy, err := setupPolicy(&appConfig.Authorization, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up authorization",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	serviceInterceptors, err := setupInterceptors(appConfig, policy, structuredLogger, appMetrics)
 
 	if err != nil {
 		slog.Error("error setting up interceptors",
@@ -147,17 +489,45 @@ func main() {
 		return
 	}
 
+	proxyServer, err := setupProxy(appConfig, modbusServer, policy, guard, auditLogger, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up modbus proxy",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if proxyServer != nil {
+		proxyAddr := fmt.Sprintf("%s:%d", appConfig.Proxy.Host, appConfig.Proxy.Port)
+		structuredLogger.Info("starting modbus proxy", slog.String("addr", proxyAddr))
+		go func() {
+			if err := proxyServer.ListenAndServe(proxyAddr); err != nil && !errors.Is(err, modbusserver.ErrServerClosed) {
+				structuredLogger.Error("error running modbus proxy",
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+		defer func() { _ = proxyServer.Close() }()
+	}
+
 	setupServiceHandler(modbusServer, mux, structuredLogger, serviceInterceptors...)
 
+	setupAuditServiceHandler(auditServer, mux, structuredLogger, serviceInterceptors...)
+
 	setupReflector(mux, structuredLogger)
 
-	setupHealthCheck(mux, structuredLogger, handler)
+	setupHealthCheck(mux, structuredLogger, handler, circuitBreaker)
 
-	server := setupServer(addr, mux, structuredLogger)
+	server, err := setupServer(addr, mux, &appConfig.HTTP.TLS, structuredLogger)
+	if err != nil {
+		structuredLogger.Error("error setting up http server",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
 
 	structuredLogger.Info("starting http server", slog.String("addr", addr))
 
-	defer func(handler *modbus.TCPClientHandler) {
+	defer func(handler *modbuspool.Pool) {
 		// Make sure the modbus handler is closed when the application exits.
 		err = handler.Close()
 		if err != nil {
@@ -167,7 +537,13 @@ func main() {
 		}
 	}(handler)
 
-	if err := server.ListenAndServe(); err != nil {
+	// The certificate and key are provided by the TLS config, so no files are passed to ListenAndServeTLS.
+	if server.TLSConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil {
 		slog.Error("error running application",
 			slog.String("error", err.Error()),
 		)
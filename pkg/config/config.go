@@ -1,13 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/caarlos0/env/v11"
+	"gopkg.in/yaml.v3"
 )
 
 // ModbusFunction is a particular Modbus function which can be configured to be supported by the application.
@@ -23,8 +26,59 @@ const (
 	WriteMultipleRegisters  ModbusFunction = "WriteMultipleRegisters"
 	WriteSingleRegister     ModbusFunction = "WriteSingleRegister"
 	MaskWriteSingleRegister ModbusFunction = "MaskWriteSingleRegister"
+	// ReadWriteMultipleRegisters is function code 23, which writes and then reads holding registers in one transaction
+	ReadWriteMultipleRegisters ModbusFunction = "ReadWriteMultipleRegisters"
+	// ReadDeviceIdentification is function code 43 with MEI type 14, which reads the identification objects of the
+	// modbus server
+	ReadDeviceIdentification ModbusFunction = "ReadDeviceIdentification"
+	// DiagnosticsReturnQueryData is function code 8 with subfunction 0, which returns the request data unchanged
+	DiagnosticsReturnQueryData ModbusFunction = "DiagnosticsReturnQueryData"
+	// DiagnosticsClearCounters is function code 8 with subfunction 10, which clears the diagnostic counters
+	DiagnosticsClearCounters ModbusFunction = "DiagnosticsClearCounters"
+	// DiagnosticsBusMessageCount is function code 8 with subfunction 11
+	DiagnosticsBusMessageCount ModbusFunction = "DiagnosticsBusMessageCount"
+	// DiagnosticsBusCommunicationErrorCount is function code 8 with subfunction 12, the count of CRC errors
+	DiagnosticsBusCommunicationErrorCount ModbusFunction = "DiagnosticsBusCommunicationErrorCount"
+	// DiagnosticsBusExceptionErrorCount is function code 8 with subfunction 13
+	DiagnosticsBusExceptionErrorCount ModbusFunction = "DiagnosticsBusExceptionErrorCount"
+	// DiagnosticsServerMessageCount is function code 8 with subfunction 14
+	DiagnosticsServerMessageCount ModbusFunction = "DiagnosticsServerMessageCount"
+	// DiagnosticsServerNoResponseCount is function code 8 with subfunction 15
+	DiagnosticsServerNoResponseCount ModbusFunction = "DiagnosticsServerNoResponseCount"
+	// GetCommEventCounter is function code 11
+	GetCommEventCounter ModbusFunction = "GetCommEventCounter"
+	// GetCommEventLog is function code 12
+	GetCommEventLog ModbusFunction = "GetCommEventLog"
+	// ReadFIFOQueue is function code 24
+	ReadFIFOQueue ModbusFunction = "ReadFIFOQueue"
+	// ReadFileRecord is function code 20
+	ReadFileRecord ModbusFunction = "ReadFileRecord"
+	// WriteFileRecord is function code 21
+	WriteFileRecord ModbusFunction = "WriteFileRecord"
 )
 
+// Table is one of the Modbus data tables.
+type Table string
+
+const (
+	Coils            Table = "coils"
+	DiscreteInputs   Table = "discreteInputs"
+	InputRegisters   Table = "inputRegisters"
+	HoldingRegisters Table = "holdingRegisters"
+	// FileRecords are the records of the files written using WriteFileRecord. They are only used in audit events.
+	FileRecords Table = "fileRecords"
+)
+
+// RawPDU contains the config of the SendRawPDU RPC, which sends requests with vendor specific function codes
+type RawPDU struct {
+	// Enabled allows SendRawPDU to be called. As raw PDUs are not checked by the write guardrails, authorization must
+	// be enabled so that it can be limited to administrators.
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// FunctionCodes are the function codes which can be sent. If it is empty, the user-defined function codes 65-72 and
+	// 100-110 can be sent. The function codes defined by the Modbus specification cannot be sent.
+	FunctionCodes []byte `json:"functionCodes" env:"FUNCTION_CODES"`
+}
+
 // Modbus contains Modbus protocol specific config
 type Modbus struct {
 	// Host is the hostname of the modbus server to connect to
@@ -37,13 +91,463 @@ type Modbus struct {
 	// requests are made
 	ConnectionTimeout time.Duration `json:"connectionTimeout" env:"CONNECTION_TIMEOUT" envDefault:"10s"`
 	// FunctionsSupported is the list of available ModbusFunction supported by the modbus server
-	FunctionsSupported []ModbusFunction `json:"functionsSupported" env:"FUNCTIONS_SUPPORTED" envDefault:"ReadCoils,ReadDiscreteInputs,ReadHoldingRegisters,ReadInputRegisters,WriteSingleCoil,WriteMultipleCoils,WriteMultipleRegisters,WriteSingleRegister,MaskWriteSingleRegister"`
+	FunctionsSupported []ModbusFunction `json:"functionsSupported" env:"FUNCTIONS_SUPPORTED" envDefault:"ReadCoils,ReadDiscreteInputs,ReadHoldingRegisters,ReadInputRegisters,WriteSingleCoil,WriteMultipleCoils,WriteMultipleRegisters,WriteSingleRegister,MaskWriteSingleRegister,ReadWriteMultipleRegisters,ReadDeviceIdentification"`
+	// RawPDU is the config of the SendRawPDU RPC, which is disabled by default
+	RawPDU RawPDU `json:"rawPDU" envPrefix:"RAW_PDU_"`
+	// Pool is the config of the pool of connections to the modbus server
+	Pool ConnectionPool `json:"pool" envPrefix:"POOL_"`
+	// CircuitBreaker is the config of the circuit breaker which fails requests fast while the modbus server is
+	// unreachable
+	CircuitBreaker CircuitBreaker `json:"circuitBreaker" envPrefix:"CIRCUIT_BREAKER_"`
+	// Retry is the config of the retries of failed connections and transactions
+	Retry Retry `json:"retry" envPrefix:"RETRY_"`
+	// RateLimit is the rate limit of the transactions sent to the modbus server, for devices which cannot keep up
+	RateLimit RateLimit `json:"rateLimit" envPrefix:"RATE_LIMIT_"`
+}
+
+// RateLimit contains the config of a token bucket, which allows requests at the rate on average and up to the burst
+// at once.
+type RateLimit struct {
+	// Rate is the number of requests per second allowed on average. If zero, requests are not limited.
+	Rate float64 `json:"rate" env:"RATE" envDefault:"0"`
+	// Burst is the number of requests allowed at once after a period without requests. If zero, 1 is used.
+	Burst int `json:"burst" env:"BURST" envDefault:"1"`
+	// MaxWait is how long a request over the limit queues for its turn, or until its deadline if that is sooner.
+	// Requests whose turn would come later fail at once. If zero, requests over the limit fail at once.
+	MaxWait time.Duration `json:"maxWait" env:"MAX_WAIT" envDefault:"5s"`
+}
+
+// RateLimitKey is what the requests of HTTP clients are limited by.
+type RateLimitKey string
+
+const (
+	// RateLimitByIP limits the requests from each client IP address
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitByPrincipal limits the requests of each authenticated principal, and the requests of clients which are
+	// not authenticated by IP address
+	RateLimitByPrincipal RateLimitKey = "principal"
+)
+
+// ClientRateLimit contains the config of the rate limit of each HTTP client, which applies to the RPCs of every
+// service.
+type ClientRateLimit struct {
+	RateLimit
+	// Key is what requests are limited by, either ip or principal
+	Key RateLimitKey `json:"key" env:"KEY" envDefault:"ip"`
+}
+
+// Retry contains the retry policies of the connection to the modbus server and the transactions sent over it.
+type Retry struct {
+	// Connect is the policy for connecting to the modbus server
+	Connect RetryPolicy `json:"connect" envPrefix:"CONNECT_"`
+	// Read is the policy for transactions which only read, which are retried after any transport error
+	Read RetryPolicy `json:"read" envPrefix:"READ_"`
+	// Write is the policy for transactions which may write. They are only retried if the request was never sent, as a
+	// write which may have been delivered could otherwise be applied twice.
+	Write RetryPolicy `json:"write" envPrefix:"WRITE_"`
+}
+
+// RetryPolicy contains the config of an exponential backoff between attempts. The policies share this type, so the
+// fields whose default differs between policies have no env default, and use the default of the policy when zero.
+type RetryPolicy struct {
+	// MaxAttempts is the largest number of attempts, including the first, so 1 disables retries. If zero, the default
+	// of the policy is used.
+	MaxAttempts int `json:"maxAttempts" env:"MAX_ATTEMPTS"`
+	// InitialBackoff is the delay before the first retry. If zero, the default of the policy is used.
+	InitialBackoff time.Duration `json:"initialBackoff" env:"INITIAL_BACKOFF"`
+	// MaxBackoff is the longest delay between attempts. If zero, the default of the policy is used.
+	MaxBackoff time.Duration `json:"maxBackoff" env:"MAX_BACKOFF"`
+	// Multiplier is the factor the delay is multiplied by after each retry. If zero, 2 is used.
+	Multiplier float64 `json:"multiplier" env:"MULTIPLIER" envDefault:"2"`
+	// Jitter randomises each delay between zero and its full length, so that requests which failed together do not
+	// retry together
+	Jitter bool `json:"jitter" env:"JITTER" envDefault:"true"`
+}
+
+// CircuitBreaker contains the config of the circuit breaker of the connection to the modbus server. Once the breaker
+// opens, requests fail immediately instead of retrying the connection, until a probe request reaches the modbus server.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed connection attempts and transactions after which the
+	// breaker opens. If zero, the circuit breaker is disabled.
+	FailureThreshold int `json:"failureThreshold" env:"FAILURE_THRESHOLD" envDefault:"5"`
+	// CoolDown is how long the breaker stays open before a single request is let through to probe the modbus server.
+	// If zero, 30 seconds is used.
+	CoolDown time.Duration `json:"coolDown" env:"COOL_DOWN" envDefault:"30s"`
+}
+
+// ConnectionPool contains the config of the pool of connections to the modbus server. Transactions are sent over any
+// connection which is not in use, so devices which handle parallel connections can be polled at a higher rate.
+type ConnectionPool struct {
+	// MinConnections is the number of connections which are kept open even when idle
+	MinConnections int `json:"minConnections" env:"MIN_CONNECTIONS" envDefault:"0"`
+	// MaxConnections is the largest number of connections which are open at once. Once they are all in use, requests
+	// wait for a connection in the order they were made. If zero, 1 is used.
+	MaxConnections int `json:"maxConnections" env:"MAX_CONNECTIONS" envDefault:"1"`
+	// IdleTimeout is how long a connection can be unused before it is closed, unless it is needed to keep
+	// MinConnections open. If zero, idle connections are not closed. If both it and HealthCheckInterval are zero, their
+	// defaults are used.
+	IdleTimeout time.Duration `json:"idleTimeout" env:"IDLE_TIMEOUT" envDefault:"60s"`
+	// HealthCheckInterval is how often idle connections are checked by reading holding register 0. Any response,
+	// including an exception, shows the connection is healthy, and connections which do not respond are closed. If
+	// zero, idle connections are not checked.
+	HealthCheckInterval time.Duration `json:"healthCheckInterval" env:"HEALTH_CHECK_INTERVAL" envDefault:"30s"`
 }
 
 // HTTP contains the HTTP specific config of the application
 type HTTP struct {
 	Host string `json:"host" env:"HOST" envDefault:""`
 	Port int    `json:"port" env:"PORT" envDefault:"8080"`
+	// TLS contains the TLS config of the HTTP server
+	TLS TLS `json:"tls" envPrefix:"TLS_"`
+}
+
+// TLS contains the TLS and client certificate config of the HTTP server
+type TLS struct {
+	// CertFile is the PEM encoded certificate chain of the server. If empty, TLS is disabled and HTTP/2 is served
+	// without TLS (h2c). The certificate is reloaded when the file changes.
+	CertFile string `json:"certFile" env:"CERT_FILE" envDefault:""`
+	// KeyFile is the PEM encoded private key of the server certificate. The key is reloaded when the file changes.
+	KeyFile string `json:"keyFile" env:"KEY_FILE" envDefault:""`
+	// ClientCAFile is the PEM encoded bundle of CA certificates client certificates are verified against. If empty,
+	// client certificates are not requested.
+	ClientCAFile string `json:"clientCAFile" env:"CLIENT_CA_FILE" envDefault:""`
+	// RequireClientCert rejects connections which do not present a valid client certificate
+	RequireClientCert bool `json:"requireClientCert" env:"REQUIRE_CLIENT_CERT" envDefault:"false"`
+}
+
+// TracingExporter is the exporter used to send OpenTelemetry spans.
+type TracingExporter string
+
+const (
+	// TracingExporterNone disables exporting spans. Trace context is still propagated.
+	TracingExporterNone TracingExporter = "none"
+	// TracingExporterOTLP exports spans using OTLP over HTTP.
+	TracingExporterOTLP TracingExporter = "otlp"
+	// TracingExporterStdout writes spans to stdout, which is useful for local testing.
+	TracingExporterStdout TracingExporter = "stdout"
+)
+
+// Tracing contains the OpenTelemetry tracing config of the application
+type Tracing struct {
+	// Exporter is the TracingExporter used to send spans. An empty value is treated as TracingExporterNone.
+	Exporter TracingExporter `json:"exporter" env:"EXPORTER" envDefault:"none"`
+	// Endpoint is the host and port of the OTLP/HTTP collector. If empty, the standard OTEL_EXPORTER_OTLP_* environment
+	// variables are used.
+	Endpoint string `json:"endpoint" env:"ENDPOINT" envDefault:""`
+	// Insecure disables TLS when exporting spans using OTLP
+	Insecure bool `json:"insecure" env:"INSECURE" envDefault:"false"`
+	// ServiceName is the service name reported with each span
+	ServiceName string `json:"serviceName" env:"SERVICE_NAME" envDefault:"modbustohttp"`
+}
+
+// Audit contains the write audit log config of the application
+type Audit struct {
+	// Enabled turns on recording an audit event for every write made to the modbus server
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// Path is the location of the JSON lines file the audit events are appended to
+	Path string `json:"path" env:"PATH" envDefault:"audit.jsonl"`
+	// MaxSizeMB is the size in megabytes at which the audit log file is rotated
+	MaxSizeMB int `json:"maxSizeMB" env:"MAX_SIZE_MB" envDefault:"100"`
+	// MaxBackups is the number of rotated audit log files to keep. If zero, all rotated files are kept
+	MaxBackups int `json:"maxBackups" env:"MAX_BACKUPS" envDefault:"0"`
+	// MaxAgeDays is the number of days to keep rotated audit log files for. If zero, files are not removed based on age
+	MaxAgeDays int `json:"maxAgeDays" env:"MAX_AGE_DAYS" envDefault:"0"`
+	// ReadBeforeWrite reads the current values from the modbus server before each write, so that the values being
+	// replaced are recorded in the audit event
+	ReadBeforeWrite bool `json:"readBeforeWrite" env:"READ_BEFORE_WRITE" envDefault:"false"`
+}
+
+// APIKey is a static API key which can be used to authenticate requests
+type APIKey struct {
+	// Name is the name of the principal authenticated by the key
+	Name string `json:"name" env:"NAME"`
+	// Hash is the hex encoded SHA-256 hash of the key
+	Hash string `json:"hash" env:"HASH"`
+}
+
+// JWT contains the config for authenticating requests using JWT bearer tokens
+type JWT struct {
+	// JWKSFile is the location of the JSON Web Key Set file containing the keys tokens are verified against. JWT bearer
+	// tokens are not accepted if it is empty.
+	JWKSFile string `json:"jwksFile" env:"JWKS_FILE" envDefault:""`
+	// Issuer is the required value of the "iss" claim. The issuer is not checked if it is empty.
+	Issuer string `json:"issuer" env:"ISSUER" envDefault:""`
+	// Audience is the value required in the "aud" claim. The audience is not checked if it is empty.
+	Audience string `json:"audience" env:"AUDIENCE" envDefault:""`
+	// PrincipalClaim is the claim used as the name of the authenticated principal. If empty, the "sub" claim is used.
+	PrincipalClaim string `json:"principalClaim" env:"PRINCIPAL_CLAIM" envDefault:"sub"`
+}
+
+// Auth contains the authentication config of the application
+type Auth struct {
+	// Enabled requires requests to the modbus and audit services to be authenticated
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// APIKeys are the static API keys accepted in the X-API-Key header
+	APIKeys []APIKey `json:"apiKeys" envPrefix:"API_KEYS"`
+	// JWT contains the config for accepting JWT bearer tokens in the Authorization header
+	JWT JWT `json:"jwt" envPrefix:"JWT_"`
+}
+
+// AddressRange is an inclusive range of addresses in one of the Modbus data tables
+type AddressRange struct {
+	// Table is the Modbus data table the range is in
+	Table Table `json:"table" env:"TABLE"`
+	// Start is the first address in the range
+	Start uint16 `json:"start" env:"START"`
+	// End is the last address in the range
+	End uint16 `json:"end" env:"END"`
+}
+
+// Role is a named set of permissions which can be granted to principals
+type Role struct {
+	// Name identifies the role in grants
+	Name string `json:"name" env:"NAME"`
+	// Procedures are the names of the RPC methods the role allows, for example "ReadCoils". "*" allows all methods.
+	Procedures []string `json:"procedures" env:"PROCEDURES"`
+	// Devices are the slave IDs of the modbus servers the role allows. If empty, all devices are allowed.
+	Devices []byte `json:"devices" env:"DEVICES"`
+	// AddressRanges are the addresses the role allows. If empty, all addresses are allowed.
+	AddressRanges []AddressRange `json:"addressRanges" envPrefix:"ADDRESS_RANGES"`
+}
+
+// Grant grants roles to a principal
+type Grant struct {
+	// Principal is the name of the principal, as established by authentication
+	Principal string `json:"principal" env:"PRINCIPAL"`
+	// Roles are the names of the roles granted to the principal
+	Roles []string `json:"roles" env:"ROLES"`
+}
+
+// Authorization contains the role based authorization config of the application
+type Authorization struct {
+	// Enabled requires every call to be allowed by a role granted to the calling principal
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// Roles are the roles which can be granted
+	Roles []Role `json:"roles" envPrefix:"ROLES"`
+	// Grants grant roles to principals
+	Grants []Grant `json:"grants" envPrefix:"GRANTS"`
+}
+
+// WriteRule restricts the values which can be written to a range of coils or holding registers. Coils are treated as
+// having the value 0 or 1.
+type WriteRule struct {
+	// Name identifies the rule in errors
+	Name string `json:"name" env:"NAME"`
+	// Table is the table the rule applies to, either coils or holdingRegisters
+	Table Table `json:"table" env:"TABLE"`
+	// Start is the first address the rule applies to
+	Start uint16 `json:"start" env:"START"`
+	// End is the last address the rule applies to
+	End uint16 `json:"end" env:"END"`
+	// Min is the smallest value which can be written, if set
+	Min *uint16 `json:"min,omitempty" env:"MIN"`
+	// Max is the largest value which can be written, if set
+	Max *uint16 `json:"max,omitempty" env:"MAX"`
+	// AllowedValues are the only values which can be written. If empty, any value can be written.
+	AllowedValues []uint16 `json:"allowedValues,omitempty" env:"ALLOWED_VALUES"`
+	// MaxStep is the largest change from the current value a single write can make, if set. The current value is read
+	// before each write to an address with a max step.
+	MaxStep *uint16 `json:"maxStep,omitempty" env:"MAX_STEP"`
+	// RequireArm rejects direct writes to the addresses, which must instead be armed using PrepareWrite and then
+	// performed using ExecuteWrite
+	RequireArm bool `json:"requireArm,omitempty" env:"REQUIRE_ARM"`
+}
+
+// Guardrails contains the config of the rules writes must follow before they are sent to the modbus server
+type Guardrails struct {
+	// Enabled enforces the write rules
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// DenyUnlisted rejects writes to addresses which are not covered by a rule, making the rules an allowlist
+	DenyUnlisted bool `json:"denyUnlisted" env:"DENY_UNLISTED" envDefault:"false"`
+	// Rules are the write rules. Every rule which applies to an address must be followed.
+	Rules []WriteRule `json:"rules" envPrefix:"RULES"`
+	// ArmTimeout is how long a write prepared using PrepareWrite can be executed for. If zero, 30 seconds is used.
+	ArmTimeout time.Duration `json:"armTimeout" env:"ARM_TIMEOUT" envDefault:"30s"`
+}
+
+// SlaveMapping maps consecutive addresses in a table to values extracted from the JSON response of an HTTP endpoint.
+type SlaveMapping struct {
+	// Table is the table the addresses are in
+	Table Table `json:"table" env:"TABLE"`
+	// Address is the first address mapped
+	Address uint16 `json:"address" env:"ADDRESS"`
+	// Quantity is the number of addresses mapped. If it is more than 1, the path must select an array with a value for
+	// each address. If zero, 1 is used.
+	Quantity uint16 `json:"quantity" env:"QUANTITY"`
+	// URL is the endpoint the values are read from using GET
+	URL string `json:"url" env:"URL"`
+	// Path is the JSON path of the values in the response, such as $.sensors[0].temperature
+	Path string `json:"path" env:"PATH"`
+	// Scale multiplies each value read before it is rounded to a register value, and divides each value written. If
+	// zero, 1 is used.
+	Scale float64 `json:"scale" env:"SCALE"`
+	// Signed stores register values as 16-bit two's complement, so that negative values can be read and written
+	Signed bool `json:"signed" env:"SIGNED"`
+	// WriteURL is the endpoint writes are sent to using POST. If empty, the addresses cannot be written. Only coils
+	// and holding registers can be written.
+	WriteURL string `json:"writeURL" env:"WRITE_URL"`
+}
+
+// Slave contains the config of slave mode, in which Modbus TCP masters can read and write values held by HTTP
+// endpoints
+type Slave struct {
+	// Host is the host to listen on for Modbus TCP connections
+	Host string `json:"host" env:"HOST"`
+	// Port is the port to listen on for Modbus TCP connections
+	Port int `json:"port" env:"PORT" envDefault:"502"`
+	// RefreshInterval is how often the endpoints are read. If zero, 5 seconds is used.
+	RefreshInterval time.Duration `json:"refreshInterval" env:"REFRESH_INTERVAL" envDefault:"5s"`
+	// MaxAge is how old values can be before reads of them return a server device failure exception. If zero, 3 times
+	// the refresh interval is used.
+	MaxAge time.Duration `json:"maxAge" env:"MAX_AGE" envDefault:"15s"`
+	// RequestTimeout is how long each HTTP request can take. If zero, 5 seconds is used.
+	RequestTimeout time.Duration `json:"requestTimeout" env:"REQUEST_TIMEOUT" envDefault:"5s"`
+	// Mappings map addresses to endpoints
+	Mappings []SlaveMapping `json:"mappings" envPrefix:"MAPPINGS"`
+}
+
+// ProxyPrincipal identifies the modbus masters which connect to the proxy from a network as a principal, so that their
+// requests can be authorized.
+type ProxyPrincipal struct {
+	// Network is the IP address or CIDR block the masters connect from, such as 10.0.1.0/24
+	Network string `json:"network" env:"NETWORK"`
+	// Principal is the name of the principal the masters are authorized as
+	Principal string `json:"principal" env:"PRINCIPAL"`
+}
+
+// Proxy contains the config of the Modbus TCP proxy, which forwards the transactions of modbus masters over the
+// connection to the modbus server
+type Proxy struct {
+	// Enabled listens for Modbus TCP connections from masters
+	Enabled bool `json:"enabled" env:"ENABLED" envDefault:"false"`
+	// Host is the host to listen on for Modbus TCP connections
+	Host string `json:"host" env:"HOST"`
+	// Port is the port to listen on for Modbus TCP connections
+	Port int `json:"port" env:"PORT" envDefault:"502"`
+	// CacheMaxAge is how long the responses to reads are served to other masters making the same read. If zero,
+	// reads are not cached.
+	CacheMaxAge time.Duration `json:"cacheMaxAge" env:"CACHE_MAX_AGE" envDefault:"0s"`
+	// Principals identify masters by the network they connect from. Masters which are not identified have no
+	// principal, so all of their requests are denied if authorization is enabled.
+	Principals []ProxyPrincipal `json:"principals" envPrefix:"PRINCIPALS"`
+}
+
+// Gateway contains the config of gateway mode, in which Modbus TCP masters access the slaves on an RTU serial bus
+type Gateway struct {
+	// Host is the host to listen on for Modbus TCP connections
+	Host string `json:"host" env:"HOST"`
+	// Port is the port to listen on for Modbus TCP connections
+	Port int `json:"port" env:"PORT" envDefault:"502"`
+	// Device is the serial port the RTU bus is connected to
+	Device string `json:"device" env:"DEVICE" envDefault:"/dev/ttyUSB0"`
+	// BaudRate is the baud rate of the bus. If zero, 19200 is used.
+	BaudRate int `json:"baudRate" env:"BAUD_RATE" envDefault:"19200"`
+	// DataBits is the number of data bits in each character. If zero, 8 is used.
+	DataBits int `json:"dataBits" env:"DATA_BITS" envDefault:"8"`
+	// Parity is N for none, E for even or O for odd. If empty, even parity is used.
+	Parity string `json:"parity" env:"PARITY" envDefault:"E"`
+	// StopBits is the number of stop bits in each character. If zero, 1 is used.
+	StopBits int `json:"stopBits" env:"STOP_BITS" envDefault:"1"`
+	// ResponseTimeout is how long to wait for a slave to respond before returning a gateway target device failed to
+	// respond exception. If zero, 1 second is used.
+	ResponseTimeout time.Duration `json:"responseTimeout" env:"RESPONSE_TIMEOUT" envDefault:"1s"`
+}
+
+// Duration is a time.Duration which is decoded from a string such as "1.5s", as accepted by time.ParseDuration.
+type Duration time.Duration
+
+// UnmarshalJSON decodes the duration from a string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// WaveformShape is the shape of a simulated waveform.
+type WaveformShape string
+
+const (
+	// Sine varies between the min and max once per period
+	Sine WaveformShape = "sine"
+	// Ramp rises from the min to the max over each period
+	Ramp WaveformShape = "ramp"
+	// RandomWalk changes by a random amount of at most the step at each interval, starting halfway between the min and
+	// max
+	RandomWalk WaveformShape = "randomWalk"
+)
+
+// SimulatorValues are the initial values of consecutive addresses in a table. Coils and discrete inputs are on if
+// their value is not zero.
+type SimulatorValues struct {
+	// Table is the table the values are in
+	Table Table `json:"table"`
+	// Address is the address of the first value
+	Address uint16 `json:"address"`
+	// Values are the values starting at the address
+	Values []uint16 `json:"values"`
+}
+
+// SimulatorWaveform drives the value of a register using a waveform.
+type SimulatorWaveform struct {
+	// Table is the table the register is in, either holdingRegisters or inputRegisters
+	Table Table `json:"table"`
+	// Address is the address of the register
+	Address uint16 `json:"address"`
+	// Shape is the shape of the waveform
+	Shape WaveformShape `json:"shape"`
+	// Min is the smallest value of the waveform
+	Min uint16 `json:"min"`
+	// Max is the largest value of the waveform
+	Max uint16 `json:"max"`
+	// Period is the period of sine and ramp waveforms
+	Period Duration `json:"period"`
+	// Step is the largest change of a random walk at each interval. If zero, 1 is used.
+	Step uint16 `json:"step"`
+	// Interval is how often the register is updated. If zero, 100ms is used.
+	Interval Duration `json:"interval"`
+}
+
+// SimulatorToggle sets a discrete input to the value of a coil some time after the coil is written, such as a motor
+// reporting it is running after it is started.
+type SimulatorToggle struct {
+	// Coil is the address of the coil
+	Coil uint16 `json:"coil"`
+	// Input is the address of the discrete input
+	Input uint16 `json:"input"`
+	// Delay is how long after the coil is written the input is set
+	Delay Duration `json:"delay"`
+}
+
+// SimulatorMirror copies the value written to a holding register into a status register, such as a setpoint being
+// acknowledged.
+type SimulatorMirror struct {
+	// Register is the address of the holding register
+	Register uint16 `json:"register"`
+	// StatusTable is the table the status register is in, either holdingRegisters or inputRegisters
+	StatusTable Table `json:"statusTable"`
+	// Status is the address of the status register
+	Status uint16 `json:"status"`
+	// Delay is how long after the holding register is written the status register is set
+	Delay Duration `json:"delay"`
+}
+
+// SimulatorProfile describes the behaviour of a simulated modbus device
+type SimulatorProfile struct {
+	// Values are the initial values of the tables
+	Values []SimulatorValues `json:"values"`
+	// Waveforms drive the values of registers
+	Waveforms []SimulatorWaveform `json:"waveforms"`
+	// Toggles set discrete inputs after coils are written
+	Toggles []SimulatorToggle `json:"toggles"`
+	// Mirrors copy writes to holding registers into status registers
+	Mirrors []SimulatorMirror `json:"mirrors"`
 }
 
 // App is the modbustohttp application config
@@ -52,6 +556,24 @@ type App struct {
 	Modbus Modbus `json:"modbus" envPrefix:"MODBUS_"`
 	// HTTP contains HTTP specific config
 	HTTP HTTP `json:"http" envPrefix:"HTTP_"`
+	// Tracing contains OpenTelemetry tracing config
+	Tracing Tracing `json:"tracing" envPrefix:"TRACING_"`
+	// Audit contains write audit log config
+	Audit Audit `json:"audit" envPrefix:"AUDIT_"`
+	// Auth contains authentication config
+	Auth Auth `json:"auth" envPrefix:"AUTH_"`
+	// Authorization contains role based authorization config
+	Authorization Authorization `json:"authorization" envPrefix:"AUTHZ_"`
+	// Guardrails contains safe write rules
+	Guardrails Guardrails `json:"guardrails" envPrefix:"GUARDRAILS_"`
+	// Slave contains the config of slave mode
+	Slave Slave `json:"slave" envPrefix:"SLAVE_"`
+	// Proxy contains the config of the Modbus TCP proxy
+	Proxy Proxy `json:"proxy" envPrefix:"PROXY_"`
+	// Gateway contains the config of gateway mode
+	Gateway Gateway `json:"gateway" envPrefix:"GATEWAY_"`
+	// RateLimit contains the config of the rate limit of HTTP clients
+	RateLimit ClientRateLimit `json:"rateLimit" envPrefix:"RATE_LIMIT_"`
 }
 
 // LoadAppConfig loads the application config from the given path. If path is nil then config will be loaded from
@@ -89,3 +611,30 @@ func LoadAppConfig(path *string) (*App, error) {
 	}
 	return &app, nil
 }
+
+// LoadSimulatorProfile loads a simulator profile from the given path. Files with a .yaml or .yml extension are decoded as
+// YAML, using the same field names as JSON, and any other file is decoded as JSON.
+func LoadSimulatorProfile(path string) (*SimulatorProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		// Convert the YAML to JSON so that the profile is decoded the same way whichever format it is written in.
+		var document any
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var profile SimulatorProfile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
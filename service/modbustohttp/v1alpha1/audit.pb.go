@@ -0,0 +1,477 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: modbustohttp/v1alpha1/audit.proto
+
+package v1alpha1
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A write made to the modbus server
+type AuditEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the write was made
+	Time *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// The principal which made the write, or "anonymous" if the request was not authenticated
+	Principal string `protobuf:"bytes,2,opt,name=principal,proto3" json:"principal,omitempty"`
+	// The network address the request was received from
+	Peer string `protobuf:"bytes,3,opt,name=peer,proto3" json:"peer,omitempty"`
+	// The RPC procedure used to make the write
+	Procedure string `protobuf:"bytes,4,opt,name=procedure,proto3" json:"procedure,omitempty"`
+	// The table written to
+	Table Table `protobuf:"varint,5,opt,name=table,proto3,enum=modbustohttp.v1alpha1.Table" json:"table,omitempty"`
+	// The first address written to
+	Address uint32 `protobuf:"varint,6,opt,name=address,proto3" json:"address,omitempty"`
+	// The values which were replaced, starting at address. Coils are 0 or 1. Empty if not read before the write.
+	OldValues []uint32 `protobuf:"varint,7,rep,packed,name=old_values,json=oldValues,proto3" json:"old_values,omitempty"`
+	// The values written, starting at address. Coils are 0 or 1. Only set for masked writes if the result is known.
+	NewValues []uint32 `protobuf:"varint,8,rep,packed,name=new_values,json=newValues,proto3" json:"new_values,omitempty"`
+	// The AND mask of a masked write to a single holding register
+	AndMask *uint32 `protobuf:"varint,9,opt,name=and_mask,json=andMask,proto3,oneof" json:"and_mask,omitempty"`
+	// The OR mask of a masked write to a single holding register
+	OrMask *uint32 `protobuf:"varint,10,opt,name=or_mask,json=orMask,proto3,oneof" json:"or_mask,omitempty"`
+	// Whether the write succeeded
+	Success bool `protobuf:"varint,11,opt,name=success,proto3" json:"success,omitempty"`
+	// The error returned if the write did not succeed
+	Error string `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	// The file written to, for writes to file records. The address is then the first record number written to.
+	FileNumber uint32 `protobuf:"varint,13,opt,name=file_number,json=fileNumber,proto3" json:"file_number,omitempty"`
+	// The function code of a raw PDU. The table is then unspecified.
+	FunctionCode uint32 `protobuf:"varint,14,opt,name=function_code,json=functionCode,proto3" json:"function_code,omitempty"`
+	// The data of a raw PDU
+	Data []byte `protobuf:"bytes,15,opt,name=data,proto3" json:"data,omitempty"`
+	// The unit ID a raw PDU was sent to
+	UnitId        uint32 `protobuf:"varint,16,opt,name=unit_id,json=unitId,proto3" json:"unit_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_audit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuditEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetProcedure() string {
+	if x != nil {
+		return x.Procedure
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetTable() Table {
+	if x != nil {
+		return x.Table
+	}
+	return Table_TABLE_UNSPECIFIED
+}
+
+func (x *AuditEvent) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetOldValues() []uint32 {
+	if x != nil {
+		return x.OldValues
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetNewValues() []uint32 {
+	if x != nil {
+		return x.NewValues
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetAndMask() uint32 {
+	if x != nil && x.AndMask != nil {
+		return *x.AndMask
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetOrMask() uint32 {
+	if x != nil && x.OrMask != nil {
+		return *x.OrMask
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AuditEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetFileNumber() uint32 {
+	if x != nil {
+		return x.FileNumber
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetFunctionCode() uint32 {
+	if x != nil {
+		return x.FunctionCode
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetUnitId() uint32 {
+	if x != nil {
+		return x.UnitId
+	}
+	return 0
+}
+
+type ListAuditEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list events made by this principal
+	Principal *string `protobuf:"bytes,1,opt,name=principal,proto3,oneof" json:"principal,omitempty"`
+	// Only list events which wrote to this table
+	Table Table `protobuf:"varint,2,opt,name=table,proto3,enum=modbustohttp.v1alpha1.Table" json:"table,omitempty"`
+	// Only list events which wrote to an address at or after this address
+	StartAddress *uint32 `protobuf:"varint,3,opt,name=start_address,json=startAddress,proto3,oneof" json:"start_address,omitempty"`
+	// Only list events which wrote to an address at or before this address
+	EndAddress *uint32 `protobuf:"varint,4,opt,name=end_address,json=endAddress,proto3,oneof" json:"end_address,omitempty"`
+	// Only list events at or after this time
+	Since *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	// Only list events before this time
+	Until *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`
+	// Only list events with this outcome
+	Success *bool `protobuf:"varint,7,opt,name=success,proto3,oneof" json:"success,omitempty"`
+	// The maximum number of events to list, defaults to 100
+	Limit         *uint32 `protobuf:"varint,8,opt,name=limit,proto3,oneof" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsRequest) Reset() {
+	*x = ListAuditEventsRequest{}
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsRequest) ProtoMessage() {}
+
+func (x *ListAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_audit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListAuditEventsRequest) GetPrincipal() string {
+	if x != nil && x.Principal != nil {
+		return *x.Principal
+	}
+	return ""
+}
+
+func (x *ListAuditEventsRequest) GetTable() Table {
+	if x != nil {
+		return x.Table
+	}
+	return Table_TABLE_UNSPECIFIED
+}
+
+func (x *ListAuditEventsRequest) GetStartAddress() uint32 {
+	if x != nil && x.StartAddress != nil {
+		return *x.StartAddress
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetEndAddress() uint32 {
+	if x != nil && x.EndAddress != nil {
+		return *x.EndAddress
+	}
+	return 0
+}
+
+func (x *ListAuditEventsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListAuditEventsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *ListAuditEventsRequest) GetSuccess() bool {
+	if x != nil && x.Success != nil {
+		return *x.Success
+	}
+	return false
+}
+
+func (x *ListAuditEventsRequest) GetLimit() uint32 {
+	if x != nil && x.Limit != nil {
+		return *x.Limit
+	}
+	return 0
+}
+
+type ListAuditEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The matching events, newest first
+	Events        []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEventsResponse) Reset() {
+	*x = ListAuditEventsResponse{}
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEventsResponse) ProtoMessage() {}
+
+func (x *ListAuditEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_audit_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_audit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+var File_modbustohttp_v1alpha1_audit_proto protoreflect.FileDescriptor
+
+const file_modbustohttp_v1alpha1_audit_proto_rawDesc = "" +
+	"\n" +
+	"!modbustohttp/v1alpha1/audit.proto\x12\x15modbustohttp.v1alpha1\x1a!modbustohttp/v1alpha1/types.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x92\x04\n" +
+	"\n" +
+	"AuditEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1c\n" +
+	"\tprincipal\x18\x02 \x01(\tR\tprincipal\x12\x12\n" +
+	"\x04peer\x18\x03 \x01(\tR\x04peer\x12\x1c\n" +
+	"\tprocedure\x18\x04 \x01(\tR\tprocedure\x122\n" +
+	"\x05table\x18\x05 \x01(\x0e2\x1c.modbustohttp.v1alpha1.TableR\x05table\x12\x18\n" +
+	"\aaddress\x18\x06 \x01(\rR\aaddress\x12\x1d\n" +
+	"\n" +
+	"old_values\x18\a \x03(\rR\toldValues\x12\x1d\n" +
+	"\n" +
+	"new_values\x18\b \x03(\rR\tnewValues\x12\x1e\n" +
+	"\band_mask\x18\t \x01(\rH\x00R\aandMask\x88\x01\x01\x12\x1c\n" +
+	"\aor_mask\x18\n" +
+	" \x01(\rH\x01R\x06orMask\x88\x01\x01\x12\x18\n" +
+	"\asuccess\x18\v \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\x12\x1f\n" +
+	"\vfile_number\x18\r \x01(\rR\n" +
+	"fileNumber\x12#\n" +
+	"\rfunction_code\x18\x0e \x01(\rR\ffunctionCode\x12\x12\n" +
+	"\x04data\x18\x0f \x01(\fR\x04data\x12\x17\n" +
+	"\aunit_id\x18\x10 \x01(\rR\x06unitIdB\v\n" +
+	"\t_and_maskB\n" +
+	"\n" +
+	"\b_or_mask\"\x80\x05\n" +
+	"\x16ListAuditEventsRequest\x12!\n" +
+	"\tprincipal\x18\x01 \x01(\tH\x00R\tprincipal\x88\x01\x01\x12B\n" +
+	"\x05table\x18\x02 \x01(\x0e2\x1c.modbustohttp.v1alpha1.TableB\x0e\xbaH\v\x82\x01\b\x18\x00\x18\x01\x18\x04\x18\x05R\x05table\x123\n" +
+	"\rstart_address\x18\x03 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03H\x01R\fstartAddress\x88\x01\x01\x12/\n" +
+	"\vend_address\x18\x04 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03H\x02R\n" +
+	"endAddress\x88\x01\x01\x120\n" +
+	"\x05since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x1d\n" +
+	"\asuccess\x18\a \x01(\bH\x03R\asuccess\x88\x01\x01\x12%\n" +
+	"\x05limit\x18\b \x01(\rB\n" +
+	"\xbaH\a*\x05\x18\xe8\a \x00H\x04R\x05limit\x88\x01\x01:\xa8\x01\xbaH\xa4\x01\x1a\xa1\x01\n" +
+	"\raddress.range\x122start_address must not be greater than end_address\x1a\\!has(this.start_address) || !has(this.end_address) || this.start_address <= this.end_addressB\f\n" +
+	"\n" +
+	"_principalB\x10\n" +
+	"\x0e_start_addressB\x0e\n" +
+	"\f_end_addressB\n" +
+	"\n" +
+	"\b_successB\b\n" +
+	"\x06_limit\"T\n" +
+	"\x17ListAuditEventsResponse\x129\n" +
+	"\x06events\x18\x01 \x03(\v2!.modbustohttp.v1alpha1.AuditEventR\x06events2\x85\x01\n" +
+	"\fAuditService\x12u\n" +
+	"\x0fListAuditEvents\x12-.modbustohttp.v1alpha1.ListAuditEventsRequest\x1a..modbustohttp.v1alpha1.ListAuditEventsResponse\"\x03\x90\x02\x01B\xc8\x01\n" +
+	"\x19com.modbustohttp.v1alpha1B\n" +
+	"AuditProtoP\x01Z*modbustohttp/service/modbustohttp/v1alpha1\xa2\x02\x03MXX\xaa\x02\x15Modbustohttp.V1alpha1\xca\x02\x15Modbustohttp\\V1alpha1\xe2\x02!Modbustohttp\\V1alpha1\\GPBMetadata\xea\x02\x16Modbustohttp::V1alpha1b\x06proto3"
+
+var (
+	file_modbustohttp_v1alpha1_audit_proto_rawDescOnce sync.Once
+	file_modbustohttp_v1alpha1_audit_proto_rawDescData []byte
+)
+
+func file_modbustohttp_v1alpha1_audit_proto_rawDescGZIP() []byte {
+	file_modbustohttp_v1alpha1_audit_proto_rawDescOnce.Do(func() {
+		file_modbustohttp_v1alpha1_audit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_modbustohttp_v1alpha1_audit_proto_rawDesc), len(file_modbustohttp_v1alpha1_audit_proto_rawDesc)))
+	})
+	return file_modbustohttp_v1alpha1_audit_proto_rawDescData
+}
+
+var file_modbustohttp_v1alpha1_audit_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_modbustohttp_v1alpha1_audit_proto_goTypes = []any{
+	(*AuditEvent)(nil),              // 0: modbustohttp.v1alpha1.AuditEvent
+	(*ListAuditEventsRequest)(nil),  // 1: modbustohttp.v1alpha1.ListAuditEventsRequest
+	(*ListAuditEventsResponse)(nil), // 2: modbustohttp.v1alpha1.ListAuditEventsResponse
+	(*timestamppb.Timestamp)(nil),   // 3: google.protobuf.Timestamp
+	(Table)(0),                      // 4: modbustohttp.v1alpha1.Table
+}
+var file_modbustohttp_v1alpha1_audit_proto_depIdxs = []int32{
+	3, // 0: modbustohttp.v1alpha1.AuditEvent.time:type_name -> google.protobuf.Timestamp
+	4, // 1: modbustohttp.v1alpha1.AuditEvent.table:type_name -> modbustohttp.v1alpha1.Table
+	4, // 2: modbustohttp.v1alpha1.ListAuditEventsRequest.table:type_name -> modbustohttp.v1alpha1.Table
+	3, // 3: modbustohttp.v1alpha1.ListAuditEventsRequest.since:type_name -> google.protobuf.Timestamp
+	3, // 4: modbustohttp.v1alpha1.ListAuditEventsRequest.until:type_name -> google.protobuf.Timestamp
+	0, // 5: modbustohttp.v1alpha1.ListAuditEventsResponse.events:type_name -> modbustohttp.v1alpha1.AuditEvent
+	1, // 6: modbustohttp.v1alpha1.AuditService.ListAuditEvents:input_type -> modbustohttp.v1alpha1.ListAuditEventsRequest
+	2, // 7: modbustohttp.v1alpha1.AuditService.ListAuditEvents:output_type -> modbustohttp.v1alpha1.ListAuditEventsResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_modbustohttp_v1alpha1_audit_proto_init() }
+func file_modbustohttp_v1alpha1_audit_proto_init() {
+	if File_modbustohttp_v1alpha1_audit_proto != nil {
+		return
+	}
+	file_modbustohttp_v1alpha1_types_proto_init()
+	file_modbustohttp_v1alpha1_audit_proto_msgTypes[0].OneofWrappers = []any{}
+	file_modbustohttp_v1alpha1_audit_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_modbustohttp_v1alpha1_audit_proto_rawDesc), len(file_modbustohttp_v1alpha1_audit_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_modbustohttp_v1alpha1_audit_proto_goTypes,
+		DependencyIndexes: file_modbustohttp_v1alpha1_audit_proto_depIdxs,
+		MessageInfos:      file_modbustohttp_v1alpha1_audit_proto_msgTypes,
+	}.Build()
+	File_modbustohttp_v1alpha1_audit_proto = out.File
+	file_modbustohttp_v1alpha1_audit_proto_goTypes = nil
+	file_modbustohttp_v1alpha1_audit_proto_depIdxs = nil
+}
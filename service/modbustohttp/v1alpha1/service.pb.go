@@ -10,6 +10,7 @@ import (
 	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -942,11 +943,1576 @@ func (x *ReadRegisterAsBitsResponse) GetBits() []*BooleanAddress {
 	return nil
 }
 
+type PrepareWriteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The write to prepare
+	//
+	// Types that are valid to be assigned to Write:
+	//
+	//	*PrepareWriteRequest_Coil
+	//	*PrepareWriteRequest_Register
+	Write         isPrepareWriteRequest_Write `protobuf_oneof:"write"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareWriteRequest) Reset() {
+	*x = PrepareWriteRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareWriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareWriteRequest) ProtoMessage() {}
+
+func (x *PrepareWriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareWriteRequest.ProtoReflect.Descriptor instead.
+func (*PrepareWriteRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PrepareWriteRequest) GetWrite() isPrepareWriteRequest_Write {
+	if x != nil {
+		return x.Write
+	}
+	return nil
+}
+
+func (x *PrepareWriteRequest) GetCoil() *BooleanAddress {
+	if x != nil {
+		if x, ok := x.Write.(*PrepareWriteRequest_Coil); ok {
+			return x.Coil
+		}
+	}
+	return nil
+}
+
+func (x *PrepareWriteRequest) GetRegister() *Register {
+	if x != nil {
+		if x, ok := x.Write.(*PrepareWriteRequest_Register); ok {
+			return x.Register
+		}
+	}
+	return nil
+}
+
+type isPrepareWriteRequest_Write interface {
+	isPrepareWriteRequest_Write()
+}
+
+type PrepareWriteRequest_Coil struct {
+	// The coil to write
+	Coil *BooleanAddress `protobuf:"bytes,1,opt,name=coil,proto3,oneof"`
+}
+
+type PrepareWriteRequest_Register struct {
+	// The holding register to write
+	Register *Register `protobuf:"bytes,2,opt,name=register,proto3,oneof"`
+}
+
+func (*PrepareWriteRequest_Coil) isPrepareWriteRequest_Write() {}
+
+func (*PrepareWriteRequest_Register) isPrepareWriteRequest_Write() {}
+
+type PrepareWriteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The token which must be passed to ExecuteWrite to perform the write. It can only be used once.
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// When the token expires
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// The table which will be written to
+	Table Table `protobuf:"varint,3,opt,name=table,proto3,enum=modbustohttp.v1alpha1.Table" json:"table,omitempty"`
+	// The address which will be written to
+	Address uint32 `protobuf:"varint,4,opt,name=address,proto3" json:"address,omitempty"`
+	// The value which will be written. Coils are 0 or 1.
+	Value uint32 `protobuf:"varint,5,opt,name=value,proto3" json:"value,omitempty"`
+	// The current value, which must not change before the write is executed. Coils are 0 or 1.
+	CurrentValue  uint32 `protobuf:"varint,6,opt,name=current_value,json=currentValue,proto3" json:"current_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareWriteResponse) Reset() {
+	*x = PrepareWriteResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareWriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareWriteResponse) ProtoMessage() {}
+
+func (x *PrepareWriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareWriteResponse.ProtoReflect.Descriptor instead.
+func (*PrepareWriteResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PrepareWriteResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PrepareWriteResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *PrepareWriteResponse) GetTable() Table {
+	if x != nil {
+		return x.Table
+	}
+	return Table_TABLE_UNSPECIFIED
+}
+
+func (x *PrepareWriteResponse) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *PrepareWriteResponse) GetValue() uint32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *PrepareWriteResponse) GetCurrentValue() uint32 {
+	if x != nil {
+		return x.CurrentValue
+	}
+	return 0
+}
+
+type ExecuteWriteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The token returned by PrepareWrite
+	Token         string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteWriteRequest) Reset() {
+	*x = ExecuteWriteRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteWriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteWriteRequest) ProtoMessage() {}
+
+func (x *ExecuteWriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteWriteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteWriteRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ExecuteWriteRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type ExecuteWriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteWriteResponse) Reset() {
+	*x = ExecuteWriteResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteWriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteWriteResponse) ProtoMessage() {}
+
+func (x *ExecuteWriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteWriteResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteWriteResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{23}
+}
+
+type ReadWriteMultipleRegistersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The address to start reading from
+	ReadAddress uint32 `protobuf:"varint,1,opt,name=read_address,json=readAddress,proto3" json:"read_address,omitempty"`
+	// The number of registers to read
+	ReadQuantity uint32 `protobuf:"varint,2,opt,name=read_quantity,json=readQuantity,proto3" json:"read_quantity,omitempty"`
+	// The address to start writing to
+	WriteAddress uint32 `protobuf:"varint,3,opt,name=write_address,json=writeAddress,proto3" json:"write_address,omitempty"`
+	// The values to write, which are written before the registers are read
+	Values        []uint32 `protobuf:"varint,4,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadWriteMultipleRegistersRequest) Reset() {
+	*x = ReadWriteMultipleRegistersRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadWriteMultipleRegistersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadWriteMultipleRegistersRequest) ProtoMessage() {}
+
+func (x *ReadWriteMultipleRegistersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadWriteMultipleRegistersRequest.ProtoReflect.Descriptor instead.
+func (*ReadWriteMultipleRegistersRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReadWriteMultipleRegistersRequest) GetReadAddress() uint32 {
+	if x != nil {
+		return x.ReadAddress
+	}
+	return 0
+}
+
+func (x *ReadWriteMultipleRegistersRequest) GetReadQuantity() uint32 {
+	if x != nil {
+		return x.ReadQuantity
+	}
+	return 0
+}
+
+func (x *ReadWriteMultipleRegistersRequest) GetWriteAddress() uint32 {
+	if x != nil {
+		return x.WriteAddress
+	}
+	return 0
+}
+
+func (x *ReadWriteMultipleRegistersRequest) GetValues() []uint32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ReadWriteMultipleRegistersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The registers read
+	Registers     []*Register `protobuf:"bytes,1,rep,name=registers,proto3" json:"registers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadWriteMultipleRegistersResponse) Reset() {
+	*x = ReadWriteMultipleRegistersResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadWriteMultipleRegistersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadWriteMultipleRegistersResponse) ProtoMessage() {}
+
+func (x *ReadWriteMultipleRegistersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadWriteMultipleRegistersResponse.ProtoReflect.Descriptor instead.
+func (*ReadWriteMultipleRegistersResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ReadWriteMultipleRegistersResponse) GetRegisters() []*Register {
+	if x != nil {
+		return x.Registers
+	}
+	return nil
+}
+
+type MaskWriteRegisterRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The address of the register
+	Address uint32 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	// The AND mask. Bits which are 1 keep their current value.
+	AndMask uint32 `protobuf:"varint,2,opt,name=and_mask,json=andMask,proto3" json:"and_mask,omitempty"`
+	// The OR mask. Bits which are 0 in the AND mask are set to their value in the OR mask.
+	OrMask        uint32 `protobuf:"varint,3,opt,name=or_mask,json=orMask,proto3" json:"or_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaskWriteRegisterRequest) Reset() {
+	*x = MaskWriteRegisterRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaskWriteRegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskWriteRegisterRequest) ProtoMessage() {}
+
+func (x *MaskWriteRegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskWriteRegisterRequest.ProtoReflect.Descriptor instead.
+func (*MaskWriteRegisterRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MaskWriteRegisterRequest) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *MaskWriteRegisterRequest) GetAndMask() uint32 {
+	if x != nil {
+		return x.AndMask
+	}
+	return 0
+}
+
+func (x *MaskWriteRegisterRequest) GetOrMask() uint32 {
+	if x != nil {
+		return x.OrMask
+	}
+	return 0
+}
+
+type MaskWriteRegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaskWriteRegisterResponse) Reset() {
+	*x = MaskWriteRegisterResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaskWriteRegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaskWriteRegisterResponse) ProtoMessage() {}
+
+func (x *MaskWriteRegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaskWriteRegisterResponse.ProtoReflect.Descriptor instead.
+func (*MaskWriteRegisterResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{27}
+}
+
+type WriteBitsInRegisterRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The address of the register
+	Address uint32 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	// The bits to write, where the address of each bit is its position in the register (0-15)
+	Bits          []*BooleanAddress `protobuf:"bytes,2,rep,name=bits,proto3" json:"bits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteBitsInRegisterRequest) Reset() {
+	*x = WriteBitsInRegisterRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteBitsInRegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteBitsInRegisterRequest) ProtoMessage() {}
+
+func (x *WriteBitsInRegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteBitsInRegisterRequest.ProtoReflect.Descriptor instead.
+func (*WriteBitsInRegisterRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *WriteBitsInRegisterRequest) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *WriteBitsInRegisterRequest) GetBits() []*BooleanAddress {
+	if x != nil {
+		return x.Bits
+	}
+	return nil
+}
+
+type WriteBitsInRegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteBitsInRegisterResponse) Reset() {
+	*x = WriteBitsInRegisterResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteBitsInRegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteBitsInRegisterResponse) ProtoMessage() {}
+
+func (x *WriteBitsInRegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteBitsInRegisterResponse.ProtoReflect.Descriptor instead.
+func (*WriteBitsInRegisterResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{29}
+}
+
+type ReadDeviceIdentificationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The category of objects to read
+	Category DeviceIdentificationCategory `protobuf:"varint,1,opt,name=category,proto3,enum=modbustohttp.v1alpha1.DeviceIdentificationCategory" json:"category,omitempty"`
+	// The ID of the object to read for individual access, or the ID of the first object to read otherwise
+	ObjectId      uint32 `protobuf:"varint,2,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDeviceIdentificationRequest) Reset() {
+	*x = ReadDeviceIdentificationRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDeviceIdentificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDeviceIdentificationRequest) ProtoMessage() {}
+
+func (x *ReadDeviceIdentificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDeviceIdentificationRequest.ProtoReflect.Descriptor instead.
+func (*ReadDeviceIdentificationRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ReadDeviceIdentificationRequest) GetCategory() DeviceIdentificationCategory {
+	if x != nil {
+		return x.Category
+	}
+	return DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_UNSPECIFIED
+}
+
+func (x *ReadDeviceIdentificationRequest) GetObjectId() uint32 {
+	if x != nil {
+		return x.ObjectId
+	}
+	return 0
+}
+
+type ReadDeviceIdentificationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The conformity level of the modbus server, which describes the categories and access it supports
+	ConformityLevel uint32 `protobuf:"varint,1,opt,name=conformity_level,json=conformityLevel,proto3" json:"conformity_level,omitempty"`
+	// The vendor name (object 0x00)
+	VendorName string `protobuf:"bytes,2,opt,name=vendor_name,json=vendorName,proto3" json:"vendor_name,omitempty"`
+	// The product code (object 0x01)
+	ProductCode string `protobuf:"bytes,3,opt,name=product_code,json=productCode,proto3" json:"product_code,omitempty"`
+	// The major and minor revision (object 0x02)
+	Revision string `protobuf:"bytes,4,opt,name=revision,proto3" json:"revision,omitempty"`
+	// All objects read, by object ID, including the vendor name, product code and revision
+	Objects       map[uint32]string `protobuf:"bytes,5,rep,name=objects,proto3" json:"objects,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDeviceIdentificationResponse) Reset() {
+	*x = ReadDeviceIdentificationResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDeviceIdentificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDeviceIdentificationResponse) ProtoMessage() {}
+
+func (x *ReadDeviceIdentificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDeviceIdentificationResponse.ProtoReflect.Descriptor instead.
+func (*ReadDeviceIdentificationResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ReadDeviceIdentificationResponse) GetConformityLevel() uint32 {
+	if x != nil {
+		return x.ConformityLevel
+	}
+	return 0
+}
+
+func (x *ReadDeviceIdentificationResponse) GetVendorName() string {
+	if x != nil {
+		return x.VendorName
+	}
+	return ""
+}
+
+func (x *ReadDeviceIdentificationResponse) GetProductCode() string {
+	if x != nil {
+		return x.ProductCode
+	}
+	return ""
+}
+
+func (x *ReadDeviceIdentificationResponse) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *ReadDeviceIdentificationResponse) GetObjects() map[uint32]string {
+	if x != nil {
+		return x.Objects
+	}
+	return nil
+}
+
+type ReturnQueryDataRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The data to send, which is returned unchanged
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnQueryDataRequest) Reset() {
+	*x = ReturnQueryDataRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnQueryDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnQueryDataRequest) ProtoMessage() {}
+
+func (x *ReturnQueryDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnQueryDataRequest.ProtoReflect.Descriptor instead.
+func (*ReturnQueryDataRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ReturnQueryDataRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ReturnQueryDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The data returned by the modbus server
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnQueryDataResponse) Reset() {
+	*x = ReturnQueryDataResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnQueryDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnQueryDataResponse) ProtoMessage() {}
+
+func (x *ReturnQueryDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnQueryDataResponse.ProtoReflect.Descriptor instead.
+func (*ReturnQueryDataResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ReturnQueryDataResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ClearDiagnosticCountersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearDiagnosticCountersRequest) Reset() {
+	*x = ClearDiagnosticCountersRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearDiagnosticCountersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearDiagnosticCountersRequest) ProtoMessage() {}
+
+func (x *ClearDiagnosticCountersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearDiagnosticCountersRequest.ProtoReflect.Descriptor instead.
+func (*ClearDiagnosticCountersRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{34}
+}
+
+type ClearDiagnosticCountersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearDiagnosticCountersResponse) Reset() {
+	*x = ClearDiagnosticCountersResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearDiagnosticCountersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearDiagnosticCountersResponse) ProtoMessage() {}
+
+func (x *ClearDiagnosticCountersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearDiagnosticCountersResponse.ProtoReflect.Descriptor instead.
+func (*ClearDiagnosticCountersResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{35}
+}
+
+type GetDiagnosticCountersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDiagnosticCountersRequest) Reset() {
+	*x = GetDiagnosticCountersRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDiagnosticCountersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDiagnosticCountersRequest) ProtoMessage() {}
+
+func (x *GetDiagnosticCountersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDiagnosticCountersRequest.ProtoReflect.Descriptor instead.
+func (*GetDiagnosticCountersRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{36}
+}
+
+// Each counter is only set if reading it is supported
+type GetDiagnosticCountersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The number of messages the modbus server has detected on the bus
+	BusMessageCount *uint32 `protobuf:"varint,1,opt,name=bus_message_count,json=busMessageCount,proto3,oneof" json:"bus_message_count,omitempty"`
+	// The number of CRC errors the modbus server has detected on the bus
+	BusCommunicationErrorCount *uint32 `protobuf:"varint,2,opt,name=bus_communication_error_count,json=busCommunicationErrorCount,proto3,oneof" json:"bus_communication_error_count,omitempty"`
+	// The number of exception responses returned by the modbus server
+	BusExceptionErrorCount *uint32 `protobuf:"varint,3,opt,name=bus_exception_error_count,json=busExceptionErrorCount,proto3,oneof" json:"bus_exception_error_count,omitempty"`
+	// The number of messages addressed to the modbus server, or broadcast
+	ServerMessageCount *uint32 `protobuf:"varint,4,opt,name=server_message_count,json=serverMessageCount,proto3,oneof" json:"server_message_count,omitempty"`
+	// The number of messages addressed to the modbus server for which it returned no response
+	ServerNoResponseCount *uint32 `protobuf:"varint,5,opt,name=server_no_response_count,json=serverNoResponseCount,proto3,oneof" json:"server_no_response_count,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *GetDiagnosticCountersResponse) Reset() {
+	*x = GetDiagnosticCountersResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDiagnosticCountersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDiagnosticCountersResponse) ProtoMessage() {}
+
+func (x *GetDiagnosticCountersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDiagnosticCountersResponse.ProtoReflect.Descriptor instead.
+func (*GetDiagnosticCountersResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetDiagnosticCountersResponse) GetBusMessageCount() uint32 {
+	if x != nil && x.BusMessageCount != nil {
+		return *x.BusMessageCount
+	}
+	return 0
+}
+
+func (x *GetDiagnosticCountersResponse) GetBusCommunicationErrorCount() uint32 {
+	if x != nil && x.BusCommunicationErrorCount != nil {
+		return *x.BusCommunicationErrorCount
+	}
+	return 0
+}
+
+func (x *GetDiagnosticCountersResponse) GetBusExceptionErrorCount() uint32 {
+	if x != nil && x.BusExceptionErrorCount != nil {
+		return *x.BusExceptionErrorCount
+	}
+	return 0
+}
+
+func (x *GetDiagnosticCountersResponse) GetServerMessageCount() uint32 {
+	if x != nil && x.ServerMessageCount != nil {
+		return *x.ServerMessageCount
+	}
+	return 0
+}
+
+func (x *GetDiagnosticCountersResponse) GetServerNoResponseCount() uint32 {
+	if x != nil && x.ServerNoResponseCount != nil {
+		return *x.ServerNoResponseCount
+	}
+	return 0
+}
+
+type GetCommEventCounterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommEventCounterRequest) Reset() {
+	*x = GetCommEventCounterRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommEventCounterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommEventCounterRequest) ProtoMessage() {}
+
+func (x *GetCommEventCounterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommEventCounterRequest.ProtoReflect.Descriptor instead.
+func (*GetCommEventCounterRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{38}
+}
+
+type GetCommEventCounterResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the modbus server is still processing a previous command
+	Busy bool `protobuf:"varint,1,opt,name=busy,proto3" json:"busy,omitempty"`
+	// The number of messages successfully completed by the modbus server
+	EventCount    uint32 `protobuf:"varint,2,opt,name=event_count,json=eventCount,proto3" json:"event_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommEventCounterResponse) Reset() {
+	*x = GetCommEventCounterResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommEventCounterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommEventCounterResponse) ProtoMessage() {}
+
+func (x *GetCommEventCounterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommEventCounterResponse.ProtoReflect.Descriptor instead.
+func (*GetCommEventCounterResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetCommEventCounterResponse) GetBusy() bool {
+	if x != nil {
+		return x.Busy
+	}
+	return false
+}
+
+func (x *GetCommEventCounterResponse) GetEventCount() uint32 {
+	if x != nil {
+		return x.EventCount
+	}
+	return 0
+}
+
+type GetCommEventLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommEventLogRequest) Reset() {
+	*x = GetCommEventLogRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommEventLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommEventLogRequest) ProtoMessage() {}
+
+func (x *GetCommEventLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommEventLogRequest.ProtoReflect.Descriptor instead.
+func (*GetCommEventLogRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{40}
+}
+
+type GetCommEventLogResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the modbus server is still processing a previous command
+	Busy bool `protobuf:"varint,1,opt,name=busy,proto3" json:"busy,omitempty"`
+	// The number of messages successfully completed by the modbus server
+	EventCount uint32 `protobuf:"varint,2,opt,name=event_count,json=eventCount,proto3" json:"event_count,omitempty"`
+	// The number of messages processed by the modbus server
+	MessageCount uint32 `protobuf:"varint,3,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	// The event bytes in the log, the most recent first
+	Events        []uint32 `protobuf:"varint,4,rep,packed,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommEventLogResponse) Reset() {
+	*x = GetCommEventLogResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommEventLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommEventLogResponse) ProtoMessage() {}
+
+func (x *GetCommEventLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommEventLogResponse.ProtoReflect.Descriptor instead.
+func (*GetCommEventLogResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetCommEventLogResponse) GetBusy() bool {
+	if x != nil {
+		return x.Busy
+	}
+	return false
+}
+
+func (x *GetCommEventLogResponse) GetEventCount() uint32 {
+	if x != nil {
+		return x.EventCount
+	}
+	return 0
+}
+
+func (x *GetCommEventLogResponse) GetMessageCount() uint32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+func (x *GetCommEventLogResponse) GetEvents() []uint32 {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ReadFIFOQueueRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The address of the FIFO pointer register
+	Address       uint32 `protobuf:"varint,1,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFIFOQueueRequest) Reset() {
+	*x = ReadFIFOQueueRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFIFOQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFIFOQueueRequest) ProtoMessage() {}
+
+func (x *ReadFIFOQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFIFOQueueRequest.ProtoReflect.Descriptor instead.
+func (*ReadFIFOQueueRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ReadFIFOQueueRequest) GetAddress() uint32 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+type ReadFIFOQueueResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The values in the queue, the oldest first
+	Values        []uint32 `protobuf:"varint,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFIFOQueueResponse) Reset() {
+	*x = ReadFIFOQueueResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFIFOQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFIFOQueueResponse) ProtoMessage() {}
+
+func (x *ReadFIFOQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFIFOQueueResponse.ProtoReflect.Descriptor instead.
+func (*ReadFIFOQueueResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ReadFIFOQueueResponse) GetValues() []uint32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ReadFileRecordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ranges of records to read. The records read must fit in a single response, which allows 245 bytes, where each
+	// range takes 2 bytes plus 2 bytes per record.
+	References    []*FileRecordReference `protobuf:"bytes,1,rep,name=references,proto3" json:"references,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileRecordRequest) Reset() {
+	*x = ReadFileRecordRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileRecordRequest) ProtoMessage() {}
+
+func (x *ReadFileRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileRecordRequest.ProtoReflect.Descriptor instead.
+func (*ReadFileRecordRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ReadFileRecordRequest) GetReferences() []*FileRecordReference {
+	if x != nil {
+		return x.References
+	}
+	return nil
+}
+
+type ReadFileRecordResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The records read, in the order of the references in the request
+	Records       []*FileRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileRecordResponse) Reset() {
+	*x = ReadFileRecordResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileRecordResponse) ProtoMessage() {}
+
+func (x *ReadFileRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileRecordResponse.ProtoReflect.Descriptor instead.
+func (*ReadFileRecordResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ReadFileRecordResponse) GetRecords() []*FileRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type WriteFileRecordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ranges of records to write. The records written must fit in a single request, which allows 245 bytes, where
+	// each range takes 7 bytes plus 2 bytes per record.
+	Records       []*FileRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRecordRequest) Reset() {
+	*x = WriteFileRecordRequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRecordRequest) ProtoMessage() {}
+
+func (x *WriteFileRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRecordRequest.ProtoReflect.Descriptor instead.
+func (*WriteFileRecordRequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *WriteFileRecordRequest) GetRecords() []*FileRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type WriteFileRecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRecordResponse) Reset() {
+	*x = WriteFileRecordResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRecordResponse) ProtoMessage() {}
+
+func (x *WriteFileRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRecordResponse.ProtoReflect.Descriptor instead.
+func (*WriteFileRecordResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{47}
+}
+
+type SendRawPDURequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The function code, which must be allowed by the configuration
+	FunctionCode uint32 `protobuf:"varint,1,opt,name=function_code,json=functionCode,proto3" json:"function_code,omitempty"`
+	// The data of the request PDU following the function code
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// The unit ID of the device to send the request to. The configured slave ID is used if it is not set.
+	UnitId        *uint32 `protobuf:"varint,3,opt,name=unit_id,json=unitId,proto3,oneof" json:"unit_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendRawPDURequest) Reset() {
+	*x = SendRawPDURequest{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendRawPDURequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendRawPDURequest) ProtoMessage() {}
+
+func (x *SendRawPDURequest) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendRawPDURequest.ProtoReflect.Descriptor instead.
+func (*SendRawPDURequest) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *SendRawPDURequest) GetFunctionCode() uint32 {
+	if x != nil {
+		return x.FunctionCode
+	}
+	return 0
+}
+
+func (x *SendRawPDURequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SendRawPDURequest) GetUnitId() uint32 {
+	if x != nil && x.UnitId != nil {
+		return *x.UnitId
+	}
+	return 0
+}
+
+type SendRawPDUResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The function code of the response PDU, which has the high bit set if it is an exception
+	FunctionCode uint32 `protobuf:"varint,1,opt,name=function_code,json=functionCode,proto3" json:"function_code,omitempty"`
+	// The data of the response PDU following the function code
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// The exception code, if the modbus server returned an exception
+	ExceptionCode *uint32 `protobuf:"varint,3,opt,name=exception_code,json=exceptionCode,proto3,oneof" json:"exception_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendRawPDUResponse) Reset() {
+	*x = SendRawPDUResponse{}
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendRawPDUResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendRawPDUResponse) ProtoMessage() {}
+
+func (x *SendRawPDUResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_service_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendRawPDUResponse.ProtoReflect.Descriptor instead.
+func (*SendRawPDUResponse) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_service_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *SendRawPDUResponse) GetFunctionCode() uint32 {
+	if x != nil {
+		return x.FunctionCode
+	}
+	return 0
+}
+
+func (x *SendRawPDUResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SendRawPDUResponse) GetExceptionCode() uint32 {
+	if x != nil && x.ExceptionCode != nil {
+		return *x.ExceptionCode
+	}
+	return 0
+}
+
 var File_modbustohttp_v1alpha1_service_proto protoreflect.FileDescriptor
 
 const file_modbustohttp_v1alpha1_service_proto_rawDesc = "" +
 	"\n" +
-	"#modbustohttp/v1alpha1/service.proto\x12\x15modbustohttp.v1alpha1\x1a!modbustohttp/v1alpha1/types.proto\x1a\x1bbuf/validate/validate.proto\"\xec\x01\n" +
+	"#modbustohttp/v1alpha1/service.proto\x12\x15modbustohttp.v1alpha1\x1a!modbustohttp/v1alpha1/types.proto\x1a\x1bbuf/validate/validate.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xec\x01\n" +
 	"\x19ReadInputRegistersRequest\x12#\n" +
 	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\x12*\n" +
 	"\bquantity\x18\x02 \x01(\rB\t\xbaH\x06*\x04\x18} \x00H\x00R\bquantity\x88\x01\x01:q\xbaHn\x1al\n" +
@@ -1003,8 +2569,115 @@ const file_modbustohttp_v1alpha1_service_proto_rawDesc = "" +
 	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\"c\n" +
 	"\x1aReadRegisterAsBitsResponse\x12E\n" +
 	"\x04bits\x18\x01 \x03(\v2%.modbustohttp.v1alpha1.BooleanAddressB\n" +
-	"\xbaH\a\x92\x01\x04\b\x01\x10\x10R\x04bits2\x80\n" +
+	"\xbaH\a\x92\x01\x04\b\x01\x10\x10R\x04bits\"\xa1\x01\n" +
+	"\x13PrepareWriteRequest\x12;\n" +
+	"\x04coil\x18\x01 \x01(\v2%.modbustohttp.v1alpha1.BooleanAddressH\x00R\x04coil\x12=\n" +
+	"\bregister\x18\x02 \x01(\v2\x1f.modbustohttp.v1alpha1.RegisterH\x00R\bregisterB\x0e\n" +
+	"\x05write\x12\x05\xbaH\x02\b\x01\"\xf0\x01\n" +
+	"\x14PrepareWriteResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x122\n" +
+	"\x05table\x18\x03 \x01(\x0e2\x1c.modbustohttp.v1alpha1.TableR\x05table\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\rR\aaddress\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\rR\x05value\x12#\n" +
+	"\rcurrent_value\x18\x06 \x01(\rR\fcurrentValue\"4\n" +
+	"\x13ExecuteWriteRequest\x12\x1d\n" +
+	"\x05token\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x05token\"\x16\n" +
+	"\x14ExecuteWriteResponse\"\x80\x04\n" +
+	"!ReadWriteMultipleRegistersRequest\x12,\n" +
+	"\fread_address\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\vreadAddress\x12.\n" +
+	"\rread_quantity\x18\x02 \x01(\rB\t\xbaH\x06*\x04\x18}(\x01R\freadQuantity\x12.\n" +
+	"\rwrite_address\x18\x03 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\fwriteAddress\x12*\n" +
+	"\x06values\x18\x04 \x03(\rB\x12\xbaH\x0f\x92\x01\f\b\x01\x10y\"\x06*\x04\x18\xff\xff\x03R\x06values:\xa0\x02\xbaH\x9c\x02\x1a\x85\x01\n" +
+	"\x15read.not.out.of.range\x12;read_address + read_quantity must not be greater than 65536\x1a/this.read_address + this.read_quantity <= 65536\x1a\x91\x01\n" +
+	"\x16write.not.out.of.range\x12?write_address + length of values must not be greater than 65536\x1a6this.write_address + uint(this.values.size()) <= 65536\"o\n" +
+	"\"ReadWriteMultipleRegistersResponse\x12I\n" +
+	"\tregisters\x18\x01 \x03(\v2\x1f.modbustohttp.v1alpha1.RegisterB\n" +
+	"\xbaH\a\x92\x01\x04\b\x01\x10}R\tregisters\"\x89\x01\n" +
+	"\x18MaskWriteRegisterRequest\x12#\n" +
+	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\x12$\n" +
+	"\band_mask\x18\x02 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aandMask\x12\"\n" +
+	"\aor_mask\x18\x03 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\x06orMask\"\x1b\n" +
+	"\x19MaskWriteRegisterResponse\"\xd1\x02\n" +
+	"\x1aWriteBitsInRegisterRequest\x12#\n" +
+	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\x12E\n" +
+	"\x04bits\x18\x02 \x03(\v2%.modbustohttp.v1alpha1.BooleanAddressB\n" +
+	"\xbaH\a\x92\x01\x04\b\x01\x10\x10R\x04bits:\xc6\x01\xbaH\xc2\x01\x1ag\n" +
+	"\x10bits.in.register\x120the address of each bit must be between 0 and 15\x1a!this.bits.all(b, b.address <= 15)\x1aW\n" +
+	"\vbits.unique\x12\"each bit must only be written once\x1a$this.bits.map(b, b.address).unique()\"\x1d\n" +
+	"\x1bWriteBitsInRegisterResponse\"\xa5\x01\n" +
+	"\x1fReadDeviceIdentificationRequest\x12[\n" +
+	"\bcategory\x18\x01 \x01(\x0e23.modbustohttp.v1alpha1.DeviceIdentificationCategoryB\n" +
+	"\xbaH\a\x82\x01\x04\x10\x01 \x00R\bcategory\x12%\n" +
+	"\tobject_id\x18\x02 \x01(\rB\b\xbaH\x05*\x03\x18\xff\x01R\bobjectId\"\xc9\x02\n" +
+	" ReadDeviceIdentificationResponse\x12)\n" +
+	"\x10conformity_level\x18\x01 \x01(\rR\x0fconformityLevel\x12\x1f\n" +
+	"\vvendor_name\x18\x02 \x01(\tR\n" +
+	"vendorName\x12!\n" +
+	"\fproduct_code\x18\x03 \x01(\tR\vproductCode\x12\x1a\n" +
+	"\brevision\x18\x04 \x01(\tR\brevision\x12^\n" +
+	"\aobjects\x18\x05 \x03(\v2D.modbustohttp.v1alpha1.ReadDeviceIdentificationResponse.ObjectsEntryR\aobjects\x1a:\n" +
+	"\fObjectsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\rR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"8\n" +
+	"\x16ReturnQueryDataRequest\x12\x1e\n" +
+	"\x04data\x18\x01 \x01(\fB\n" +
+	"\xbaH\az\x05\x10\x01\x18\xfa\x01R\x04data\"-\n" +
+	"\x17ReturnQueryDataResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\" \n" +
+	"\x1eClearDiagnosticCountersRequest\"!\n" +
+	"\x1fClearDiagnosticCountersResponse\"\x1e\n" +
+	"\x1cGetDiagnosticCountersRequest\"\xd9\x03\n" +
+	"\x1dGetDiagnosticCountersResponse\x12/\n" +
+	"\x11bus_message_count\x18\x01 \x01(\rH\x00R\x0fbusMessageCount\x88\x01\x01\x12F\n" +
+	"\x1dbus_communication_error_count\x18\x02 \x01(\rH\x01R\x1abusCommunicationErrorCount\x88\x01\x01\x12>\n" +
+	"\x19bus_exception_error_count\x18\x03 \x01(\rH\x02R\x16busExceptionErrorCount\x88\x01\x01\x125\n" +
+	"\x14server_message_count\x18\x04 \x01(\rH\x03R\x12serverMessageCount\x88\x01\x01\x12<\n" +
+	"\x18server_no_response_count\x18\x05 \x01(\rH\x04R\x15serverNoResponseCount\x88\x01\x01B\x14\n" +
+	"\x12_bus_message_countB \n" +
+	"\x1e_bus_communication_error_countB\x1c\n" +
+	"\x1a_bus_exception_error_countB\x17\n" +
+	"\x15_server_message_countB\x1b\n" +
+	"\x19_server_no_response_count\"\x1c\n" +
+	"\x1aGetCommEventCounterRequest\"R\n" +
+	"\x1bGetCommEventCounterResponse\x12\x12\n" +
+	"\x04busy\x18\x01 \x01(\bR\x04busy\x12\x1f\n" +
+	"\vevent_count\x18\x02 \x01(\rR\n" +
+	"eventCount\"\x18\n" +
+	"\x16GetCommEventLogRequest\"\x8b\x01\n" +
+	"\x17GetCommEventLogResponse\x12\x12\n" +
+	"\x04busy\x18\x01 \x01(\bR\x04busy\x12\x1f\n" +
+	"\vevent_count\x18\x02 \x01(\rR\n" +
+	"eventCount\x12#\n" +
+	"\rmessage_count\x18\x03 \x01(\rR\fmessageCount\x12\x16\n" +
+	"\x06events\x18\x04 \x03(\rR\x06events\";\n" +
+	"\x14ReadFIFOQueueRequest\x12#\n" +
+	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\"9\n" +
+	"\x15ReadFIFOQueueResponse\x12 \n" +
+	"\x06values\x18\x01 \x03(\rB\b\xbaH\x05\x92\x01\x02\x10\x1fR\x06values\"o\n" +
+	"\x15ReadFileRecordRequest\x12V\n" +
+	"\n" +
+	"references\x18\x01 \x03(\v2*.modbustohttp.v1alpha1.FileRecordReferenceB\n" +
+	"\xbaH\a\x92\x01\x04\b\x01\x10#R\n" +
+	"references\"U\n" +
+	"\x16ReadFileRecordResponse\x12;\n" +
+	"\arecords\x18\x01 \x03(\v2!.modbustohttp.v1alpha1.FileRecordR\arecords\"a\n" +
+	"\x16WriteFileRecordRequest\x12G\n" +
+	"\arecords\x18\x01 \x03(\v2!.modbustohttp.v1alpha1.FileRecordB\n" +
+	"\xbaH\a\x92\x01\x04\b\x01\x10\x1bR\arecords\"\x19\n" +
+	"\x17WriteFileRecordResponse\"\x95\x01\n" +
+	"\x11SendRawPDURequest\x12.\n" +
+	"\rfunction_code\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\x7f(\x01R\ffunctionCode\x12\x1c\n" +
+	"\x04data\x18\x02 \x01(\fB\b\xbaH\x05z\x03\x18\xfc\x01R\x04data\x12&\n" +
+	"\aunit_id\x18\x03 \x01(\rB\b\xbaH\x05*\x03\x18\xff\x01H\x00R\x06unitId\x88\x01\x01B\n" +
 	"\n" +
+	"\b_unit_id\"\x8c\x01\n" +
+	"\x12SendRawPDUResponse\x12#\n" +
+	"\rfunction_code\x18\x01 \x01(\rR\ffunctionCode\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12*\n" +
+	"\x0eexception_code\x18\x03 \x01(\rH\x00R\rexceptionCode\x88\x01\x01B\x11\n" +
+	"\x0f_exception_code2\xcd\x18\n" +
 	"\rModbusService\x12\x84\x01\n" +
 	"\x14ReadHoldingRegisters\x122.modbustohttp.v1alpha1.ReadHoldingRegistersRequest\x1a3.modbustohttp.v1alpha1.ReadHoldingRegistersResponse\"\x03\x90\x02\x01\x12\x81\x01\n" +
 	"\x13WriteSingleRegister\x121.modbustohttp.v1alpha1.WriteSingleRegisterRequest\x1a2.modbustohttp.v1alpha1.WriteSingleRegisterResponse\"\x03\x90\x02\x02\x12c\n" +
@@ -1014,8 +2687,24 @@ const file_modbustohttp_v1alpha1_service_proto_rawDesc = "" +
 	"\x12WriteMultipleCoils\x120.modbustohttp.v1alpha1.WriteMultipleCoilsRequest\x1a1.modbustohttp.v1alpha1.WriteMultipleCoilsResponse\"\x03\x90\x02\x02\x12~\n" +
 	"\x12ReadInputRegisters\x120.modbustohttp.v1alpha1.ReadInputRegistersRequest\x1a1.modbustohttp.v1alpha1.ReadInputRegistersResponse\"\x03\x90\x02\x01\x12\x8a\x01\n" +
 	"\x16WriteMultipleRegisters\x124.modbustohttp.v1alpha1.WriteMultipleRegistersRequest\x1a5.modbustohttp.v1alpha1.WriteMultipleRegistersResponse\"\x03\x90\x02\x02\x12{\n" +
-	"\x12WriteBitInRegister\x120.modbustohttp.v1alpha1.WriteBitInRegisterRequest\x1a1.modbustohttp.v1alpha1.WriteBitInRegisterResponse\"\x00\x12~\n" +
-	"\x12ReadRegisterAsBits\x120.modbustohttp.v1alpha1.ReadRegisterAsBitsRequest\x1a1.modbustohttp.v1alpha1.ReadRegisterAsBitsResponse\"\x03\x90\x02\x01B\xca\x01\n" +
+	"\x12WriteBitInRegister\x120.modbustohttp.v1alpha1.WriteBitInRegisterRequest\x1a1.modbustohttp.v1alpha1.WriteBitInRegisterResponse\"\x00\x12{\n" +
+	"\x11MaskWriteRegister\x12/.modbustohttp.v1alpha1.MaskWriteRegisterRequest\x1a0.modbustohttp.v1alpha1.MaskWriteRegisterResponse\"\x03\x90\x02\x02\x12\x81\x01\n" +
+	"\x13WriteBitsInRegister\x121.modbustohttp.v1alpha1.WriteBitsInRegisterRequest\x1a2.modbustohttp.v1alpha1.WriteBitsInRegisterResponse\"\x03\x90\x02\x02\x12~\n" +
+	"\x12ReadRegisterAsBits\x120.modbustohttp.v1alpha1.ReadRegisterAsBitsRequest\x1a1.modbustohttp.v1alpha1.ReadRegisterAsBitsResponse\"\x03\x90\x02\x01\x12\x93\x01\n" +
+	"\x1aReadWriteMultipleRegisters\x128.modbustohttp.v1alpha1.ReadWriteMultipleRegistersRequest\x1a9.modbustohttp.v1alpha1.ReadWriteMultipleRegistersResponse\"\x00\x12i\n" +
+	"\fPrepareWrite\x12*.modbustohttp.v1alpha1.PrepareWriteRequest\x1a+.modbustohttp.v1alpha1.PrepareWriteResponse\"\x00\x12i\n" +
+	"\fExecuteWrite\x12*.modbustohttp.v1alpha1.ExecuteWriteRequest\x1a+.modbustohttp.v1alpha1.ExecuteWriteResponse\"\x00\x12\x90\x01\n" +
+	"\x18ReadDeviceIdentification\x126.modbustohttp.v1alpha1.ReadDeviceIdentificationRequest\x1a7.modbustohttp.v1alpha1.ReadDeviceIdentificationResponse\"\x03\x90\x02\x01\x12u\n" +
+	"\x0fReturnQueryData\x12-.modbustohttp.v1alpha1.ReturnQueryDataRequest\x1a..modbustohttp.v1alpha1.ReturnQueryDataResponse\"\x03\x90\x02\x01\x12\x8d\x01\n" +
+	"\x17ClearDiagnosticCounters\x125.modbustohttp.v1alpha1.ClearDiagnosticCountersRequest\x1a6.modbustohttp.v1alpha1.ClearDiagnosticCountersResponse\"\x03\x90\x02\x02\x12\x87\x01\n" +
+	"\x15GetDiagnosticCounters\x123.modbustohttp.v1alpha1.GetDiagnosticCountersRequest\x1a4.modbustohttp.v1alpha1.GetDiagnosticCountersResponse\"\x03\x90\x02\x01\x12\x81\x01\n" +
+	"\x13GetCommEventCounter\x121.modbustohttp.v1alpha1.GetCommEventCounterRequest\x1a2.modbustohttp.v1alpha1.GetCommEventCounterResponse\"\x03\x90\x02\x01\x12u\n" +
+	"\x0fGetCommEventLog\x12-.modbustohttp.v1alpha1.GetCommEventLogRequest\x1a..modbustohttp.v1alpha1.GetCommEventLogResponse\"\x03\x90\x02\x01\x12o\n" +
+	"\rReadFIFOQueue\x12+.modbustohttp.v1alpha1.ReadFIFOQueueRequest\x1a,.modbustohttp.v1alpha1.ReadFIFOQueueResponse\"\x03\x90\x02\x01\x12r\n" +
+	"\x0eReadFileRecord\x12,.modbustohttp.v1alpha1.ReadFileRecordRequest\x1a-.modbustohttp.v1alpha1.ReadFileRecordResponse\"\x03\x90\x02\x01\x12u\n" +
+	"\x0fWriteFileRecord\x12-.modbustohttp.v1alpha1.WriteFileRecordRequest\x1a..modbustohttp.v1alpha1.WriteFileRecordResponse\"\x03\x90\x02\x02\x12c\n" +
+	"\n" +
+	"SendRawPDU\x12(.modbustohttp.v1alpha1.SendRawPDURequest\x1a).modbustohttp.v1alpha1.SendRawPDUResponse\"\x00B\xca\x01\n" +
 	"\x19com.modbustohttp.v1alpha1B\fServiceProtoP\x01Z*modbustohttp/service/modbustohttp/v1alpha1\xa2\x02\x03MXX\xaa\x02\x15Modbustohttp.V1alpha1\xca\x02\x15Modbustohttp\\V1alpha1\xe2\x02!Modbustohttp\\V1alpha1\\GPBMetadata\xea\x02\x16Modbustohttp::V1alpha1b\x06proto3"
 
 var (
@@ -1030,64 +2719,141 @@ func file_modbustohttp_v1alpha1_service_proto_rawDescGZIP() []byte {
 	return file_modbustohttp_v1alpha1_service_proto_rawDescData
 }
 
-var file_modbustohttp_v1alpha1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_modbustohttp_v1alpha1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
 var file_modbustohttp_v1alpha1_service_proto_goTypes = []any{
-	(*ReadInputRegistersRequest)(nil),      // 0: modbustohttp.v1alpha1.ReadInputRegistersRequest
-	(*ReadInputRegistersResponse)(nil),     // 1: modbustohttp.v1alpha1.ReadInputRegistersResponse
-	(*ReadHoldingRegistersRequest)(nil),    // 2: modbustohttp.v1alpha1.ReadHoldingRegistersRequest
-	(*ReadHoldingRegistersResponse)(nil),   // 3: modbustohttp.v1alpha1.ReadHoldingRegistersResponse
-	(*WriteSingleRegisterRequest)(nil),     // 4: modbustohttp.v1alpha1.WriteSingleRegisterRequest
-	(*WriteSingleRegisterResponse)(nil),    // 5: modbustohttp.v1alpha1.WriteSingleRegisterResponse
-	(*ReadCoilsRequest)(nil),               // 6: modbustohttp.v1alpha1.ReadCoilsRequest
-	(*ReadCoilsResponse)(nil),              // 7: modbustohttp.v1alpha1.ReadCoilsResponse
-	(*ReadDiscreteInputsRequest)(nil),      // 8: modbustohttp.v1alpha1.ReadDiscreteInputsRequest
-	(*ReadDiscreteInputsResponse)(nil),     // 9: modbustohttp.v1alpha1.ReadDiscreteInputsResponse
-	(*WriteSingleCoilRequest)(nil),         // 10: modbustohttp.v1alpha1.WriteSingleCoilRequest
-	(*WriteSingleCoilResponse)(nil),        // 11: modbustohttp.v1alpha1.WriteSingleCoilResponse
-	(*WriteMultipleCoilsRequest)(nil),      // 12: modbustohttp.v1alpha1.WriteMultipleCoilsRequest
-	(*WriteMultipleCoilsResponse)(nil),     // 13: modbustohttp.v1alpha1.WriteMultipleCoilsResponse
-	(*WriteMultipleRegistersRequest)(nil),  // 14: modbustohttp.v1alpha1.WriteMultipleRegistersRequest
-	(*WriteMultipleRegistersResponse)(nil), // 15: modbustohttp.v1alpha1.WriteMultipleRegistersResponse
-	(*WriteBitInRegisterRequest)(nil),      // 16: modbustohttp.v1alpha1.WriteBitInRegisterRequest
-	(*WriteBitInRegisterResponse)(nil),     // 17: modbustohttp.v1alpha1.WriteBitInRegisterResponse
-	(*ReadRegisterAsBitsRequest)(nil),      // 18: modbustohttp.v1alpha1.ReadRegisterAsBitsRequest
-	(*ReadRegisterAsBitsResponse)(nil),     // 19: modbustohttp.v1alpha1.ReadRegisterAsBitsResponse
-	(*Register)(nil),                       // 20: modbustohttp.v1alpha1.Register
-	(*BooleanAddress)(nil),                 // 21: modbustohttp.v1alpha1.BooleanAddress
+	(*ReadInputRegistersRequest)(nil),          // 0: modbustohttp.v1alpha1.ReadInputRegistersRequest
+	(*ReadInputRegistersResponse)(nil),         // 1: modbustohttp.v1alpha1.ReadInputRegistersResponse
+	(*ReadHoldingRegistersRequest)(nil),        // 2: modbustohttp.v1alpha1.ReadHoldingRegistersRequest
+	(*ReadHoldingRegistersResponse)(nil),       // 3: modbustohttp.v1alpha1.ReadHoldingRegistersResponse
+	(*WriteSingleRegisterRequest)(nil),         // 4: modbustohttp.v1alpha1.WriteSingleRegisterRequest
+	(*WriteSingleRegisterResponse)(nil),        // 5: modbustohttp.v1alpha1.WriteSingleRegisterResponse
+	(*ReadCoilsRequest)(nil),                   // 6: modbustohttp.v1alpha1.ReadCoilsRequest
+	(*ReadCoilsResponse)(nil),                  // 7: modbustohttp.v1alpha1.ReadCoilsResponse
+	(*ReadDiscreteInputsRequest)(nil),          // 8: modbustohttp.v1alpha1.ReadDiscreteInputsRequest
+	(*ReadDiscreteInputsResponse)(nil),         // 9: modbustohttp.v1alpha1.ReadDiscreteInputsResponse
+	(*WriteSingleCoilRequest)(nil),             // 10: modbustohttp.v1alpha1.WriteSingleCoilRequest
+	(*WriteSingleCoilResponse)(nil),            // 11: modbustohttp.v1alpha1.WriteSingleCoilResponse
+	(*WriteMultipleCoilsRequest)(nil),          // 12: modbustohttp.v1alpha1.WriteMultipleCoilsRequest
+	(*WriteMultipleCoilsResponse)(nil),         // 13: modbustohttp.v1alpha1.WriteMultipleCoilsResponse
+	(*WriteMultipleRegistersRequest)(nil),      // 14: modbustohttp.v1alpha1.WriteMultipleRegistersRequest
+	(*WriteMultipleRegistersResponse)(nil),     // 15: modbustohttp.v1alpha1.WriteMultipleRegistersResponse
+	(*WriteBitInRegisterRequest)(nil),          // 16: modbustohttp.v1alpha1.WriteBitInRegisterRequest
+	(*WriteBitInRegisterResponse)(nil),         // 17: modbustohttp.v1alpha1.WriteBitInRegisterResponse
+	(*ReadRegisterAsBitsRequest)(nil),          // 18: modbustohttp.v1alpha1.ReadRegisterAsBitsRequest
+	(*ReadRegisterAsBitsResponse)(nil),         // 19: modbustohttp.v1alpha1.ReadRegisterAsBitsResponse
+	(*PrepareWriteRequest)(nil),                // 20: modbustohttp.v1alpha1.PrepareWriteRequest
+	(*PrepareWriteResponse)(nil),               // 21: modbustohttp.v1alpha1.PrepareWriteResponse
+	(*ExecuteWriteRequest)(nil),                // 22: modbustohttp.v1alpha1.ExecuteWriteRequest
+	(*ExecuteWriteResponse)(nil),               // 23: modbustohttp.v1alpha1.ExecuteWriteResponse
+	(*ReadWriteMultipleRegistersRequest)(nil),  // 24: modbustohttp.v1alpha1.ReadWriteMultipleRegistersRequest
+	(*ReadWriteMultipleRegistersResponse)(nil), // 25: modbustohttp.v1alpha1.ReadWriteMultipleRegistersResponse
+	(*MaskWriteRegisterRequest)(nil),           // 26: modbustohttp.v1alpha1.MaskWriteRegisterRequest
+	(*MaskWriteRegisterResponse)(nil),          // 27: modbustohttp.v1alpha1.MaskWriteRegisterResponse
+	(*WriteBitsInRegisterRequest)(nil),         // 28: modbustohttp.v1alpha1.WriteBitsInRegisterRequest
+	(*WriteBitsInRegisterResponse)(nil),        // 29: modbustohttp.v1alpha1.WriteBitsInRegisterResponse
+	(*ReadDeviceIdentificationRequest)(nil),    // 30: modbustohttp.v1alpha1.ReadDeviceIdentificationRequest
+	(*ReadDeviceIdentificationResponse)(nil),   // 31: modbustohttp.v1alpha1.ReadDeviceIdentificationResponse
+	(*ReturnQueryDataRequest)(nil),             // 32: modbustohttp.v1alpha1.ReturnQueryDataRequest
+	(*ReturnQueryDataResponse)(nil),            // 33: modbustohttp.v1alpha1.ReturnQueryDataResponse
+	(*ClearDiagnosticCountersRequest)(nil),     // 34: modbustohttp.v1alpha1.ClearDiagnosticCountersRequest
+	(*ClearDiagnosticCountersResponse)(nil),    // 35: modbustohttp.v1alpha1.ClearDiagnosticCountersResponse
+	(*GetDiagnosticCountersRequest)(nil),       // 36: modbustohttp.v1alpha1.GetDiagnosticCountersRequest
+	(*GetDiagnosticCountersResponse)(nil),      // 37: modbustohttp.v1alpha1.GetDiagnosticCountersResponse
+	(*GetCommEventCounterRequest)(nil),         // 38: modbustohttp.v1alpha1.GetCommEventCounterRequest
+	(*GetCommEventCounterResponse)(nil),        // 39: modbustohttp.v1alpha1.GetCommEventCounterResponse
+	(*GetCommEventLogRequest)(nil),             // 40: modbustohttp.v1alpha1.GetCommEventLogRequest
+	(*GetCommEventLogResponse)(nil),            // 41: modbustohttp.v1alpha1.GetCommEventLogResponse
+	(*ReadFIFOQueueRequest)(nil),               // 42: modbustohttp.v1alpha1.ReadFIFOQueueRequest
+	(*ReadFIFOQueueResponse)(nil),              // 43: modbustohttp.v1alpha1.ReadFIFOQueueResponse
+	(*ReadFileRecordRequest)(nil),              // 44: modbustohttp.v1alpha1.ReadFileRecordRequest
+	(*ReadFileRecordResponse)(nil),             // 45: modbustohttp.v1alpha1.ReadFileRecordResponse
+	(*WriteFileRecordRequest)(nil),             // 46: modbustohttp.v1alpha1.WriteFileRecordRequest
+	(*WriteFileRecordResponse)(nil),            // 47: modbustohttp.v1alpha1.WriteFileRecordResponse
+	(*SendRawPDURequest)(nil),                  // 48: modbustohttp.v1alpha1.SendRawPDURequest
+	(*SendRawPDUResponse)(nil),                 // 49: modbustohttp.v1alpha1.SendRawPDUResponse
+	nil,                                        // 50: modbustohttp.v1alpha1.ReadDeviceIdentificationResponse.ObjectsEntry
+	(*Register)(nil),                           // 51: modbustohttp.v1alpha1.Register
+	(*BooleanAddress)(nil),                     // 52: modbustohttp.v1alpha1.BooleanAddress
+	(*timestamppb.Timestamp)(nil),              // 53: google.protobuf.Timestamp
+	(Table)(0),                                 // 54: modbustohttp.v1alpha1.Table
+	(DeviceIdentificationCategory)(0),          // 55: modbustohttp.v1alpha1.DeviceIdentificationCategory
+	(*FileRecordReference)(nil),                // 56: modbustohttp.v1alpha1.FileRecordReference
+	(*FileRecord)(nil),                         // 57: modbustohttp.v1alpha1.FileRecord
 }
 var file_modbustohttp_v1alpha1_service_proto_depIdxs = []int32{
-	20, // 0: modbustohttp.v1alpha1.ReadInputRegistersResponse.registers:type_name -> modbustohttp.v1alpha1.Register
-	20, // 1: modbustohttp.v1alpha1.ReadHoldingRegistersResponse.registers:type_name -> modbustohttp.v1alpha1.Register
-	20, // 2: modbustohttp.v1alpha1.WriteSingleRegisterRequest.register:type_name -> modbustohttp.v1alpha1.Register
-	21, // 3: modbustohttp.v1alpha1.ReadCoilsResponse.coils:type_name -> modbustohttp.v1alpha1.BooleanAddress
-	21, // 4: modbustohttp.v1alpha1.ReadDiscreteInputsResponse.inputs:type_name -> modbustohttp.v1alpha1.BooleanAddress
-	21, // 5: modbustohttp.v1alpha1.WriteSingleCoilRequest.coil:type_name -> modbustohttp.v1alpha1.BooleanAddress
-	21, // 6: modbustohttp.v1alpha1.ReadRegisterAsBitsResponse.bits:type_name -> modbustohttp.v1alpha1.BooleanAddress
-	2,  // 7: modbustohttp.v1alpha1.ModbusService.ReadHoldingRegisters:input_type -> modbustohttp.v1alpha1.ReadHoldingRegistersRequest
-	4,  // 8: modbustohttp.v1alpha1.ModbusService.WriteSingleRegister:input_type -> modbustohttp.v1alpha1.WriteSingleRegisterRequest
-	6,  // 9: modbustohttp.v1alpha1.ModbusService.ReadCoils:input_type -> modbustohttp.v1alpha1.ReadCoilsRequest
-	8,  // 10: modbustohttp.v1alpha1.ModbusService.ReadDiscreteInputs:input_type -> modbustohttp.v1alpha1.ReadDiscreteInputsRequest
-	10, // 11: modbustohttp.v1alpha1.ModbusService.WriteSingleCoil:input_type -> modbustohttp.v1alpha1.WriteSingleCoilRequest
-	12, // 12: modbustohttp.v1alpha1.ModbusService.WriteMultipleCoils:input_type -> modbustohttp.v1alpha1.WriteMultipleCoilsRequest
-	0,  // 13: modbustohttp.v1alpha1.ModbusService.ReadInputRegisters:input_type -> modbustohttp.v1alpha1.ReadInputRegistersRequest
-	14, // 14: modbustohttp.v1alpha1.ModbusService.WriteMultipleRegisters:input_type -> modbustohttp.v1alpha1.WriteMultipleRegistersRequest
-	16, // 15: modbustohttp.v1alpha1.ModbusService.WriteBitInRegister:input_type -> modbustohttp.v1alpha1.WriteBitInRegisterRequest
-	18, // 16: modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits:input_type -> modbustohttp.v1alpha1.ReadRegisterAsBitsRequest
-	3,  // 17: modbustohttp.v1alpha1.ModbusService.ReadHoldingRegisters:output_type -> modbustohttp.v1alpha1.ReadHoldingRegistersResponse
-	5,  // 18: modbustohttp.v1alpha1.ModbusService.WriteSingleRegister:output_type -> modbustohttp.v1alpha1.WriteSingleRegisterResponse
-	7,  // 19: modbustohttp.v1alpha1.ModbusService.ReadCoils:output_type -> modbustohttp.v1alpha1.ReadCoilsResponse
-	9,  // 20: modbustohttp.v1alpha1.ModbusService.ReadDiscreteInputs:output_type -> modbustohttp.v1alpha1.ReadDiscreteInputsResponse
-	11, // 21: modbustohttp.v1alpha1.ModbusService.WriteSingleCoil:output_type -> modbustohttp.v1alpha1.WriteSingleCoilResponse
-	13, // 22: modbustohttp.v1alpha1.ModbusService.WriteMultipleCoils:output_type -> modbustohttp.v1alpha1.WriteMultipleCoilsResponse
-	1,  // 23: modbustohttp.v1alpha1.ModbusService.ReadInputRegisters:output_type -> modbustohttp.v1alpha1.ReadInputRegistersResponse
-	15, // 24: modbustohttp.v1alpha1.ModbusService.WriteMultipleRegisters:output_type -> modbustohttp.v1alpha1.WriteMultipleRegistersResponse
-	17, // 25: modbustohttp.v1alpha1.ModbusService.WriteBitInRegister:output_type -> modbustohttp.v1alpha1.WriteBitInRegisterResponse
-	19, // 26: modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits:output_type -> modbustohttp.v1alpha1.ReadRegisterAsBitsResponse
-	17, // [17:27] is the sub-list for method output_type
-	7,  // [7:17] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	51, // 0: modbustohttp.v1alpha1.ReadInputRegistersResponse.registers:type_name -> modbustohttp.v1alpha1.Register
+	51, // 1: modbustohttp.v1alpha1.ReadHoldingRegistersResponse.registers:type_name -> modbustohttp.v1alpha1.Register
+	51, // 2: modbustohttp.v1alpha1.WriteSingleRegisterRequest.register:type_name -> modbustohttp.v1alpha1.Register
+	52, // 3: modbustohttp.v1alpha1.ReadCoilsResponse.coils:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	52, // 4: modbustohttp.v1alpha1.ReadDiscreteInputsResponse.inputs:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	52, // 5: modbustohttp.v1alpha1.WriteSingleCoilRequest.coil:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	52, // 6: modbustohttp.v1alpha1.ReadRegisterAsBitsResponse.bits:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	52, // 7: modbustohttp.v1alpha1.PrepareWriteRequest.coil:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	51, // 8: modbustohttp.v1alpha1.PrepareWriteRequest.register:type_name -> modbustohttp.v1alpha1.Register
+	53, // 9: modbustohttp.v1alpha1.PrepareWriteResponse.expires_at:type_name -> google.protobuf.Timestamp
+	54, // 10: modbustohttp.v1alpha1.PrepareWriteResponse.table:type_name -> modbustohttp.v1alpha1.Table
+	51, // 11: modbustohttp.v1alpha1.ReadWriteMultipleRegistersResponse.registers:type_name -> modbustohttp.v1alpha1.Register
+	52, // 12: modbustohttp.v1alpha1.WriteBitsInRegisterRequest.bits:type_name -> modbustohttp.v1alpha1.BooleanAddress
+	55, // 13: modbustohttp.v1alpha1.ReadDeviceIdentificationRequest.category:type_name -> modbustohttp.v1alpha1.DeviceIdentificationCategory
+	50, // 14: modbustohttp.v1alpha1.ReadDeviceIdentificationResponse.objects:type_name -> modbustohttp.v1alpha1.ReadDeviceIdentificationResponse.ObjectsEntry
+	56, // 15: modbustohttp.v1alpha1.ReadFileRecordRequest.references:type_name -> modbustohttp.v1alpha1.FileRecordReference
+	57, // 16: modbustohttp.v1alpha1.ReadFileRecordResponse.records:type_name -> modbustohttp.v1alpha1.FileRecord
+	57, // 17: modbustohttp.v1alpha1.WriteFileRecordRequest.records:type_name -> modbustohttp.v1alpha1.FileRecord
+	2,  // 18: modbustohttp.v1alpha1.ModbusService.ReadHoldingRegisters:input_type -> modbustohttp.v1alpha1.ReadHoldingRegistersRequest
+	4,  // 19: modbustohttp.v1alpha1.ModbusService.WriteSingleRegister:input_type -> modbustohttp.v1alpha1.WriteSingleRegisterRequest
+	6,  // 20: modbustohttp.v1alpha1.ModbusService.ReadCoils:input_type -> modbustohttp.v1alpha1.ReadCoilsRequest
+	8,  // 21: modbustohttp.v1alpha1.ModbusService.ReadDiscreteInputs:input_type -> modbustohttp.v1alpha1.ReadDiscreteInputsRequest
+	10, // 22: modbustohttp.v1alpha1.ModbusService.WriteSingleCoil:input_type -> modbustohttp.v1alpha1.WriteSingleCoilRequest
+	12, // 23: modbustohttp.v1alpha1.ModbusService.WriteMultipleCoils:input_type -> modbustohttp.v1alpha1.WriteMultipleCoilsRequest
+	0,  // 24: modbustohttp.v1alpha1.ModbusService.ReadInputRegisters:input_type -> modbustohttp.v1alpha1.ReadInputRegistersRequest
+	14, // 25: modbustohttp.v1alpha1.ModbusService.WriteMultipleRegisters:input_type -> modbustohttp.v1alpha1.WriteMultipleRegistersRequest
+	16, // 26: modbustohttp.v1alpha1.ModbusService.WriteBitInRegister:input_type -> modbustohttp.v1alpha1.WriteBitInRegisterRequest
+	26, // 27: modbustohttp.v1alpha1.ModbusService.MaskWriteRegister:input_type -> modbustohttp.v1alpha1.MaskWriteRegisterRequest
+	28, // 28: modbustohttp.v1alpha1.ModbusService.WriteBitsInRegister:input_type -> modbustohttp.v1alpha1.WriteBitsInRegisterRequest
+	18, // 29: modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits:input_type -> modbustohttp.v1alpha1.ReadRegisterAsBitsRequest
+	24, // 30: modbustohttp.v1alpha1.ModbusService.ReadWriteMultipleRegisters:input_type -> modbustohttp.v1alpha1.ReadWriteMultipleRegistersRequest
+	20, // 31: modbustohttp.v1alpha1.ModbusService.PrepareWrite:input_type -> modbustohttp.v1alpha1.PrepareWriteRequest
+	22, // 32: modbustohttp.v1alpha1.ModbusService.ExecuteWrite:input_type -> modbustohttp.v1alpha1.ExecuteWriteRequest
+	30, // 33: modbustohttp.v1alpha1.ModbusService.ReadDeviceIdentification:input_type -> modbustohttp.v1alpha1.ReadDeviceIdentificationRequest
+	32, // 34: modbustohttp.v1alpha1.ModbusService.ReturnQueryData:input_type -> modbustohttp.v1alpha1.ReturnQueryDataRequest
+	34, // 35: modbustohttp.v1alpha1.ModbusService.ClearDiagnosticCounters:input_type -> modbustohttp.v1alpha1.ClearDiagnosticCountersRequest
+	36, // 36: modbustohttp.v1alpha1.ModbusService.GetDiagnosticCounters:input_type -> modbustohttp.v1alpha1.GetDiagnosticCountersRequest
+	38, // 37: modbustohttp.v1alpha1.ModbusService.GetCommEventCounter:input_type -> modbustohttp.v1alpha1.GetCommEventCounterRequest
+	40, // 38: modbustohttp.v1alpha1.ModbusService.GetCommEventLog:input_type -> modbustohttp.v1alpha1.GetCommEventLogRequest
+	42, // 39: modbustohttp.v1alpha1.ModbusService.ReadFIFOQueue:input_type -> modbustohttp.v1alpha1.ReadFIFOQueueRequest
+	44, // 40: modbustohttp.v1alpha1.ModbusService.ReadFileRecord:input_type -> modbustohttp.v1alpha1.ReadFileRecordRequest
+	46, // 41: modbustohttp.v1alpha1.ModbusService.WriteFileRecord:input_type -> modbustohttp.v1alpha1.WriteFileRecordRequest
+	48, // 42: modbustohttp.v1alpha1.ModbusService.SendRawPDU:input_type -> modbustohttp.v1alpha1.SendRawPDURequest
+	3,  // 43: modbustohttp.v1alpha1.ModbusService.ReadHoldingRegisters:output_type -> modbustohttp.v1alpha1.ReadHoldingRegistersResponse
+	5,  // 44: modbustohttp.v1alpha1.ModbusService.WriteSingleRegister:output_type -> modbustohttp.v1alpha1.WriteSingleRegisterResponse
+	7,  // 45: modbustohttp.v1alpha1.ModbusService.ReadCoils:output_type -> modbustohttp.v1alpha1.ReadCoilsResponse
+	9,  // 46: modbustohttp.v1alpha1.ModbusService.ReadDiscreteInputs:output_type -> modbustohttp.v1alpha1.ReadDiscreteInputsResponse
+	11, // 47: modbustohttp.v1alpha1.ModbusService.WriteSingleCoil:output_type -> modbustohttp.v1alpha1.WriteSingleCoilResponse
+	13, // 48: modbustohttp.v1alpha1.ModbusService.WriteMultipleCoils:output_type -> modbustohttp.v1alpha1.WriteMultipleCoilsResponse
+	1,  // 49: modbustohttp.v1alpha1.ModbusService.ReadInputRegisters:output_type -> modbustohttp.v1alpha1.ReadInputRegistersResponse
+	15, // 50: modbustohttp.v1alpha1.ModbusService.WriteMultipleRegisters:output_type -> modbustohttp.v1alpha1.WriteMultipleRegistersResponse
+	17, // 51: modbustohttp.v1alpha1.ModbusService.WriteBitInRegister:output_type -> modbustohttp.v1alpha1.WriteBitInRegisterResponse
+	27, // 52: modbustohttp.v1alpha1.ModbusService.MaskWriteRegister:output_type -> modbustohttp.v1alpha1.MaskWriteRegisterResponse
+	29, // 53: modbustohttp.v1alpha1.ModbusService.WriteBitsInRegister:output_type -> modbustohttp.v1alpha1.WriteBitsInRegisterResponse
+	19, // 54: modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits:output_type -> modbustohttp.v1alpha1.ReadRegisterAsBitsResponse
+	25, // 55: modbustohttp.v1alpha1.ModbusService.ReadWriteMultipleRegisters:output_type -> modbustohttp.v1alpha1.ReadWriteMultipleRegistersResponse
+	21, // 56: modbustohttp.v1alpha1.ModbusService.PrepareWrite:output_type -> modbustohttp.v1alpha1.PrepareWriteResponse
+	23, // 57: modbustohttp.v1alpha1.ModbusService.ExecuteWrite:output_type -> modbustohttp.v1alpha1.ExecuteWriteResponse
+	31, // 58: modbustohttp.v1alpha1.ModbusService.ReadDeviceIdentification:output_type -> modbustohttp.v1alpha1.ReadDeviceIdentificationResponse
+	33, // 59: modbustohttp.v1alpha1.ModbusService.ReturnQueryData:output_type -> modbustohttp.v1alpha1.ReturnQueryDataResponse
+	35, // 60: modbustohttp.v1alpha1.ModbusService.ClearDiagnosticCounters:output_type -> modbustohttp.v1alpha1.ClearDiagnosticCountersResponse
+	37, // 61: modbustohttp.v1alpha1.ModbusService.GetDiagnosticCounters:output_type -> modbustohttp.v1alpha1.GetDiagnosticCountersResponse
+	39, // 62: modbustohttp.v1alpha1.ModbusService.GetCommEventCounter:output_type -> modbustohttp.v1alpha1.GetCommEventCounterResponse
+	41, // 63: modbustohttp.v1alpha1.ModbusService.GetCommEventLog:output_type -> modbustohttp.v1alpha1.GetCommEventLogResponse
+	43, // 64: modbustohttp.v1alpha1.ModbusService.ReadFIFOQueue:output_type -> modbustohttp.v1alpha1.ReadFIFOQueueResponse
+	45, // 65: modbustohttp.v1alpha1.ModbusService.ReadFileRecord:output_type -> modbustohttp.v1alpha1.ReadFileRecordResponse
+	47, // 66: modbustohttp.v1alpha1.ModbusService.WriteFileRecord:output_type -> modbustohttp.v1alpha1.WriteFileRecordResponse
+	49, // 67: modbustohttp.v1alpha1.ModbusService.SendRawPDU:output_type -> modbustohttp.v1alpha1.SendRawPDUResponse
+	43, // [43:68] is the sub-list for method output_type
+	18, // [18:43] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_modbustohttp_v1alpha1_service_proto_init() }
@@ -1098,13 +2864,20 @@ func file_modbustohttp_v1alpha1_service_proto_init() {
 	file_modbustohttp_v1alpha1_types_proto_init()
 	file_modbustohttp_v1alpha1_service_proto_msgTypes[0].OneofWrappers = []any{}
 	file_modbustohttp_v1alpha1_service_proto_msgTypes[2].OneofWrappers = []any{}
+	file_modbustohttp_v1alpha1_service_proto_msgTypes[20].OneofWrappers = []any{
+		(*PrepareWriteRequest_Coil)(nil),
+		(*PrepareWriteRequest_Register)(nil),
+	}
+	file_modbustohttp_v1alpha1_service_proto_msgTypes[37].OneofWrappers = []any{}
+	file_modbustohttp_v1alpha1_service_proto_msgTypes[48].OneofWrappers = []any{}
+	file_modbustohttp_v1alpha1_service_proto_msgTypes[49].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_modbustohttp_v1alpha1_service_proto_rawDesc), len(file_modbustohttp_v1alpha1_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   20,
+			NumMessages:   51,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,6 +22,126 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// A modbus data table
+type Table int32
+
+const (
+	Table_TABLE_UNSPECIFIED       Table = 0
+	Table_TABLE_COILS             Table = 1
+	Table_TABLE_DISCRETE_INPUTS   Table = 2
+	Table_TABLE_INPUT_REGISTERS   Table = 3
+	Table_TABLE_HOLDING_REGISTERS Table = 4
+	// The records of the files, which are written using WriteFileRecord
+	Table_TABLE_FILE_RECORDS Table = 5
+)
+
+// Enum value maps for Table.
+var (
+	Table_name = map[int32]string{
+		0: "TABLE_UNSPECIFIED",
+		1: "TABLE_COILS",
+		2: "TABLE_DISCRETE_INPUTS",
+		3: "TABLE_INPUT_REGISTERS",
+		4: "TABLE_HOLDING_REGISTERS",
+		5: "TABLE_FILE_RECORDS",
+	}
+	Table_value = map[string]int32{
+		"TABLE_UNSPECIFIED":       0,
+		"TABLE_COILS":             1,
+		"TABLE_DISCRETE_INPUTS":   2,
+		"TABLE_INPUT_REGISTERS":   3,
+		"TABLE_HOLDING_REGISTERS": 4,
+		"TABLE_FILE_RECORDS":      5,
+	}
+)
+
+func (x Table) Enum() *Table {
+	p := new(Table)
+	*p = x
+	return p
+}
+
+func (x Table) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Table) Descriptor() protoreflect.EnumDescriptor {
+	return file_modbustohttp_v1alpha1_types_proto_enumTypes[0].Descriptor()
+}
+
+func (Table) Type() protoreflect.EnumType {
+	return &file_modbustohttp_v1alpha1_types_proto_enumTypes[0]
+}
+
+func (x Table) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Table.Descriptor instead.
+func (Table) EnumDescriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_types_proto_rawDescGZIP(), []int{0}
+}
+
+// The category of device identification objects to read, which is the Read Device ID code of function 43/14
+type DeviceIdentificationCategory int32
+
+const (
+	DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_UNSPECIFIED DeviceIdentificationCategory = 0
+	// The basic objects: vendor name, product code and revision (0x00-0x02)
+	DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_BASIC DeviceIdentificationCategory = 1
+	// The basic and regular objects (0x00-0x7F)
+	DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_REGULAR DeviceIdentificationCategory = 2
+	// The basic, regular and extended objects (0x00-0xFF)
+	DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_EXTENDED DeviceIdentificationCategory = 3
+	// A single object, given by its ID
+	DeviceIdentificationCategory_DEVICE_IDENTIFICATION_CATEGORY_INDIVIDUAL DeviceIdentificationCategory = 4
+)
+
+// Enum value maps for DeviceIdentificationCategory.
+var (
+	DeviceIdentificationCategory_name = map[int32]string{
+		0: "DEVICE_IDENTIFICATION_CATEGORY_UNSPECIFIED",
+		1: "DEVICE_IDENTIFICATION_CATEGORY_BASIC",
+		2: "DEVICE_IDENTIFICATION_CATEGORY_REGULAR",
+		3: "DEVICE_IDENTIFICATION_CATEGORY_EXTENDED",
+		4: "DEVICE_IDENTIFICATION_CATEGORY_INDIVIDUAL",
+	}
+	DeviceIdentificationCategory_value = map[string]int32{
+		"DEVICE_IDENTIFICATION_CATEGORY_UNSPECIFIED": 0,
+		"DEVICE_IDENTIFICATION_CATEGORY_BASIC":       1,
+		"DEVICE_IDENTIFICATION_CATEGORY_REGULAR":     2,
+		"DEVICE_IDENTIFICATION_CATEGORY_EXTENDED":    3,
+		"DEVICE_IDENTIFICATION_CATEGORY_INDIVIDUAL":  4,
+	}
+)
+
+func (x DeviceIdentificationCategory) Enum() *DeviceIdentificationCategory {
+	p := new(DeviceIdentificationCategory)
+	*p = x
+	return p
+}
+
+func (x DeviceIdentificationCategory) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeviceIdentificationCategory) Descriptor() protoreflect.EnumDescriptor {
+	return file_modbustohttp_v1alpha1_types_proto_enumTypes[1].Descriptor()
+}
+
+func (DeviceIdentificationCategory) Type() protoreflect.EnumType {
+	return &file_modbustohttp_v1alpha1_types_proto_enumTypes[1]
+}
+
+func (x DeviceIdentificationCategory) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeviceIdentificationCategory.Descriptor instead.
+func (DeviceIdentificationCategory) EnumDescriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_types_proto_rawDescGZIP(), []int{1}
+}
+
 type BooleanAddress struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The address of the coil or discrete input
@@ -131,6 +251,134 @@ func (x *Register) GetValue() uint32 {
 	return 0
 }
 
+// A range of records in a file
+type FileRecordReference struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The file number
+	FileNumber uint32 `protobuf:"varint,1,opt,name=file_number,json=fileNumber,proto3" json:"file_number,omitempty"`
+	// The number of the first record
+	RecordNumber uint32 `protobuf:"varint,2,opt,name=record_number,json=recordNumber,proto3" json:"record_number,omitempty"`
+	// The number of records, each of which is a register
+	Length        uint32 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileRecordReference) Reset() {
+	*x = FileRecordReference{}
+	mi := &file_modbustohttp_v1alpha1_types_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRecordReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRecordReference) ProtoMessage() {}
+
+func (x *FileRecordReference) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_types_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRecordReference.ProtoReflect.Descriptor instead.
+func (*FileRecordReference) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_types_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FileRecordReference) GetFileNumber() uint32 {
+	if x != nil {
+		return x.FileNumber
+	}
+	return 0
+}
+
+func (x *FileRecordReference) GetRecordNumber() uint32 {
+	if x != nil {
+		return x.RecordNumber
+	}
+	return 0
+}
+
+func (x *FileRecordReference) GetLength() uint32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// The values of a range of records in a file
+type FileRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The file number
+	FileNumber uint32 `protobuf:"varint,1,opt,name=file_number,json=fileNumber,proto3" json:"file_number,omitempty"`
+	// The number of the first record
+	RecordNumber uint32 `protobuf:"varint,2,opt,name=record_number,json=recordNumber,proto3" json:"record_number,omitempty"`
+	// The values of the records, starting from record_number
+	Values        []uint32 `protobuf:"varint,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileRecord) Reset() {
+	*x = FileRecord{}
+	mi := &file_modbustohttp_v1alpha1_types_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRecord) ProtoMessage() {}
+
+func (x *FileRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_modbustohttp_v1alpha1_types_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRecord.ProtoReflect.Descriptor instead.
+func (*FileRecord) Descriptor() ([]byte, []int) {
+	return file_modbustohttp_v1alpha1_types_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FileRecord) GetFileNumber() uint32 {
+	if x != nil {
+		return x.FileNumber
+	}
+	return 0
+}
+
+func (x *FileRecord) GetRecordNumber() uint32 {
+	if x != nil {
+		return x.RecordNumber
+	}
+	return 0
+}
+
+func (x *FileRecord) GetValues() []uint32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
 var File_modbustohttp_v1alpha1_types_proto protoreflect.FileDescriptor
 
 const file_modbustohttp_v1alpha1_types_proto_rawDesc = "" +
@@ -141,7 +389,33 @@ const file_modbustohttp_v1alpha1_types_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\bR\x05value\"R\n" +
 	"\bRegister\x12#\n" +
 	"\aaddress\x18\x01 \x01(\rB\t\xbaH\x06*\x04\x18\xff\xff\x03R\aaddress\x12!\n" +
-	"\x05value\x18\x02 \x01(\rB\v\xbaH\b*\x06\x18\xff\xff\x03(\x00R\x05valueB\xc8\x01\n" +
+	"\x05value\x18\x02 \x01(\rB\v\xbaH\b*\x06\x18\xff\xff\x03(\x00R\x05value\"\x9a\x02\n" +
+	"\x13FileRecordReference\x12,\n" +
+	"\vfile_number\x18\x01 \x01(\rB\v\xbaH\b*\x06\x18\xff\xff\x03(\x01R\n" +
+	"fileNumber\x12-\n" +
+	"\rrecord_number\x18\x02 \x01(\rB\b\xbaH\x05*\x03\x18\x8fNR\frecordNumber\x12!\n" +
+	"\x06length\x18\x03 \x01(\rB\t\xbaH\x06*\x04\x18y(\x01R\x06length:\x82\x01\xbaH\x7f\x1a}\n" +
+	"\x18records.not.out.of.range\x125record_number + length must not be greater than 10000\x1a*this.record_number + this.length <= 10000u\"\xb3\x02\n" +
+	"\n" +
+	"FileRecord\x12,\n" +
+	"\vfile_number\x18\x01 \x01(\rB\v\xbaH\b*\x06\x18\xff\xff\x03(\x01R\n" +
+	"fileNumber\x12-\n" +
+	"\rrecord_number\x18\x02 \x01(\rB\b\xbaH\x05*\x03\x18\x8fNR\frecordNumber\x12*\n" +
+	"\x06values\x18\x03 \x03(\rB\x12\xbaH\x0f\x92\x01\f\b\x01\x10w\"\x06*\x04\x18\xff\xff\x03R\x06values:\x9b\x01\xbaH\x97\x01\x1a\x94\x01\n" +
+	"\x18records.not.out.of.range\x12?record_number + length of values must not be greater than 10000\x1a7this.record_number + uint(this.values.size()) <= 10000u*\x9a\x01\n" +
+	"\x05Table\x12\x15\n" +
+	"\x11TABLE_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vTABLE_COILS\x10\x01\x12\x19\n" +
+	"\x15TABLE_DISCRETE_INPUTS\x10\x02\x12\x19\n" +
+	"\x15TABLE_INPUT_REGISTERS\x10\x03\x12\x1b\n" +
+	"\x17TABLE_HOLDING_REGISTERS\x10\x04\x12\x16\n" +
+	"\x12TABLE_FILE_RECORDS\x10\x05*\x80\x02\n" +
+	"\x1cDeviceIdentificationCategory\x12.\n" +
+	"*DEVICE_IDENTIFICATION_CATEGORY_UNSPECIFIED\x10\x00\x12(\n" +
+	"$DEVICE_IDENTIFICATION_CATEGORY_BASIC\x10\x01\x12*\n" +
+	"&DEVICE_IDENTIFICATION_CATEGORY_REGULAR\x10\x02\x12+\n" +
+	"'DEVICE_IDENTIFICATION_CATEGORY_EXTENDED\x10\x03\x12-\n" +
+	")DEVICE_IDENTIFICATION_CATEGORY_INDIVIDUAL\x10\x04B\xc8\x01\n" +
 	"\x19com.modbustohttp.v1alpha1B\n" +
 	"TypesProtoP\x01Z*modbustohttp/service/modbustohttp/v1alpha1\xa2\x02\x03MXX\xaa\x02\x15Modbustohttp.V1alpha1\xca\x02\x15Modbustohttp\\V1alpha1\xe2\x02!Modbustohttp\\V1alpha1\\GPBMetadata\xea\x02\x16Modbustohttp::V1alpha1b\x06proto3"
 
@@ -157,10 +431,15 @@ func file_modbustohttp_v1alpha1_types_proto_rawDescGZIP() []byte {
 	return file_modbustohttp_v1alpha1_types_proto_rawDescData
 }
 
-var file_modbustohttp_v1alpha1_types_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_modbustohttp_v1alpha1_types_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_modbustohttp_v1alpha1_types_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_modbustohttp_v1alpha1_types_proto_goTypes = []any{
-	(*BooleanAddress)(nil), // 0: modbustohttp.v1alpha1.BooleanAddress
-	(*Register)(nil),       // 1: modbustohttp.v1alpha1.Register
+	(Table)(0),                        // 0: modbustohttp.v1alpha1.Table
+	(DeviceIdentificationCategory)(0), // 1: modbustohttp.v1alpha1.DeviceIdentificationCategory
+	(*BooleanAddress)(nil),            // 2: modbustohttp.v1alpha1.BooleanAddress
+	(*Register)(nil),                  // 3: modbustohttp.v1alpha1.Register
+	(*FileRecordReference)(nil),       // 4: modbustohttp.v1alpha1.FileRecordReference
+	(*FileRecord)(nil),                // 5: modbustohttp.v1alpha1.FileRecord
 }
 var file_modbustohttp_v1alpha1_types_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
@@ -180,13 +459,14 @@ func file_modbustohttp_v1alpha1_types_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_modbustohttp_v1alpha1_types_proto_rawDesc), len(file_modbustohttp_v1alpha1_types_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_modbustohttp_v1alpha1_types_proto_goTypes,
 		DependencyIndexes: file_modbustohttp_v1alpha1_types_proto_depIdxs,
+		EnumInfos:         file_modbustohttp_v1alpha1_types_proto_enumTypes,
 		MessageInfos:      file_modbustohttp_v1alpha1_types_proto_msgTypes,
 	}.Build()
 	File_modbustohttp_v1alpha1_types_proto = out.File
@@ -59,9 +59,54 @@ const (
 	// ModbusServiceWriteBitInRegisterProcedure is the fully-qualified name of the ModbusService's
 	// WriteBitInRegister RPC.
 	ModbusServiceWriteBitInRegisterProcedure = "/modbustohttp.v1alpha1.ModbusService/WriteBitInRegister"
+	// ModbusServiceMaskWriteRegisterProcedure is the fully-qualified name of the ModbusService's
+	// MaskWriteRegister RPC.
+	ModbusServiceMaskWriteRegisterProcedure = "/modbustohttp.v1alpha1.ModbusService/MaskWriteRegister"
+	// ModbusServiceWriteBitsInRegisterProcedure is the fully-qualified name of the ModbusService's
+	// WriteBitsInRegister RPC.
+	ModbusServiceWriteBitsInRegisterProcedure = "/modbustohttp.v1alpha1.ModbusService/WriteBitsInRegister"
 	// ModbusServiceReadRegisterAsBitsProcedure is the fully-qualified name of the ModbusService's
 	// ReadRegisterAsBits RPC.
 	ModbusServiceReadRegisterAsBitsProcedure = "/modbustohttp.v1alpha1.ModbusService/ReadRegisterAsBits"
+	// ModbusServiceReadWriteMultipleRegistersProcedure is the fully-qualified name of the
+	// ModbusService's ReadWriteMultipleRegisters RPC.
+	ModbusServiceReadWriteMultipleRegistersProcedure = "/modbustohttp.v1alpha1.ModbusService/ReadWriteMultipleRegisters"
+	// ModbusServicePrepareWriteProcedure is the fully-qualified name of the ModbusService's
+	// PrepareWrite RPC.
+	ModbusServicePrepareWriteProcedure = "/modbustohttp.v1alpha1.ModbusService/PrepareWrite"
+	// ModbusServiceExecuteWriteProcedure is the fully-qualified name of the ModbusService's
+	// ExecuteWrite RPC.
+	ModbusServiceExecuteWriteProcedure = "/modbustohttp.v1alpha1.ModbusService/ExecuteWrite"
+	// ModbusServiceReadDeviceIdentificationProcedure is the fully-qualified name of the ModbusService's
+	// ReadDeviceIdentification RPC.
+	ModbusServiceReadDeviceIdentificationProcedure = "/modbustohttp.v1alpha1.ModbusService/ReadDeviceIdentification"
+	// ModbusServiceReturnQueryDataProcedure is the fully-qualified name of the ModbusService's
+	// ReturnQueryData RPC.
+	ModbusServiceReturnQueryDataProcedure = "/modbustohttp.v1alpha1.ModbusService/ReturnQueryData"
+	// ModbusServiceClearDiagnosticCountersProcedure is the fully-qualified name of the ModbusService's
+	// ClearDiagnosticCounters RPC.
+	ModbusServiceClearDiagnosticCountersProcedure = "/modbustohttp.v1alpha1.ModbusService/ClearDiagnosticCounters"
+	// ModbusServiceGetDiagnosticCountersProcedure is the fully-qualified name of the ModbusService's
+	// GetDiagnosticCounters RPC.
+	ModbusServiceGetDiagnosticCountersProcedure = "/modbustohttp.v1alpha1.ModbusService/GetDiagnosticCounters"
+	// ModbusServiceGetCommEventCounterProcedure is the fully-qualified name of the ModbusService's
+	// GetCommEventCounter RPC.
+	ModbusServiceGetCommEventCounterProcedure = "/modbustohttp.v1alpha1.ModbusService/GetCommEventCounter"
+	// ModbusServiceGetCommEventLogProcedure is the fully-qualified name of the ModbusService's
+	// GetCommEventLog RPC.
+	ModbusServiceGetCommEventLogProcedure = "/modbustohttp.v1alpha1.ModbusService/GetCommEventLog"
+	// ModbusServiceReadFIFOQueueProcedure is the fully-qualified name of the ModbusService's
+	// ReadFIFOQueue RPC.
+	ModbusServiceReadFIFOQueueProcedure = "/modbustohttp.v1alpha1.ModbusService/ReadFIFOQueue"
+	// ModbusServiceReadFileRecordProcedure is the fully-qualified name of the ModbusService's
+	// ReadFileRecord RPC.
+	ModbusServiceReadFileRecordProcedure = "/modbustohttp.v1alpha1.ModbusService/ReadFileRecord"
+	// ModbusServiceWriteFileRecordProcedure is the fully-qualified name of the ModbusService's
+	// WriteFileRecord RPC.
+	ModbusServiceWriteFileRecordProcedure = "/modbustohttp.v1alpha1.ModbusService/WriteFileRecord"
+	// ModbusServiceSendRawPDUProcedure is the fully-qualified name of the ModbusService's SendRawPDU
+	// RPC.
+	ModbusServiceSendRawPDUProcedure = "/modbustohttp.v1alpha1.ModbusService/SendRawPDU"
 )
 
 // ModbusServiceClient is a client for the modbustohttp.v1alpha1.ModbusService service.
@@ -84,8 +129,44 @@ type ModbusServiceClient interface {
 	WriteMultipleRegisters(context.Context, *connect.Request[v1alpha1.WriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.WriteMultipleRegistersResponse], error)
 	// WriteBitInRegister writes a single bit in a holding register to the modbus server
 	WriteBitInRegister(context.Context, *connect.Request[v1alpha1.WriteBitInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitInRegisterResponse], error)
+	// MaskWriteRegister modifies the bits of a holding register using AND and OR masks
+	MaskWriteRegister(context.Context, *connect.Request[v1alpha1.MaskWriteRegisterRequest]) (*connect.Response[v1alpha1.MaskWriteRegisterResponse], error)
+	// WriteBitsInRegister writes several bits in a holding register to the modbus server, leaving the other bits unchanged
+	WriteBitsInRegister(context.Context, *connect.Request[v1alpha1.WriteBitsInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitsInRegisterResponse], error)
 	// ReadRegisterAsBits reads a holding register and returns its bits
 	ReadRegisterAsBits(context.Context, *connect.Request[v1alpha1.ReadRegisterAsBitsRequest]) (*connect.Response[v1alpha1.ReadRegisterAsBitsResponse], error)
+	// ReadWriteMultipleRegisters writes multiple holding registers and then reads multiple holding registers in a single
+	// transaction
+	ReadWriteMultipleRegisters(context.Context, *connect.Request[v1alpha1.ReadWriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.ReadWriteMultipleRegistersResponse], error)
+	// PrepareWrite arms a write to a single coil or holding register, returning a short-lived token which must be passed
+	// to ExecuteWrite to perform it
+	PrepareWrite(context.Context, *connect.Request[v1alpha1.PrepareWriteRequest]) (*connect.Response[v1alpha1.PrepareWriteResponse], error)
+	// ExecuteWrite performs a write armed by PrepareWrite, if the token is still valid and the current value has not
+	// changed since the write was prepared
+	ExecuteWrite(context.Context, *connect.Request[v1alpha1.ExecuteWriteRequest]) (*connect.Response[v1alpha1.ExecuteWriteResponse], error)
+	// ReadDeviceIdentification reads the identification objects of the modbus server, such as its vendor name, product
+	// code and revision
+	ReadDeviceIdentification(context.Context, *connect.Request[v1alpha1.ReadDeviceIdentificationRequest]) (*connect.Response[v1alpha1.ReadDeviceIdentificationResponse], error)
+	// ReturnQueryData sends data to the modbus server, which returns it unchanged, to test the communication path
+	ReturnQueryData(context.Context, *connect.Request[v1alpha1.ReturnQueryDataRequest]) (*connect.Response[v1alpha1.ReturnQueryDataResponse], error)
+	// ClearDiagnosticCounters clears the diagnostic counters and diagnostic register of the modbus server
+	ClearDiagnosticCounters(context.Context, *connect.Request[v1alpha1.ClearDiagnosticCountersRequest]) (*connect.Response[v1alpha1.ClearDiagnosticCountersResponse], error)
+	// GetDiagnosticCounters reads the diagnostic counters of the modbus server
+	GetDiagnosticCounters(context.Context, *connect.Request[v1alpha1.GetDiagnosticCountersRequest]) (*connect.Response[v1alpha1.GetDiagnosticCountersResponse], error)
+	// GetCommEventCounter reads the communication event counter of the modbus server
+	GetCommEventCounter(context.Context, *connect.Request[v1alpha1.GetCommEventCounterRequest]) (*connect.Response[v1alpha1.GetCommEventCounterResponse], error)
+	// GetCommEventLog reads the communication event log of the modbus server
+	GetCommEventLog(context.Context, *connect.Request[v1alpha1.GetCommEventLogRequest]) (*connect.Response[v1alpha1.GetCommEventLogResponse], error)
+	// ReadFIFOQueue reads the contents of a first-in-first-out queue of registers from the modbus server, without
+	// clearing it
+	ReadFIFOQueue(context.Context, *connect.Request[v1alpha1.ReadFIFOQueueRequest]) (*connect.Response[v1alpha1.ReadFIFOQueueResponse], error)
+	// ReadFileRecord reads ranges of records from files on the modbus server in a single transaction
+	ReadFileRecord(context.Context, *connect.Request[v1alpha1.ReadFileRecordRequest]) (*connect.Response[v1alpha1.ReadFileRecordResponse], error)
+	// WriteFileRecord writes ranges of records to files on the modbus server in a single transaction
+	WriteFileRecord(context.Context, *connect.Request[v1alpha1.WriteFileRecordRequest]) (*connect.Response[v1alpha1.WriteFileRecordResponse], error)
+	// SendRawPDU sends a request PDU with a vendor specific function code to the modbus server and returns the response
+	// PDU, including any exception
+	SendRawPDU(context.Context, *connect.Request[v1alpha1.SendRawPDURequest]) (*connect.Response[v1alpha1.SendRawPDUResponse], error)
 }
 
 // NewModbusServiceClient constructs a client for the modbustohttp.v1alpha1.ModbusService service.
@@ -161,6 +242,20 @@ func NewModbusServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 			connect.WithSchema(modbusServiceMethods.ByName("WriteBitInRegister")),
 			connect.WithClientOptions(opts...),
 		),
+		maskWriteRegister: connect.NewClient[v1alpha1.MaskWriteRegisterRequest, v1alpha1.MaskWriteRegisterResponse](
+			httpClient,
+			baseURL+ModbusServiceMaskWriteRegisterProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("MaskWriteRegister")),
+			connect.WithIdempotency(connect.IdempotencyIdempotent),
+			connect.WithClientOptions(opts...),
+		),
+		writeBitsInRegister: connect.NewClient[v1alpha1.WriteBitsInRegisterRequest, v1alpha1.WriteBitsInRegisterResponse](
+			httpClient,
+			baseURL+ModbusServiceWriteBitsInRegisterProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("WriteBitsInRegister")),
+			connect.WithIdempotency(connect.IdempotencyIdempotent),
+			connect.WithClientOptions(opts...),
+		),
 		readRegisterAsBits: connect.NewClient[v1alpha1.ReadRegisterAsBitsRequest, v1alpha1.ReadRegisterAsBitsResponse](
 			httpClient,
 			baseURL+ModbusServiceReadRegisterAsBitsProcedure,
@@ -168,21 +263,123 @@ func NewModbusServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
 			connect.WithClientOptions(opts...),
 		),
+		readWriteMultipleRegisters: connect.NewClient[v1alpha1.ReadWriteMultipleRegistersRequest, v1alpha1.ReadWriteMultipleRegistersResponse](
+			httpClient,
+			baseURL+ModbusServiceReadWriteMultipleRegistersProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ReadWriteMultipleRegisters")),
+			connect.WithClientOptions(opts...),
+		),
+		prepareWrite: connect.NewClient[v1alpha1.PrepareWriteRequest, v1alpha1.PrepareWriteResponse](
+			httpClient,
+			baseURL+ModbusServicePrepareWriteProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("PrepareWrite")),
+			connect.WithClientOptions(opts...),
+		),
+		executeWrite: connect.NewClient[v1alpha1.ExecuteWriteRequest, v1alpha1.ExecuteWriteResponse](
+			httpClient,
+			baseURL+ModbusServiceExecuteWriteProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ExecuteWrite")),
+			connect.WithClientOptions(opts...),
+		),
+		readDeviceIdentification: connect.NewClient[v1alpha1.ReadDeviceIdentificationRequest, v1alpha1.ReadDeviceIdentificationResponse](
+			httpClient,
+			baseURL+ModbusServiceReadDeviceIdentificationProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ReadDeviceIdentification")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		returnQueryData: connect.NewClient[v1alpha1.ReturnQueryDataRequest, v1alpha1.ReturnQueryDataResponse](
+			httpClient,
+			baseURL+ModbusServiceReturnQueryDataProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ReturnQueryData")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		clearDiagnosticCounters: connect.NewClient[v1alpha1.ClearDiagnosticCountersRequest, v1alpha1.ClearDiagnosticCountersResponse](
+			httpClient,
+			baseURL+ModbusServiceClearDiagnosticCountersProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ClearDiagnosticCounters")),
+			connect.WithIdempotency(connect.IdempotencyIdempotent),
+			connect.WithClientOptions(opts...),
+		),
+		getDiagnosticCounters: connect.NewClient[v1alpha1.GetDiagnosticCountersRequest, v1alpha1.GetDiagnosticCountersResponse](
+			httpClient,
+			baseURL+ModbusServiceGetDiagnosticCountersProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("GetDiagnosticCounters")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		getCommEventCounter: connect.NewClient[v1alpha1.GetCommEventCounterRequest, v1alpha1.GetCommEventCounterResponse](
+			httpClient,
+			baseURL+ModbusServiceGetCommEventCounterProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("GetCommEventCounter")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		getCommEventLog: connect.NewClient[v1alpha1.GetCommEventLogRequest, v1alpha1.GetCommEventLogResponse](
+			httpClient,
+			baseURL+ModbusServiceGetCommEventLogProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("GetCommEventLog")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		readFIFOQueue: connect.NewClient[v1alpha1.ReadFIFOQueueRequest, v1alpha1.ReadFIFOQueueResponse](
+			httpClient,
+			baseURL+ModbusServiceReadFIFOQueueProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ReadFIFOQueue")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		readFileRecord: connect.NewClient[v1alpha1.ReadFileRecordRequest, v1alpha1.ReadFileRecordResponse](
+			httpClient,
+			baseURL+ModbusServiceReadFileRecordProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("ReadFileRecord")),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		writeFileRecord: connect.NewClient[v1alpha1.WriteFileRecordRequest, v1alpha1.WriteFileRecordResponse](
+			httpClient,
+			baseURL+ModbusServiceWriteFileRecordProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("WriteFileRecord")),
+			connect.WithIdempotency(connect.IdempotencyIdempotent),
+			connect.WithClientOptions(opts...),
+		),
+		sendRawPDU: connect.NewClient[v1alpha1.SendRawPDURequest, v1alpha1.SendRawPDUResponse](
+			httpClient,
+			baseURL+ModbusServiceSendRawPDUProcedure,
+			connect.WithSchema(modbusServiceMethods.ByName("SendRawPDU")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // modbusServiceClient implements ModbusServiceClient.
 type modbusServiceClient struct {
-	readHoldingRegisters   *connect.Client[v1alpha1.ReadHoldingRegistersRequest, v1alpha1.ReadHoldingRegistersResponse]
-	writeSingleRegister    *connect.Client[v1alpha1.WriteSingleRegisterRequest, v1alpha1.WriteSingleRegisterResponse]
-	readCoils              *connect.Client[v1alpha1.ReadCoilsRequest, v1alpha1.ReadCoilsResponse]
-	readDiscreteInputs     *connect.Client[v1alpha1.ReadDiscreteInputsRequest, v1alpha1.ReadDiscreteInputsResponse]
-	writeSingleCoil        *connect.Client[v1alpha1.WriteSingleCoilRequest, v1alpha1.WriteSingleCoilResponse]
-	writeMultipleCoils     *connect.Client[v1alpha1.WriteMultipleCoilsRequest, v1alpha1.WriteMultipleCoilsResponse]
-	readInputRegisters     *connect.Client[v1alpha1.ReadInputRegistersRequest, v1alpha1.ReadInputRegistersResponse]
-	writeMultipleRegisters *connect.Client[v1alpha1.WriteMultipleRegistersRequest, v1alpha1.WriteMultipleRegistersResponse]
-	writeBitInRegister     *connect.Client[v1alpha1.WriteBitInRegisterRequest, v1alpha1.WriteBitInRegisterResponse]
-	readRegisterAsBits     *connect.Client[v1alpha1.ReadRegisterAsBitsRequest, v1alpha1.ReadRegisterAsBitsResponse]
+	readHoldingRegisters       *connect.Client[v1alpha1.ReadHoldingRegistersRequest, v1alpha1.ReadHoldingRegistersResponse]
+	writeSingleRegister        *connect.Client[v1alpha1.WriteSingleRegisterRequest, v1alpha1.WriteSingleRegisterResponse]
+	readCoils                  *connect.Client[v1alpha1.ReadCoilsRequest, v1alpha1.ReadCoilsResponse]
+	readDiscreteInputs         *connect.Client[v1alpha1.ReadDiscreteInputsRequest, v1alpha1.ReadDiscreteInputsResponse]
+	writeSingleCoil            *connect.Client[v1alpha1.WriteSingleCoilRequest, v1alpha1.WriteSingleCoilResponse]
+	writeMultipleCoils         *connect.Client[v1alpha1.WriteMultipleCoilsRequest, v1alpha1.WriteMultipleCoilsResponse]
+	readInputRegisters         *connect.Client[v1alpha1.ReadInputRegistersRequest, v1alpha1.ReadInputRegistersResponse]
+	writeMultipleRegisters     *connect.Client[v1alpha1.WriteMultipleRegistersRequest, v1alpha1.WriteMultipleRegistersResponse]
+	writeBitInRegister         *connect.Client[v1alpha1.WriteBitInRegisterRequest, v1alpha1.WriteBitInRegisterResponse]
+	maskWriteRegister          *connect.Client[v1alpha1.MaskWriteRegisterRequest, v1alpha1.MaskWriteRegisterResponse]
+	writeBitsInRegister        *connect.Client[v1alpha1.WriteBitsInRegisterRequest, v1alpha1.WriteBitsInRegisterResponse]
+	readRegisterAsBits         *connect.Client[v1alpha1.ReadRegisterAsBitsRequest, v1alpha1.ReadRegisterAsBitsResponse]
+	readWriteMultipleRegisters *connect.Client[v1alpha1.ReadWriteMultipleRegistersRequest, v1alpha1.ReadWriteMultipleRegistersResponse]
+	prepareWrite               *connect.Client[v1alpha1.PrepareWriteRequest, v1alpha1.PrepareWriteResponse]
+	executeWrite               *connect.Client[v1alpha1.ExecuteWriteRequest, v1alpha1.ExecuteWriteResponse]
+	readDeviceIdentification   *connect.Client[v1alpha1.ReadDeviceIdentificationRequest, v1alpha1.ReadDeviceIdentificationResponse]
+	returnQueryData            *connect.Client[v1alpha1.ReturnQueryDataRequest, v1alpha1.ReturnQueryDataResponse]
+	clearDiagnosticCounters    *connect.Client[v1alpha1.ClearDiagnosticCountersRequest, v1alpha1.ClearDiagnosticCountersResponse]
+	getDiagnosticCounters      *connect.Client[v1alpha1.GetDiagnosticCountersRequest, v1alpha1.GetDiagnosticCountersResponse]
+	getCommEventCounter        *connect.Client[v1alpha1.GetCommEventCounterRequest, v1alpha1.GetCommEventCounterResponse]
+	getCommEventLog            *connect.Client[v1alpha1.GetCommEventLogRequest, v1alpha1.GetCommEventLogResponse]
+	readFIFOQueue              *connect.Client[v1alpha1.ReadFIFOQueueRequest, v1alpha1.ReadFIFOQueueResponse]
+	readFileRecord             *connect.Client[v1alpha1.ReadFileRecordRequest, v1alpha1.ReadFileRecordResponse]
+	writeFileRecord            *connect.Client[v1alpha1.WriteFileRecordRequest, v1alpha1.WriteFileRecordResponse]
+	sendRawPDU                 *connect.Client[v1alpha1.SendRawPDURequest, v1alpha1.SendRawPDUResponse]
 }
 
 // ReadHoldingRegisters calls modbustohttp.v1alpha1.ModbusService.ReadHoldingRegisters.
@@ -230,11 +427,86 @@ func (c *modbusServiceClient) WriteBitInRegister(ctx context.Context, req *conne
 	return c.writeBitInRegister.CallUnary(ctx, req)
 }
 
+// MaskWriteRegister calls modbustohttp.v1alpha1.ModbusService.MaskWriteRegister.
+func (c *modbusServiceClient) MaskWriteRegister(ctx context.Context, req *connect.Request[v1alpha1.MaskWriteRegisterRequest]) (*connect.Response[v1alpha1.MaskWriteRegisterResponse], error) {
+	return c.maskWriteRegister.CallUnary(ctx, req)
+}
+
+// WriteBitsInRegister calls modbustohttp.v1alpha1.ModbusService.WriteBitsInRegister.
+func (c *modbusServiceClient) WriteBitsInRegister(ctx context.Context, req *connect.Request[v1alpha1.WriteBitsInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitsInRegisterResponse], error) {
+	return c.writeBitsInRegister.CallUnary(ctx, req)
+}
+
 // ReadRegisterAsBits calls modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits.
 func (c *modbusServiceClient) ReadRegisterAsBits(ctx context.Context, req *connect.Request[v1alpha1.ReadRegisterAsBitsRequest]) (*connect.Response[v1alpha1.ReadRegisterAsBitsResponse], error) {
 	return c.readRegisterAsBits.CallUnary(ctx, req)
 }
 
+// ReadWriteMultipleRegisters calls modbustohttp.v1alpha1.ModbusService.ReadWriteMultipleRegisters.
+func (c *modbusServiceClient) ReadWriteMultipleRegisters(ctx context.Context, req *connect.Request[v1alpha1.ReadWriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.ReadWriteMultipleRegistersResponse], error) {
+	return c.readWriteMultipleRegisters.CallUnary(ctx, req)
+}
+
+// PrepareWrite calls modbustohttp.v1alpha1.ModbusService.PrepareWrite.
+func (c *modbusServiceClient) PrepareWrite(ctx context.Context, req *connect.Request[v1alpha1.PrepareWriteRequest]) (*connect.Response[v1alpha1.PrepareWriteResponse], error) {
+	return c.prepareWrite.CallUnary(ctx, req)
+}
+
+// ExecuteWrite calls modbustohttp.v1alpha1.ModbusService.ExecuteWrite.
+func (c *modbusServiceClient) ExecuteWrite(ctx context.Context, req *connect.Request[v1alpha1.ExecuteWriteRequest]) (*connect.Response[v1alpha1.ExecuteWriteResponse], error) {
+	return c.executeWrite.CallUnary(ctx, req)
+}
+
+// ReadDeviceIdentification calls modbustohttp.v1alpha1.ModbusService.ReadDeviceIdentification.
+func (c *modbusServiceClient) ReadDeviceIdentification(ctx context.Context, req *connect.Request[v1alpha1.ReadDeviceIdentificationRequest]) (*connect.Response[v1alpha1.ReadDeviceIdentificationResponse], error) {
+	return c.readDeviceIdentification.CallUnary(ctx, req)
+}
+
+// ReturnQueryData calls modbustohttp.v1alpha1.ModbusService.ReturnQueryData.
+func (c *modbusServiceClient) ReturnQueryData(ctx context.Context, req *connect.Request[v1alpha1.ReturnQueryDataRequest]) (*connect.Response[v1alpha1.ReturnQueryDataResponse], error) {
+	return c.returnQueryData.CallUnary(ctx, req)
+}
+
+// ClearDiagnosticCounters calls modbustohttp.v1alpha1.ModbusService.ClearDiagnosticCounters.
+func (c *modbusServiceClient) ClearDiagnosticCounters(ctx context.Context, req *connect.Request[v1alpha1.ClearDiagnosticCountersRequest]) (*connect.Response[v1alpha1.ClearDiagnosticCountersResponse], error) {
+	return c.clearDiagnosticCounters.CallUnary(ctx, req)
+}
+
+// GetDiagnosticCounters calls modbustohttp.v1alpha1.ModbusService.GetDiagnosticCounters.
+func (c *modbusServiceClient) GetDiagnosticCounters(ctx context.Context, req *connect.Request[v1alpha1.GetDiagnosticCountersRequest]) (*connect.Response[v1alpha1.GetDiagnosticCountersResponse], error) {
+	return c.getDiagnosticCounters.CallUnary(ctx, req)
+}
+
+// GetCommEventCounter calls modbustohttp.v1alpha1.ModbusService.GetCommEventCounter.
+func (c *modbusServiceClient) GetCommEventCounter(ctx context.Context, req *connect.Request[v1alpha1.GetCommEventCounterRequest]) (*connect.Response[v1alpha1.GetCommEventCounterResponse], error) {
+	return c.getCommEventCounter.CallUnary(ctx, req)
+}
+
+// GetCommEventLog calls modbustohttp.v1alpha1.ModbusService.GetCommEventLog.
+func (c *modbusServiceClient) GetCommEventLog(ctx context.Context, req *connect.Request[v1alpha1.GetCommEventLogRequest]) (*connect.Response[v1alpha1.GetCommEventLogResponse], error) {
+	return c.getCommEventLog.CallUnary(ctx, req)
+}
+
+// ReadFIFOQueue calls modbustohttp.v1alpha1.ModbusService.ReadFIFOQueue.
+func (c *modbusServiceClient) ReadFIFOQueue(ctx context.Context, req *connect.Request[v1alpha1.ReadFIFOQueueRequest]) (*connect.Response[v1alpha1.ReadFIFOQueueResponse], error) {
+	return c.readFIFOQueue.CallUnary(ctx, req)
+}
+
+// ReadFileRecord calls modbustohttp.v1alpha1.ModbusService.ReadFileRecord.
+func (c *modbusServiceClient) ReadFileRecord(ctx context.Context, req *connect.Request[v1alpha1.ReadFileRecordRequest]) (*connect.Response[v1alpha1.ReadFileRecordResponse], error) {
+	return c.readFileRecord.CallUnary(ctx, req)
+}
+
+// WriteFileRecord calls modbustohttp.v1alpha1.ModbusService.WriteFileRecord.
+func (c *modbusServiceClient) WriteFileRecord(ctx context.Context, req *connect.Request[v1alpha1.WriteFileRecordRequest]) (*connect.Response[v1alpha1.WriteFileRecordResponse], error) {
+	return c.writeFileRecord.CallUnary(ctx, req)
+}
+
+// SendRawPDU calls modbustohttp.v1alpha1.ModbusService.SendRawPDU.
+func (c *modbusServiceClient) SendRawPDU(ctx context.Context, req *connect.Request[v1alpha1.SendRawPDURequest]) (*connect.Response[v1alpha1.SendRawPDUResponse], error) {
+	return c.sendRawPDU.CallUnary(ctx, req)
+}
+
 // ModbusServiceHandler is an implementation of the modbustohttp.v1alpha1.ModbusService service.
 type ModbusServiceHandler interface {
 	// ReadHoldingRegisters reads the holding registers from the modbus server
@@ -255,8 +527,44 @@ type ModbusServiceHandler interface {
 	WriteMultipleRegisters(context.Context, *connect.Request[v1alpha1.WriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.WriteMultipleRegistersResponse], error)
 	// WriteBitInRegister writes a single bit in a holding register to the modbus server
 	WriteBitInRegister(context.Context, *connect.Request[v1alpha1.WriteBitInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitInRegisterResponse], error)
+	// MaskWriteRegister modifies the bits of a holding register using AND and OR masks
+	MaskWriteRegister(context.Context, *connect.Request[v1alpha1.MaskWriteRegisterRequest]) (*connect.Response[v1alpha1.MaskWriteRegisterResponse], error)
+	// WriteBitsInRegister writes several bits in a holding register to the modbus server, leaving the other bits unchanged
+	WriteBitsInRegister(context.Context, *connect.Request[v1alpha1.WriteBitsInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitsInRegisterResponse], error)
 	// ReadRegisterAsBits reads a holding register and returns its bits
 	ReadRegisterAsBits(context.Context, *connect.Request[v1alpha1.ReadRegisterAsBitsRequest]) (*connect.Response[v1alpha1.ReadRegisterAsBitsResponse], error)
+	// ReadWriteMultipleRegisters writes multiple holding registers and then reads multiple holding registers in a single
+	// transaction
+	ReadWriteMultipleRegisters(context.Context, *connect.Request[v1alpha1.ReadWriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.ReadWriteMultipleRegistersResponse], error)
+	// PrepareWrite arms a write to a single coil or holding register, returning a short-lived token which must be passed
+	// to ExecuteWrite to perform it
+	PrepareWrite(context.Context, *connect.Request[v1alpha1.PrepareWriteRequest]) (*connect.Response[v1alpha1.PrepareWriteResponse], error)
+	// ExecuteWrite performs a write armed by PrepareWrite, if the token is still valid and the current value has not
+	// changed since the write was prepared
+	ExecuteWrite(context.Context, *connect.Request[v1alpha1.ExecuteWriteRequest]) (*connect.Response[v1alpha1.ExecuteWriteResponse], error)
+	// ReadDeviceIdentification reads the identification objects of the modbus server, such as its vendor name, product
+	// code and revision
+	ReadDeviceIdentification(context.Context, *connect.Request[v1alpha1.ReadDeviceIdentificationRequest]) (*connect.Response[v1alpha1.ReadDeviceIdentificationResponse], error)
+	// ReturnQueryData sends data to the modbus server, which returns it unchanged, to test the communication path
+	ReturnQueryData(context.Context, *connect.Request[v1alpha1.ReturnQueryDataRequest]) (*connect.Response[v1alpha1.ReturnQueryDataResponse], error)
+	// ClearDiagnosticCounters clears the diagnostic counters and diagnostic register of the modbus server
+	ClearDiagnosticCounters(context.Context, *connect.Request[v1alpha1.ClearDiagnosticCountersRequest]) (*connect.Response[v1alpha1.ClearDiagnosticCountersResponse], error)
+	// GetDiagnosticCounters reads the diagnostic counters of the modbus server
+	GetDiagnosticCounters(context.Context, *connect.Request[v1alpha1.GetDiagnosticCountersRequest]) (*connect.Response[v1alpha1.GetDiagnosticCountersResponse], error)
+	// GetCommEventCounter reads the communication event counter of the modbus server
+	GetCommEventCounter(context.Context, *connect.Request[v1alpha1.GetCommEventCounterRequest]) (*connect.Response[v1alpha1.GetCommEventCounterResponse], error)
+	// GetCommEventLog reads the communication event log of the modbus server
+	GetCommEventLog(context.Context, *connect.Request[v1alpha1.GetCommEventLogRequest]) (*connect.Response[v1alpha1.GetCommEventLogResponse], error)
+	// ReadFIFOQueue reads the contents of a first-in-first-out queue of registers from the modbus server, without
+	// clearing it
+	ReadFIFOQueue(context.Context, *connect.Request[v1alpha1.ReadFIFOQueueRequest]) (*connect.Response[v1alpha1.ReadFIFOQueueResponse], error)
+	// ReadFileRecord reads ranges of records from files on the modbus server in a single transaction
+	ReadFileRecord(context.Context, *connect.Request[v1alpha1.ReadFileRecordRequest]) (*connect.Response[v1alpha1.ReadFileRecordResponse], error)
+	// WriteFileRecord writes ranges of records to files on the modbus server in a single transaction
+	WriteFileRecord(context.Context, *connect.Request[v1alpha1.WriteFileRecordRequest]) (*connect.Response[v1alpha1.WriteFileRecordResponse], error)
+	// SendRawPDU sends a request PDU with a vendor specific function code to the modbus server and returns the response
+	// PDU, including any exception
+	SendRawPDU(context.Context, *connect.Request[v1alpha1.SendRawPDURequest]) (*connect.Response[v1alpha1.SendRawPDUResponse], error)
 }
 
 // NewModbusServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -328,6 +636,20 @@ func NewModbusServiceHandler(svc ModbusServiceHandler, opts ...connect.HandlerOp
 		connect.WithSchema(modbusServiceMethods.ByName("WriteBitInRegister")),
 		connect.WithHandlerOptions(opts...),
 	)
+	modbusServiceMaskWriteRegisterHandler := connect.NewUnaryHandler(
+		ModbusServiceMaskWriteRegisterProcedure,
+		svc.MaskWriteRegister,
+		connect.WithSchema(modbusServiceMethods.ByName("MaskWriteRegister")),
+		connect.WithIdempotency(connect.IdempotencyIdempotent),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceWriteBitsInRegisterHandler := connect.NewUnaryHandler(
+		ModbusServiceWriteBitsInRegisterProcedure,
+		svc.WriteBitsInRegister,
+		connect.WithSchema(modbusServiceMethods.ByName("WriteBitsInRegister")),
+		connect.WithIdempotency(connect.IdempotencyIdempotent),
+		connect.WithHandlerOptions(opts...),
+	)
 	modbusServiceReadRegisterAsBitsHandler := connect.NewUnaryHandler(
 		ModbusServiceReadRegisterAsBitsProcedure,
 		svc.ReadRegisterAsBits,
@@ -335,6 +657,93 @@ func NewModbusServiceHandler(svc ModbusServiceHandler, opts ...connect.HandlerOp
 		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
 		connect.WithHandlerOptions(opts...),
 	)
+	modbusServiceReadWriteMultipleRegistersHandler := connect.NewUnaryHandler(
+		ModbusServiceReadWriteMultipleRegistersProcedure,
+		svc.ReadWriteMultipleRegisters,
+		connect.WithSchema(modbusServiceMethods.ByName("ReadWriteMultipleRegisters")),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServicePrepareWriteHandler := connect.NewUnaryHandler(
+		ModbusServicePrepareWriteProcedure,
+		svc.PrepareWrite,
+		connect.WithSchema(modbusServiceMethods.ByName("PrepareWrite")),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceExecuteWriteHandler := connect.NewUnaryHandler(
+		ModbusServiceExecuteWriteProcedure,
+		svc.ExecuteWrite,
+		connect.WithSchema(modbusServiceMethods.ByName("ExecuteWrite")),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceReadDeviceIdentificationHandler := connect.NewUnaryHandler(
+		ModbusServiceReadDeviceIdentificationProcedure,
+		svc.ReadDeviceIdentification,
+		connect.WithSchema(modbusServiceMethods.ByName("ReadDeviceIdentification")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceReturnQueryDataHandler := connect.NewUnaryHandler(
+		ModbusServiceReturnQueryDataProcedure,
+		svc.ReturnQueryData,
+		connect.WithSchema(modbusServiceMethods.ByName("ReturnQueryData")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceClearDiagnosticCountersHandler := connect.NewUnaryHandler(
+		ModbusServiceClearDiagnosticCountersProcedure,
+		svc.ClearDiagnosticCounters,
+		connect.WithSchema(modbusServiceMethods.ByName("ClearDiagnosticCounters")),
+		connect.WithIdempotency(connect.IdempotencyIdempotent),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceGetDiagnosticCountersHandler := connect.NewUnaryHandler(
+		ModbusServiceGetDiagnosticCountersProcedure,
+		svc.GetDiagnosticCounters,
+		connect.WithSchema(modbusServiceMethods.ByName("GetDiagnosticCounters")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceGetCommEventCounterHandler := connect.NewUnaryHandler(
+		ModbusServiceGetCommEventCounterProcedure,
+		svc.GetCommEventCounter,
+		connect.WithSchema(modbusServiceMethods.ByName("GetCommEventCounter")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceGetCommEventLogHandler := connect.NewUnaryHandler(
+		ModbusServiceGetCommEventLogProcedure,
+		svc.GetCommEventLog,
+		connect.WithSchema(modbusServiceMethods.ByName("GetCommEventLog")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceReadFIFOQueueHandler := connect.NewUnaryHandler(
+		ModbusServiceReadFIFOQueueProcedure,
+		svc.ReadFIFOQueue,
+		connect.WithSchema(modbusServiceMethods.ByName("ReadFIFOQueue")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceReadFileRecordHandler := connect.NewUnaryHandler(
+		ModbusServiceReadFileRecordProcedure,
+		svc.ReadFileRecord,
+		connect.WithSchema(modbusServiceMethods.ByName("ReadFileRecord")),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceWriteFileRecordHandler := connect.NewUnaryHandler(
+		ModbusServiceWriteFileRecordProcedure,
+		svc.WriteFileRecord,
+		connect.WithSchema(modbusServiceMethods.ByName("WriteFileRecord")),
+		connect.WithIdempotency(connect.IdempotencyIdempotent),
+		connect.WithHandlerOptions(opts...),
+	)
+	modbusServiceSendRawPDUHandler := connect.NewUnaryHandler(
+		ModbusServiceSendRawPDUProcedure,
+		svc.SendRawPDU,
+		connect.WithSchema(modbusServiceMethods.ByName("SendRawPDU")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/modbustohttp.v1alpha1.ModbusService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case ModbusServiceReadHoldingRegistersProcedure:
@@ -355,8 +764,38 @@ func NewModbusServiceHandler(svc ModbusServiceHandler, opts ...connect.HandlerOp
 			modbusServiceWriteMultipleRegistersHandler.ServeHTTP(w, r)
 		case ModbusServiceWriteBitInRegisterProcedure:
 			modbusServiceWriteBitInRegisterHandler.ServeHTTP(w, r)
+		case ModbusServiceMaskWriteRegisterProcedure:
+			modbusServiceMaskWriteRegisterHandler.ServeHTTP(w, r)
+		case ModbusServiceWriteBitsInRegisterProcedure:
+			modbusServiceWriteBitsInRegisterHandler.ServeHTTP(w, r)
 		case ModbusServiceReadRegisterAsBitsProcedure:
 			modbusServiceReadRegisterAsBitsHandler.ServeHTTP(w, r)
+		case ModbusServiceReadWriteMultipleRegistersProcedure:
+			modbusServiceReadWriteMultipleRegistersHandler.ServeHTTP(w, r)
+		case ModbusServicePrepareWriteProcedure:
+			modbusServicePrepareWriteHandler.ServeHTTP(w, r)
+		case ModbusServiceExecuteWriteProcedure:
+			modbusServiceExecuteWriteHandler.ServeHTTP(w, r)
+		case ModbusServiceReadDeviceIdentificationProcedure:
+			modbusServiceReadDeviceIdentificationHandler.ServeHTTP(w, r)
+		case ModbusServiceReturnQueryDataProcedure:
+			modbusServiceReturnQueryDataHandler.ServeHTTP(w, r)
+		case ModbusServiceClearDiagnosticCountersProcedure:
+			modbusServiceClearDiagnosticCountersHandler.ServeHTTP(w, r)
+		case ModbusServiceGetDiagnosticCountersProcedure:
+			modbusServiceGetDiagnosticCountersHandler.ServeHTTP(w, r)
+		case ModbusServiceGetCommEventCounterProcedure:
+			modbusServiceGetCommEventCounterHandler.ServeHTTP(w, r)
+		case ModbusServiceGetCommEventLogProcedure:
+			modbusServiceGetCommEventLogHandler.ServeHTTP(w, r)
+		case ModbusServiceReadFIFOQueueProcedure:
+			modbusServiceReadFIFOQueueHandler.ServeHTTP(w, r)
+		case ModbusServiceReadFileRecordProcedure:
+			modbusServiceReadFileRecordHandler.ServeHTTP(w, r)
+		case ModbusServiceWriteFileRecordProcedure:
+			modbusServiceWriteFileRecordHandler.ServeHTTP(w, r)
+		case ModbusServiceSendRawPDUProcedure:
+			modbusServiceSendRawPDUHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -402,6 +841,66 @@ func (UnimplementedModbusServiceHandler) WriteBitInRegister(context.Context, *co
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.WriteBitInRegister is not implemented"))
 }
 
+func (UnimplementedModbusServiceHandler) MaskWriteRegister(context.Context, *connect.Request[v1alpha1.MaskWriteRegisterRequest]) (*connect.Response[v1alpha1.MaskWriteRegisterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.MaskWriteRegister is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) WriteBitsInRegister(context.Context, *connect.Request[v1alpha1.WriteBitsInRegisterRequest]) (*connect.Response[v1alpha1.WriteBitsInRegisterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.WriteBitsInRegister is not implemented"))
+}
+
 func (UnimplementedModbusServiceHandler) ReadRegisterAsBits(context.Context, *connect.Request[v1alpha1.ReadRegisterAsBitsRequest]) (*connect.Response[v1alpha1.ReadRegisterAsBitsResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReadRegisterAsBits is not implemented"))
 }
+
+func (UnimplementedModbusServiceHandler) ReadWriteMultipleRegisters(context.Context, *connect.Request[v1alpha1.ReadWriteMultipleRegistersRequest]) (*connect.Response[v1alpha1.ReadWriteMultipleRegistersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReadWriteMultipleRegisters is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) PrepareWrite(context.Context, *connect.Request[v1alpha1.PrepareWriteRequest]) (*connect.Response[v1alpha1.PrepareWriteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.PrepareWrite is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ExecuteWrite(context.Context, *connect.Request[v1alpha1.ExecuteWriteRequest]) (*connect.Response[v1alpha1.ExecuteWriteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ExecuteWrite is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ReadDeviceIdentification(context.Context, *connect.Request[v1alpha1.ReadDeviceIdentificationRequest]) (*connect.Response[v1alpha1.ReadDeviceIdentificationResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReadDeviceIdentification is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ReturnQueryData(context.Context, *connect.Request[v1alpha1.ReturnQueryDataRequest]) (*connect.Response[v1alpha1.ReturnQueryDataResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReturnQueryData is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ClearDiagnosticCounters(context.Context, *connect.Request[v1alpha1.ClearDiagnosticCountersRequest]) (*connect.Response[v1alpha1.ClearDiagnosticCountersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ClearDiagnosticCounters is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) GetDiagnosticCounters(context.Context, *connect.Request[v1alpha1.GetDiagnosticCountersRequest]) (*connect.Response[v1alpha1.GetDiagnosticCountersResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.GetDiagnosticCounters is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) GetCommEventCounter(context.Context, *connect.Request[v1alpha1.GetCommEventCounterRequest]) (*connect.Response[v1alpha1.GetCommEventCounterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.GetCommEventCounter is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) GetCommEventLog(context.Context, *connect.Request[v1alpha1.GetCommEventLogRequest]) (*connect.Response[v1alpha1.GetCommEventLogResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.GetCommEventLog is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ReadFIFOQueue(context.Context, *connect.Request[v1alpha1.ReadFIFOQueueRequest]) (*connect.Response[v1alpha1.ReadFIFOQueueResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReadFIFOQueue is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) ReadFileRecord(context.Context, *connect.Request[v1alpha1.ReadFileRecordRequest]) (*connect.Response[v1alpha1.ReadFileRecordResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.ReadFileRecord is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) WriteFileRecord(context.Context, *connect.Request[v1alpha1.WriteFileRecordRequest]) (*connect.Response[v1alpha1.WriteFileRecordResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.WriteFileRecord is not implemented"))
+}
+
+func (UnimplementedModbusServiceHandler) SendRawPDU(context.Context, *connect.Request[v1alpha1.SendRawPDURequest]) (*connect.Response[v1alpha1.SendRawPDUResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("modbustohttp.v1alpha1.ModbusService.SendRawPDU is not implemented"))
+}
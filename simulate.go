@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runSimulator runs the simulate subcommand, which serves a simulated modbus device over Modbus TCP until the process is
+// interrupted.
+func runSimulator(args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	addr := flags.String("addr", ":5020", "address to listen on for Modbus TCP connections")
+	latency := flags.Duration("latency", 0, "delay added to every response")
+	profilePath := flags.String("profile", "", "location of a JSON or YAML simulator profile")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	simulator := modbusserver.NewSimulator()
+	simulator.SetLatency(*latency)
+	if *profilePath != "" {
+		profile, err := config.LoadSimulatorProfile(*profilePath)
+		if err != nil {
+			return err
+		}
+		if err := simulator.ApplyProfile(ctx, profile); err != nil {
+			return err
+		}
+	}
+	server := modbusserver.NewServer(simulator)
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	logger.Info("starting modbus simulator",
+		slog.String("addr", *addr),
+		slog.Duration("latency", *latency),
+		slog.String("profile", *profilePath),
+	)
+	err := server.ListenAndServe(*addr)
+	if errors.Is(err, modbusserver.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"modbustohttp/internal/httpslave"
+	"modbustohttp/internal/modbusserver"
+	"modbustohttp/pkg/config"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runSlave runs the slave subcommand, which serves the values of the HTTP endpoints in the slave config to Modbus TCP
+// masters until the process is interrupted.
+func runSlave(args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("slave", flag.ContinueOnError)
+	configLocation := flags.String("config", os.Getenv("CONFIG_FILE"), "location of config file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	appConfig, err := config.LoadAppConfig(configLocation)
+	if err != nil {
+		return err
+	}
+	slaveConfig := &appConfig.Slave
+	if slaveConfig.Port == 0 {
+		slaveConfig.Port = 502
+	}
+	logger.Info("setting up slave mode",
+		slog.String("config_file", *configLocation),
+		slog.Duration("refresh_interval", slaveConfig.RefreshInterval),
+		slog.Int("num_mappings", len(slaveConfig.Mappings)),
+	)
+	handler, err := httpslave.New(slaveConfig, &http.Client{}, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go handler.Run(ctx)
+	server := modbusserver.NewServer(handler)
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	addr := fmt.Sprintf("%s:%d", slaveConfig.Host, slaveConfig.Port)
+	logger.Info("starting modbus slave", slog.String("addr", addr))
+	err = server.ListenAndServe(addr)
+	if errors.Is(err, modbusserver.ErrServerClosed) {
+		return nil
+	}
+	return err
+}